@@ -2,6 +2,9 @@
 // lhm-companion endpoint and prints the sensors/readings the dial catalog would
 // receive. Verifies the plugin code path that source_linux.go uses for a
 // non-localhost source profile. Usage: companion-probe http://host:8085/data.json
+// Credentials and TLS options are read from the same LHM_* variables the
+// bridge uses (LHM_USERNAME/LHM_PASSWORD or LHM_TOKEN, LHM_CA_FILE,
+// LHM_TLS_SKIP_VERIFY).
 package main
 
 import (
@@ -12,11 +15,17 @@ import (
 )
 
 func main() {
-	url := "http://127.0.0.1:8085/data.json"
+	ep := lhmplugin.EndpointFromEnv()
+	ep.URL = "http://127.0.0.1:8085/data.json"
 	if len(os.Args) > 1 {
-		url = os.Args[1]
+		ep.URL = os.Args[1]
+	}
+	url := ep.URL
+	hw, err := lhmplugin.NewHTTPService(ep)
+	if err != nil {
+		fmt.Printf("NewHTTPService error: %v\n", err)
+		os.Exit(1)
 	}
-	hw := lhmplugin.NewHTTPService(url)
 
 	if _, err := hw.PollTime(); err != nil {
 		fmt.Printf("PollTime error: %v\n", err)
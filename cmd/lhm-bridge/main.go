@@ -1,13 +1,18 @@
 package main
 
 import (
+	"log"
+
 	"github.com/hashicorp/go-plugin"
 	lhmplugin "github.com/moeilijk/lhm-streamdeck/internal/lhm/plugin"
 	hwsensorsservice "github.com/moeilijk/lhm-streamdeck/pkg/service"
 )
 
 func main() {
-	service := lhmplugin.StartService()
+	service, err := lhmplugin.StartService()
+	if err != nil {
+		log.Fatalf("lhm-bridge: %v", err)
+	}
 
 	plugin.Serve(&plugin.ServeConfig{
 		HandshakeConfig: hwsensorsservice.Handshake,
@@ -18,4 +23,8 @@ func main() {
 		// A non-nil value here enables gRPC serving for this plugin...
 		GRPCServer: plugin.DefaultGRPCServer,
 	})
+
+	if err := service.Close(); err != nil {
+		log.Printf("lhm-bridge: %v", err)
+	}
 }
@@ -192,7 +192,9 @@ function populateReadingSelect(slotIdx, readings) {
 function applySettingsToUI(s) {
   var slotCount = s.slotCount || 2;
   setSelectValue("composite_mode", s.mode || "both");
+  setSelectValue("composite_layout", s.layout || "");
   setSelectValue("composite_slotCount", String(slotCount));
+  setSelectValue("composite_graphWindow", s.graphWindow || "");
   setSelectValue("updateIntervalOverrideMs", String(s.updateIntervalOverrideMs || 0));
   var saInp = byId("smoothingAlpha") && byId("smoothingAlpha").querySelector("input[type=range]");
   if (saInp) { saInp.value = s.smoothingAlpha > 0 ? s.smoothingAlpha : 1; positionRangeVal(saInp); }
@@ -200,11 +202,13 @@ function applySettingsToUI(s) {
 
   var slots = s.slots || [];
   for (var i = 0; i < 4; i++) {
-    var slot = slots[i] || {};
+    var slot = migrateSymmetricAutoScale(slots[i] || {});
     setInputValue("slot" + i + "_title", slot.title || "");
     setSelectValue("slot" + i + "_mode", slot.mode || "");
     setColorValue("slot" + i + "_highlightColor", slot.highlightColor);
     setColorValue("slot" + i + "_foregroundColor", slot.foregroundColor);
+    setInputValue("slot" + i + "_graphGradient", slot.graphGradient || "");
+    setSelectValue("slot" + i + "_graphStyle", slot.graphStyle || "");
     setColorValue("slot" + i + "_valueTextColor", slot.valueTextColor);
     setColorValue("slot" + i + "_titleColor", slot.titleColor);
     setColorValue("slot" + i + "_backgroundColor", slot.backgroundColor);
@@ -212,6 +216,9 @@ function applySettingsToUI(s) {
     updateRangeDisplay("slot" + i + "_fillAlpha");
     setInputValue("slot" + i + "_min", slot.min != null ? slot.min : "");
     setInputValue("slot" + i + "_max", slot.max != null ? slot.max : "");
+    setSelectValue("slot" + i + "_autoScale", slot.autoScale || "");
+    setSelectValue("slot" + i + "_graphScale", slot.graphScale || "");
+    setInputValue("slot" + i + "_logFloor", slot.logFloor || "");
     setInputValue("slot" + i + "_titleFontSize", slot.titleFontSize || 9);
     updateRangeDisplay("slot" + i + "_titleFontSize");
     setInputValue("slot" + i + "_valueFontSize", slot.valueFontSize || 10.5);
@@ -222,10 +229,13 @@ function applySettingsToUI(s) {
     updateRangeDisplay("slot" + i + "_graphLineThickness");
     var tsEl = byId("slot" + i + "_textStroke");
     if (tsEl) tsEl.checked = slot.textStroke === true;
+    var stlEl = byId("slot" + i + "_showThresholdLines");
+    if (stlEl) stlEl.checked = slot.showThresholdLines === true;
     setColorValue("slot" + i + "_textStrokeColor", slot.textStrokeColor || "#000000");
     setInputValue("slot" + i + "_format", slot.format || "");
     setInputValue("slot" + i + "_divisor", slot.divisor || "");
     setSelectValue("slot" + i + "_graphUnit", slot.graphUnit || "");
+    setSelectValue("slot" + i + "_displayUnit", slot.displayUnit || "");
 
     if (allSensors.length > 0) {
       setSelectValue("slot" + i + "_sensorSelect", slot.sensorUid || "");
@@ -249,6 +259,8 @@ function updateSlotVisibility(count) {
 
 document.addEventListener("DOMContentLoaded", function () {
   bindSdpiValue("composite_mode", sendSdpi, onchangeevt);
+  bindSdpiValue("composite_layout", sendSdpi, onchangeevt);
+  bindSdpiValue("composite_graphWindow", sendSdpi, onchangeevt);
   bindSdpiValue("composite_slotCount", sendSdpi, onchangeevt, function (val) {
     updateSlotVisibility(parseInt(val, 10));
   });
@@ -268,6 +280,8 @@ document.addEventListener("DOMContentLoaded", function () {
     bindSdpiValue("slot" + i + "_mode", sendSdpi, onchangeevt);
     bindSdpiValue("slot" + i + "_highlightColor", sendSdpi, onchangeevt);
     bindSdpiValue("slot" + i + "_foregroundColor", sendSdpi, onchangeevt);
+    bindSdpiValue("slot" + i + "_graphGradient", sendSdpi, "onchange");
+    bindSdpiValue("slot" + i + "_graphStyle", sendSdpi, onchangeevt);
     bindSdpiValue("slot" + i + "_valueTextColor", sendSdpi, onchangeevt);
     bindSdpiValue("slot" + i + "_titleColor", sendSdpi, onchangeevt);
     bindSdpiValue("slot" + i + "_backgroundColor", sendSdpi, onchangeevt);
@@ -275,6 +289,9 @@ document.addEventListener("DOMContentLoaded", function () {
     wireRangeOninput("slot" + i + "_fillAlpha");
     bindSdpiValue("slot" + i + "_min", sendSdpi, "onchange");
     bindSdpiValue("slot" + i + "_max", sendSdpi, "onchange");
+    bindSdpiValue("slot" + i + "_autoScale", sendSdpi, onchangeevt);
+    bindSdpiValue("slot" + i + "_graphScale", sendSdpi, onchangeevt);
+    bindSdpiValue("slot" + i + "_logFloor", sendSdpi, "onchange");
     bindSdpiValue("slot" + i + "_titleFontSize", sendSdpi, onchangeevt);
     wireRangeOninput("slot" + i + "_titleFontSize");
     bindSdpiValue("slot" + i + "_valueFontSize", sendSdpi, onchangeevt);
@@ -286,11 +303,14 @@ document.addEventListener("DOMContentLoaded", function () {
     (function(idx) {
       var cb = byId("slot" + idx + "_textStroke");
       if (cb) cb.onchange = function() { sendSdpiChecked("slot" + idx + "_textStroke", this.checked); };
+      var lines = byId("slot" + idx + "_showThresholdLines");
+      if (lines) lines.onchange = function() { sendSdpiChecked("slot" + idx + "_showThresholdLines", this.checked); };
     })(i);
     bindSdpiValue("slot" + i + "_textStrokeColor", sendSdpi, onchangeevt);
     bindSdpiValue("slot" + i + "_format", sendSdpi, "onchange");
     bindSdpiValue("slot" + i + "_divisor", sendSdpi, "onchange");
     bindSdpiValue("slot" + i + "_graphUnit", sendSdpi, onchangeevt);
+    bindSdpiValue("slot" + i + "_displayUnit", sendSdpi, onchangeevt);
     wireSlotAddThreshold(i);
   }
 });
@@ -536,7 +556,7 @@ function renderSlotActiveGlobals(slotIdx) {
   container.innerHTML = "";
   var slotRt = slotReadingTypes[slotIdx] || "";
   var active = (globalThresholds || []).filter(function(gt) {
-    return !gt.readingType || gt.readingType === slotRt;
+    return readingTypeMatches(gt.readingType, slotRt);
   });
   if (active.length === 0) return;
   var slot = currentSettings.slots && currentSettings.slots[slotIdx];
@@ -324,6 +324,7 @@ function applySettingsToUI(s) {
 
   setColorValue("derived_highlightColor", s.highlightColor);
   setColorValue("derived_foregroundColor", s.foregroundColor);
+  setInputValue("derived_graphGradient", s.graphGradient || "");
   setColorValue("derived_backgroundColor", s.backgroundColor);
   setColorValue("derived_valueTextColor", s.valueTextColor);
   setColorValue("derived_titleColor", s.titleColor);
@@ -333,15 +334,24 @@ function applySettingsToUI(s) {
   updateRangeVal("derived_graphLineThickness");
   var tsDerived = byId("derived_textStroke");
   if (tsDerived) tsDerived.checked = s.textStroke === true;
+  var stlDerived = byId("derived_showThresholdLines");
+  if (stlDerived) stlDerived.checked = s.showThresholdLines === true;
   setColorValue("derived_textStrokeColor", s.textStrokeColor || "#000000");
   setSelectValue("derived_updateIntervalOverrideMs", String(s.updateIntervalOverrideMs || 0));
   var saInp = byId("derived_smoothingAlpha") && byId("derived_smoothingAlpha").querySelector("input[type=range]");
   if (saInp) { saInp.value = s.smoothingAlpha > 0 ? s.smoothingAlpha : 1; positionRangeVal(saInp); }
   setInputValue("derived_min", s.min != null ? s.min : "");
   setInputValue("derived_max", s.max != null ? s.max : "");
+  migrateSymmetricAutoScale(s);
+  setSelectValue("derived_autoScale", s.autoScale || "");
+  setSelectValue("derived_graphScale", s.graphScale || "");
+  setInputValue("derived_logFloor", s.logFloor || "");
+  setSelectValue("derived_graphWindow", s.graphWindow || "");
+  setSelectValue("derived_graphStyle", s.graphStyle || "");
   setInputValue("derived_format", s.format || "");
   setInputValue("derived_divisor", s.divisor || "");
   setSelectValue("derived_graphUnit", s.graphUnit || "");
+  setSelectValue("derived_displayUnit", s.displayUnit || "");
 
   var slots = s.slots || [];
   for (var i = 0; i < 8; i++) {
@@ -426,20 +436,29 @@ document.addEventListener("DOMContentLoaded", function () {
   });
   bindSdpiValue("derived_highlightColor", sendSdpi, onchangeevt);
   bindSdpiValue("derived_foregroundColor", sendSdpi, onchangeevt);
+  bindSdpiValue("derived_graphGradient", sendSdpi, "onchange");
   bindSdpiValue("derived_backgroundColor", sendSdpi, onchangeevt);
   bindSdpiValue("derived_valueTextColor", sendSdpi, onchangeevt);
   bindSdpiValue("derived_titleColor", sendSdpi, onchangeevt);
   bindSdpiValue("derived_min", sendSdpi, "onchange");
   bindSdpiValue("derived_max", sendSdpi, "onchange");
+  bindSdpiValue("derived_autoScale", sendSdpi, onchangeevt);
+  bindSdpiValue("derived_graphScale", sendSdpi, onchangeevt);
+  bindSdpiValue("derived_logFloor", sendSdpi, "onchange");
+  bindSdpiValue("derived_graphWindow", sendSdpi, onchangeevt);
+  bindSdpiValue("derived_graphStyle", sendSdpi, onchangeevt);
   bindSdpiValue("derived_format", sendSdpi, "onchange");
   bindSdpiValue("derived_divisor", sendSdpi, "onchange");
   bindSdpiValue("derived_graphUnit", sendSdpi, onchangeevt);
+  bindSdpiValue("derived_displayUnit", sendSdpi, onchangeevt);
   bindSdpiValue("derived_graphHeightPct", sendSdpi, onchangeevt);
   wireRangeVal("derived_graphHeightPct");
   bindSdpiValue("derived_graphLineThickness", sendSdpi, onchangeevt);
   wireRangeVal("derived_graphLineThickness");
   var tsDerivedEl = byId("derived_textStroke");
   if (tsDerivedEl) tsDerivedEl.onchange = function() { sendSdpiChecked("derived_textStroke", this.checked); };
+  var stlDerivedEl = byId("derived_showThresholdLines");
+  if (stlDerivedEl) stlDerivedEl.onchange = function() { sendSdpiChecked("derived_showThresholdLines", this.checked); };
   bindSdpiValue("derived_textStrokeColor", sendSdpi, onchangeevt);
   bindSdpiValue("derived_updateIntervalOverrideMs", sendSdpi, onchangeevt);
   (function() {
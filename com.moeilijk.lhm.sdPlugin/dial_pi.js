@@ -68,6 +68,7 @@ function connectElgatoStreamDeckSocket(inPort, inUUID, inRegisterEvent, inInfo,
       currentCatalog = payload.catalog;
       populateProfiles();
       renderSelectedPageSelection();
+      renderMirrorReadings();
       renderActiveGlobals();
     }
     if (Array.isArray(payload.globalThresholds)) {
@@ -111,6 +112,13 @@ function normalizePage(page) {
   if (!page.format) page.format = "";
   if (!page.divisor) page.divisor = "";
   if (!page.graphUnit) page.graphUnit = "";
+  if (!page.displayUnit) page.displayUnit = "";
+  if (!page.autoScale) page.autoScale = "";
+  if (!page.graphScale) page.graphScale = "";
+  migrateSymmetricAutoScale(page);
+  if (!page.logFloor) page.logFloor = 0;
+  if (!page.graphWindow) page.graphWindow = "";
+  if (!page.graphStyle) page.graphStyle = "";
   if (!page.titleColor) page.titleColor = "#b7b7b7";
   if (!page.foregroundColor) page.foregroundColor = "#005128";
   if (!page.backgroundColor) page.backgroundColor = "#000000";
@@ -345,6 +353,12 @@ function renderPageSettings() {
   setValue("formatValue", page.format || "");
   setValue("divisorValue", page.divisor || "");
   setValue("graphUnit", page.graphUnit || "");
+  setValue("displayUnit", page.displayUnit || "");
+  setValue("autoScale", page.autoScale || "");
+  setValue("graphScale", page.graphScale || "");
+  setValue("logFloor", page.logFloor || "");
+  setValue("graphWindow", page.graphWindow || "");
+  setValue("graphStyle", page.graphStyle || "");
   setValue("titleFontSize", page.titleFontSize || 14);
   setValue("valueFontSize", page.valueFontSize || 18);
   setValue("smoothingAlpha", page.smoothingAlpha > 0 ? page.smoothingAlpha : 1);
@@ -355,12 +369,69 @@ function renderPageSettings() {
   setValue("backgroundColor", page.backgroundColor || "#000000");
   setValue("foregroundColor", page.foregroundColor || "#005128");
   setValue("highlightColor", page.highlightColor || "#009e00");
+  setValue("mirrorForegroundColor", page.mirrorForegroundColor || "#003f73");
+  setValue("mirrorHighlightColor", page.mirrorHighlightColor || "#00a2ff");
   setValue("textStroke", page.textStroke);
   setValue("textStrokeColor", page.textStrokeColor || page.backgroundColor || "#000000");
   applySnoozeDurationsToUI(page);
   renderThresholds(page.thresholds || []);
   renderActiveGlobals();
   renderSelectedPageSelection();
+  renderMirrorReadings();
+}
+
+// renderMirrorReadings lists the other readings of the page's sensor as
+// candidates for the mirrored lower half (e.g. upload under download). A
+// mirror reading missing from the catalog stays listed so it isn't dropped.
+function renderMirrorReadings() {
+  var sel = document.getElementById("mirrorReading");
+  var page = selectedPage();
+  if (!sel || !page) return;
+  var selected = page.mirrorReadingId ? String(page.mirrorReadingId) : "";
+  sel.innerHTML = "";
+  var off = document.createElement("option");
+  off.value = "";
+  off.textContent = "Off";
+  sel.appendChild(off);
+  var found = false;
+  readingsForSensor(page.sensorUid).forEach(function (reading) {
+    if (String(reading.id) === String(page.readingId)) return;
+    var opt = document.createElement("option");
+    opt.value = String(reading.id);
+    opt.textContent = readingOptionLabel(reading);
+    if (opt.value === selected) found = true;
+    sel.appendChild(opt);
+  });
+  if (selected && !found) {
+    var missing = document.createElement("option");
+    missing.value = selected;
+    missing.textContent = page.mirrorReadingLabel || selected;
+    sel.appendChild(missing);
+  }
+  sel.value = selected;
+}
+
+// bindMirrorReading stores the mirror reading id and label together, and
+// removes both for Off: the plugin decodes the id as a number.
+function bindMirrorReading() {
+  var sel = document.getElementById("mirrorReading");
+  if (!sel || sel.dataset.bound) return;
+  sel.dataset.bound = "1";
+  sel.addEventListener("change", function () {
+    var page = selectedPage();
+    if (!page) return;
+    var reading = readingsForSensor(page.sensorUid).filter(function (r) {
+      return String(r.id) === sel.value;
+    })[0];
+    if (reading) {
+      page.mirrorReadingId = String(reading.id);
+      page.mirrorReadingLabel = reading.label;
+    } else if (!sel.value) {
+      delete page.mirrorReadingId;
+      delete page.mirrorReadingLabel;
+    }
+    saveSettings();
+  });
 }
 
 function bindPageField(id, key, parser) {
@@ -418,6 +489,12 @@ function bindPageSettings() {
   bindPageField("formatValue", "format");
   bindPageField("divisorValue", "divisor");
   bindPageField("graphUnit", "graphUnit");
+  bindPageField("displayUnit", "displayUnit");
+  bindPageField("autoScale", "autoScale");
+  bindPageField("graphScale", "graphScale");
+  bindPageField("logFloor", "logFloor", function (v) { return Math.max(Number(v) || 0, 0); });
+  bindPageField("graphWindow", "graphWindow");
+  bindPageField("graphStyle", "graphStyle");
   bindPageField("titleFontSize", "titleFontSize", function (v) { return Number(v) || 0; });
   bindPageField("valueFontSize", "valueFontSize", function (v) { return Number(v) || 0; });
   bindPageField("graphHeightPct", "graphHeightPct", function (v) { return Number(v) || 100; });
@@ -428,6 +505,9 @@ function bindPageSettings() {
   bindPageField("backgroundColor", "backgroundColor");
   bindPageField("foregroundColor", "foregroundColor");
   bindPageField("highlightColor", "highlightColor");
+  bindPageField("mirrorForegroundColor", "mirrorForegroundColor");
+  bindPageField("mirrorHighlightColor", "mirrorHighlightColor");
+  bindMirrorReading();
   bindPageField("textStroke", "textStroke", function (v) { return !!v; });
   bindPageField("textStrokeColor", "textStrokeColor");
 }
@@ -786,9 +866,9 @@ function readingForPage(page) {
 
 function activeGlobalThresholdsForPage(page) {
   var reading = readingForPage(page);
-  var readingType = reading ? reading.type || "" : "";
+  var readingType = normalizeReadingType(reading ? reading.type : "");
   return (globalThresholds || []).filter(function (gt) {
-    return !gt.readingType || gt.readingType === readingType;
+    return readingTypeMatches(gt.readingType, readingType);
   });
 }
 
@@ -897,6 +977,12 @@ function pageFromReading(sensorUid, reading, index, title) {
     format: "",
     divisor: "",
     graphUnit: "",
+    displayUnit: "",
+    autoScale: "",
+    graphScale: "",
+    logFloor: 0,
+    graphWindow: "",
+    graphStyle: "",
     isValid: true,
     titleColor: "#b7b7b7",
     foregroundColor: pageColors.foregroundColor,
@@ -85,6 +85,15 @@ function connectElgatoStreamDeckSocket(inPort, inUUID, inRegisterEvent, inInfo,
       if (jsonObj.payload.error === true) {
         document.querySelector("#ui").style = "display:none";
         document.querySelector("#error").style = "display:block";
+        var summary = document.querySelector("#error summary");
+        if (summary) {
+          if (!summary.dataset.defaultText) {
+            summary.dataset.defaultText = summary.textContent;
+          }
+          summary.textContent = jsonObj.payload.message === "Libre Hardware Monitor Authentication Failed"
+            ? "Libre Hardware Monitor Rejected The Credentials"
+            : summary.dataset.defaultText;
+        }
       } else if (jsonObj.payload.message === "show_ui") {
         document.querySelector("#ui").style = "display:block";
         document.querySelector("#error").style = "display:none";
@@ -112,6 +121,7 @@ function connectElgatoStreamDeckSocket(inPort, inUUID, inRegisterEvent, inInfo,
         rebuildSourceProfileDropdown(selId);
         initSourceProfileDropdown();
       }
+      renderValueSourceOptions(currentCatalog.statWindows, currentSensorSettings.valueSource);
       renderFavoriteControls();
     }
     if (
@@ -184,20 +194,34 @@ function connectElgatoStreamDeckSocket(inPort, inUUID, inRegisterEvent, inInfo,
         if (vfsInp) { vfsInp.value = settings.valueFontSize || 10.5; positionRangeVal(vfsInp); }
       }
       setSelectValue("graphMode", settings.graphMode || "both");
+      migrateSymmetricAutoScale(settings);
+      setSelectValue("autoScale", settings.autoScale || "");
+      setSelectValue("graphScale", settings.graphScale || "");
+      var lfEl = document.querySelector("#logFloor");
+      if (lfEl) { lfEl.value = settings.logFloor || ""; }
+      setSelectValue("graphWindow", settings.graphWindow || "");
+      setSelectValue("graphStyle", settings.graphStyle || "");
       var ghpInp = document.querySelector("#graphHeightPct input[type=range]");
       if (ghpInp) { ghpInp.value = settings.graphHeightPct || 100; positionRangeVal(ghpInp); }
       var gltInp = document.querySelector("#graphLineThickness input[type=range]");
       if (gltInp) { gltInp.value = settings.graphLineThickness || 1; positionRangeVal(gltInp); }
       var tsEl = document.querySelector("#textStroke");
       if (tsEl) { tsEl.checked = settings.textStroke === true; }
+      var ggEl = document.querySelector("#graphGradient");
+      if (ggEl) { ggEl.value = settings.graphGradient || ""; }
+      var stlEl = document.querySelector("#showThresholdLines");
+      if (stlEl) { stlEl.checked = settings.showThresholdLines === true; }
       var tscEl = document.querySelector("#textStrokeColor");
       if (tscEl && settings.textStrokeColor) { tscEl.value = settings.textStrokeColor; }
+      renderValueSourceOptions(currentCatalog ? currentCatalog.statWindows : [], settings.valueSource);
       setSelectValue("updateIntervalOverrideMs", String(settings.updateIntervalOverrideMs || 0));
       var saInp = document.querySelector("#smoothingAlpha input[type=range]");
       if (saInp) { saInp.value = settings.smoothingAlpha > 0 ? settings.smoothingAlpha : 1; positionRangeVal(saInp); }
       if (settings.graphUnit !== undefined) {
         document.querySelector("#graphUnit").value = settings.graphUnit;
       }
+      var displayUnitEl = document.querySelector("#displayUnit");
+      if (displayUnitEl) displayUnitEl.value = settings.displayUnit || "";
       applySnoozeDurationsToUI(settings);
       // Render dynamic thresholds
       if (settings.thresholds) {
@@ -369,6 +393,40 @@ function updateGraphUnitVisibility(unit) {
   }
 }
 
+// Build the Value options from the stat windows the tile's source tracks
+// (catalog.statWindows, e.g. ["1m","5m","15m"]). Sources without windows
+// (hwmon) hide the selector unless a saved choice needs clearing; a saved
+// window the source does not track stays listed as such.
+function renderValueSourceOptions(windows, selected) {
+  var sel = byId("valueSource");
+  if (!sel) return;
+  windows = Array.isArray(windows) ? windows : [];
+  selected = selected || "";
+  while (sel.options.length > 0) sel.remove(0);
+  function add(value, text) {
+    var opt = document.createElement("option");
+    opt.value = value;
+    opt.textContent = text;
+    sel.add(opt);
+  }
+  add("", "Current");
+  var stats = { avg: "average", min: "minimum", max: "maximum" };
+  var found = selected === "";
+  windows.forEach(function(w) {
+    Object.keys(stats).forEach(function(st) {
+      add(st + w, w + " " + stats[st]);
+      if (st + w === selected) found = true;
+    });
+  });
+  if (!found) {
+    var name = stats[selected.slice(0, 3)] || selected.slice(0, 3);
+    add(selected, selected.slice(3) + " " + name + " (not tracked)");
+  }
+  setSelectValue("valueSource", selected);
+  var item = byId("valueSourceItem");
+  if (item) item.style.display = windows.length > 0 || selected !== "" ? "" : "none";
+}
+
 function initPropertyInspector(initDelay) {
   setupCatalogControls();
   bindSnoozeControls();
@@ -390,6 +448,12 @@ function wireRangeDisplays() {
       sendValueToPlugin({ key: "textStroke", value: "", checked: this.checked }, "sdpi_collection");
     };
   }
+  var thresholdLinesEl = document.querySelector("#showThresholdLines");
+  if (thresholdLinesEl) {
+    thresholdLinesEl.onchange = function() {
+      sendValueToPlugin({ key: "showThresholdLines", value: "", checked: this.checked }, "sdpi_collection");
+    };
+  }
 }
 
 function setupCatalogControls() {
@@ -1349,7 +1413,7 @@ function renderActiveGlobals() {
   if (!container) return;
   container.innerHTML = "";
   var active = (globalThresholds || []).filter(function(gt) {
-    return !gt.readingType || gt.readingType === currentReadingType;
+    return readingTypeMatches(gt.readingType, currentReadingType);
   });
   if (active.length === 0) {
     if (globalThresholds && globalThresholds.length > 0 && currentReadingType) {
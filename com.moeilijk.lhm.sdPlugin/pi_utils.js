@@ -7,12 +7,39 @@ function normalizeReadingType(t) {
     case "current":                  return "Current";
     case "power":                    return "Power";
     case "clock":                    return "Clock";
-    case "usage": case "load": case "control": case "level": return "Usage";
+    case "usage": case "load":       return "Usage";
+    case "control":                  return "Control";
+    case "level":                    return "Level";
+    case "throughput":               return "Throughput";
+    case "data":                     return "Data";
+    case "smalldata":                return "SmallData";
+    case "energy":                   return "Energy";
+    case "flow":                     return "Flow";
+    case "noise":                    return "Noise";
+    case "factor":                   return "Factor";
+    case "frequency":                return "Frequency";
+    case "timespan":                 return "TimeSpan";
+    case "timing":                   return "Timing";
+    case "conductivity":             return "Conductivity";
+    case "humidity":                 return "Humidity";
     case "none":                     return "None";
     default:                         return t ? "Other" : "";
   }
 }
 
+/** Does a global threshold's type filter apply to a normalized reading type?
+ *  Filters saved before Control/Level and the Other types were split out keep
+ *  matching them as "Usage" and "Other" (same rule as ReadingType.Matches). */
+function readingTypeMatches(filter, type) {
+  if (!filter || filter === type) return true;
+  switch (type) {
+    case "Control": case "Level": return filter === "Usage";
+    case "Temp": case "Volt": case "Fan": case "Current": case "Power":
+    case "Clock": case "Usage": case "None": case "": return false;
+    default: return filter === "Other";
+  }
+}
+
 function naturalCompare(left, right) {
   var a = String(left || "");
   var b = String(right || "");
@@ -77,6 +104,15 @@ function normalizeHexColor(hex) {
   return hex;
 }
 
+/** Show the old "symmetric" auto scale the way the plugin migrates it: a
+ *  symmetric scale fitted to the visible samples. */
+function migrateSymmetricAutoScale(s) {
+  if (!s || s.autoScale !== "symmetric") return s;
+  s.autoScale = "window";
+  if (!s.graphScale) s.graphScale = "symmetric";
+  return s;
+}
+
 function setInputValue(id, val) {
   var el = byId(id);
   if (el) {
@@ -31,6 +31,71 @@ function byId(id) {
   return document.getElementById(id);
 }
 
+// applySourceHealth shows the last fetch error while the selected source is
+// degraded or its circuit is open.
+function applySourceHealth(health) {
+  var row = byId("lastSourceErrorRow");
+  var el = byId("lastSourceError");
+  var unhealthy = health.state && health.state !== "healthy";
+  if (row) {
+    row.style.display = unhealthy && health.lastError ? "" : "none";
+  }
+  if (el) {
+    el.textContent = unhealthy ? health.lastError || "" : "";
+  }
+  var statusEl = byId("connectionStatus");
+  if (statusEl && health.state === "degraded") {
+    statusEl.style.color = "#ca4";
+  }
+}
+
+// applyRenderTiming shows how long a tick takes to render, and turns amber
+// once ticks have overrun the poll interval or tiles were skipped.
+function applyRenderTiming(timing) {
+  var el = byId("renderTiming");
+  if (!el) {
+    return;
+  }
+  var text = (timing.avgMs || 0) + " ms avg · peak " + (timing.peakMs || 0) + " ms";
+  var behind = [];
+  if (timing.lateTicks) {
+    behind.push(timing.lateTicks + " late");
+  }
+  if (timing.skippedRenders) {
+    behind.push(timing.skippedRenders + " skipped");
+  }
+  if (behind.length) {
+    text += " (" + behind.join(", ") + ")";
+  }
+  el.textContent = text;
+  el.style.color = behind.length ? "#ca4" : "#999";
+}
+
+function formatBytes(n) {
+  if (n >= 1048576) {
+    return (n / 1048576).toFixed(1) + " MB";
+  }
+  if (n >= 1024) {
+    return Math.round(n / 1024) + " KB";
+  }
+  return n + " B";
+}
+
+// applyFrameStats shows how many rendered frames went out and selects the
+// compression level of the transport the plugin is connected to.
+function applyFrameStats(frames) {
+  var el = byId("frameStats");
+  if (el) {
+    var rendered = frames.rendered || 0;
+    var suppressed = frames.suppressed || 0;
+    el.textContent = (rendered - suppressed) + " sent · " + suppressed + " unchanged · " + formatBytes(frames.bytesSent || 0);
+  }
+  var levelEl = byId("pngCompression");
+  if (levelEl && frames.compression && document.activeElement !== levelEl) {
+    levelEl.value = frames.compression;
+  }
+}
+
 function normalizeInterval(value) {
   var v = parseInt(value, 10);
   if (isNaN(v)) {
@@ -236,9 +301,21 @@ function connectElgatoStreamDeckSocket(inPort, inUUID, inRegisterEvent, inInfo,
       if (rateEl) {
         rateEl.textContent = interval + "ms";
       }
+      var tempUnitEl = byId("temperatureUnit");
+      if (tempUnitEl) {
+        tempUnitEl.value = settings.temperatureUnit || "";
+      }
+      var historyEl = byId("historyMaxAge");
+      if (historyEl) {
+        historyEl.value = String(settings.historyMaxAgeMin || 15);
+      }
+      var renderingEl = byId("rendering");
+      if (renderingEl) {
+        renderingEl.value = settings.rendering === "smooth" ? "smooth" : "";
+      }
       // Source profiles
       if (Array.isArray(settings.sourceProfiles)) {
-        sourceProfiles = settings.sourceProfiles;
+        sourceProfiles = maskCredentials(settings.sourceProfiles);
         defaultProfileId = settings.defaultSourceProfileId || "";
         if (!selectedProfileId) {
           selectedProfileId = defaultProfileId;
@@ -275,12 +352,21 @@ function connectElgatoStreamDeckSocket(inPort, inUUID, inRegisterEvent, inInfo,
           statusEl.style.color = payload.connectionStatus === "Connected" ? "#4a4" : "#a44";
         }
       }
+      if (payload.sourceHealth !== undefined) {
+        applySourceHealth(payload.sourceHealth || {});
+      }
       if (payload.currentRate !== undefined) {
         var currentRateEl = byId("currentRate");
         if (currentRateEl) {
           currentRateEl.textContent = payload.currentRate + "ms";
         }
       }
+      if (payload.renderTiming) {
+        applyRenderTiming(payload.renderTiming);
+      }
+      if (payload.frames) {
+        applyFrameStats(payload.frames);
+      }
       if (Array.isArray(payload.sourceProfiles)) {
         sourceProfiles = payload.sourceProfiles;
         defaultProfileId = payload.defaultSourceProfileId || "";
@@ -395,16 +481,141 @@ function applySelectedProfileToUI() {
   for (var i = 0; i < sourceProfiles.length; i++) {
     if (sourceProfiles[i].id === selectedProfileId) {
       var nameEl = byId("profileName");
+      var typeEl = byId("sourceType");
       var hostEl = byId("lhmHost");
       var portEl = byId("lhmPort");
       applyInputValue(nameEl, sourceProfiles[i].name || "");
+      applyInputValue(typeEl, sourceProfiles[i].type || "");
       applyInputValue(hostEl, sourceProfiles[i].host || "127.0.0.1");
       applyInputValue(portEl, sourceProfiles[i].port || 8085);
+      applyInputValue(byId("lhmScheme"), sourceProfiles[i].scheme || "");
+      applyInputValue(byId("lhmPath"), sourceProfiles[i].path || "");
+      applyInputValue(byId("lhmCAFile"), sourceProfiles[i].caFile || "");
+      var skipEl = byId("lhmSkipVerify");
+      if (skipEl && !isActivelyEditing(skipEl)) {
+        skipEl.checked = !!sourceProfiles[i].insecureSkipVerify;
+      }
+      applyInputValue(byId("lhmUsername"), sourceProfiles[i].username || "");
+      applyInputValue(byId("lhmPassword"), sourceProfiles[i].password || "");
+      applyInputValue(byId("lhmToken"), sourceProfiles[i].token || "");
+      applyInputValue(byId("recordDir"), sourceProfiles[i].recordDir || "");
+      applyInputValue(byId("statWindows"), sourceProfiles[i].statWindows || "");
+      applyInputValue(byId("replayFile"), sourceProfiles[i].replayFile || "");
+      applyInputValue(byId("replaySpeed"), sourceProfiles[i].replaySpeed || "");
+      renderGroupMembers(sourceProfiles[i]);
+      updateSourceTypeVisibility();
       return;
     }
   }
 }
 
+// Endpoint, credentials and recording only apply to HTTP sources; the hwmon
+// source reads sysfs, a failover group reads from its members and a replay
+// profile from its recording.
+function updateSourceTypeVisibility() {
+  var typeEl = byId("sourceType");
+  var type = typeEl ? typeEl.value : "";
+  var isHTTP = type === "";
+  var ids = ["lhmSchemeItem", "lhmHostItem", "lhmPortItem", "lhmPathItem",
+    "lhmUsernameItem", "lhmPasswordItem", "lhmTokenItem", "recordDirItem"];
+  for (var i = 0; i < ids.length; i++) {
+    var item = byId(ids[i]);
+    if (item) item.style.display = isHTTP ? "" : "none";
+  }
+  var membersItem = byId("groupMembersItem");
+  if (membersItem) membersItem.style.display = type === "group" ? "" : "none";
+  ["replayFileItem", "replaySpeedItem"].forEach(function(id) {
+    var replayItem = byId(id);
+    if (replayItem) replayItem.style.display = type === "replay" ? "" : "none";
+  });
+  // Rolling stats are kept by the LHM service behind HTTP and replay sources.
+  var statWindowsItem = byId("statWindowsItem");
+  if (statWindowsItem) statWindowsItem.style.display = isHTTP || type === "replay" ? "" : "none";
+  // TLS options only matter for https.
+  var schemeEl = byId("lhmScheme");
+  var isHttps = isHTTP && !!schemeEl && schemeEl.value === "https";
+  var tlsIds = ["lhmCAFileItem", "lhmSkipVerifyItem"];
+  for (var j = 0; j < tlsIds.length; j++) {
+    var tlsItem = byId(tlsIds[j]);
+    if (tlsItem) tlsItem.style.display = isHttps ? "" : "none";
+  }
+}
+
+// renderGroupMembers shows one select per member of a failover group, in
+// priority order, plus an empty one to append the next member.
+function renderGroupMembers(profile) {
+  var container = byId("groupMembers");
+  if (!container) return;
+  var members = (profile.members || []).slice();
+  members.push("");
+  container.innerHTML = "";
+  for (var i = 0; i < members.length; i++) {
+    var sel = document.createElement("select");
+    sel.className = "sdpi-item-value select";
+    var none = document.createElement("option");
+    none.value = "";
+    none.textContent = i === 0 ? "Primary…" : "Next…";
+    sel.appendChild(none);
+    for (var j = 0; j < sourceProfiles.length; j++) {
+      var candidate = sourceProfiles[j];
+      if (candidate.id === profile.id || candidate.type === "group") continue;
+      var o = document.createElement("option");
+      o.value = candidate.id;
+      o.textContent = candidate.name || candidate.id;
+      if (candidate.id === members[i]) o.selected = true;
+      sel.appendChild(o);
+    }
+    sel.value = members[i];
+    sel.addEventListener("change", saveSourceProfile);
+    container.appendChild(sel);
+  }
+}
+
+function collectGroupMembers() {
+  var container = byId("groupMembers");
+  var out = [];
+  if (!container) return out;
+  for (var i = 0; i < container.children.length; i++) {
+    var v = container.children[i].value;
+    if (v && out.indexOf(v) < 0) out.push(v);
+  }
+  return out;
+}
+
+// splitEndpointURL fills scheme/host/port/path from a URL pasted into the host
+// field, e.g. https://[fd00::2]:8443/lhm/data.json. Returns false for plain hosts.
+function splitEndpointURL(value) {
+  if (value.indexOf("://") < 0) return false;
+  var u;
+  try {
+    u = new URL(value);
+  } catch (e) {
+    return false;
+  }
+  var https = u.protocol === "https:";
+  byId("lhmScheme").value = https ? "https" : "";
+  byId("lhmHost").value = u.hostname.replace(/^\[|\]$/g, "");
+  byId("lhmPort").value = u.port || (https ? 443 : 80);
+  byId("lhmPath").value = u.pathname === "/" ? "" : u.pathname;
+  return true;
+}
+
+// The plugin masks stored secrets before echoing profiles; do the same for
+// profiles read straight from the global settings so they never reach the UI.
+// Saving the mask back leaves the stored secret unchanged.
+var CREDENTIAL_MASK = "********";
+
+function maskCredentials(profiles) {
+  var out = [];
+  for (var i = 0; i < profiles.length; i++) {
+    var p = Object.assign({}, profiles[i]);
+    if (p.password) p.password = CREDENTIAL_MASK;
+    if (p.token) p.token = CREDENTIAL_MASK;
+    out.push(p);
+  }
+  return out;
+}
+
 function addSourceProfile() {
   sendJson({
     action: action,
@@ -424,12 +635,29 @@ function deleteSourceProfile() {
   });
 }
 
+function fieldValue(id) {
+  var el = byId(id);
+  return el ? String(el.value || "").trim() : "";
+}
+
 function saveSourceProfile() {
   if (!selectedProfileId) return;
   var nameEl = byId("profileName");
+  var typeEl = byId("sourceType");
   var hostEl = byId("lhmHost");
   var portEl = byId("lhmPort");
+  if (hostEl && splitEndpointURL(hostEl.value.trim())) {
+    updateSourceTypeVisibility();
+  }
+  var schemeEl = byId("lhmScheme");
+  var pathEl = byId("lhmPath");
+  var caFileEl = byId("lhmCAFile");
+  var skipEl = byId("lhmSkipVerify");
+  var usernameEl = byId("lhmUsername");
+  var passwordEl = byId("lhmPassword");
+  var tokenEl = byId("lhmToken");
   var name = nameEl ? nameEl.value.trim() : "";
+  var type = typeEl ? typeEl.value : "";
   var host = hostEl ? hostEl.value.trim() : "127.0.0.1";
   var port = portEl ? parseInt(portEl.value, 10) : 8085;
   if (!name) name = "Source";
@@ -439,7 +667,27 @@ function saveSourceProfile() {
     action: action,
     event: "sendToPlugin",
     context: sdkContext(),
-    payload: { setSourceProfile: { id: selectedProfileId, name: name, host: host, port: port } }
+    payload: {
+      setSourceProfile: {
+        id: selectedProfileId,
+        name: name,
+        type: type,
+        host: host,
+        port: port,
+        scheme: schemeEl ? schemeEl.value : "",
+        path: pathEl ? pathEl.value.trim() : "",
+        caFile: caFileEl ? caFileEl.value.trim() : "",
+        insecureSkipVerify: skipEl ? skipEl.checked : false,
+        username: usernameEl ? usernameEl.value.trim() : "",
+        password: passwordEl ? passwordEl.value : "",
+        token: tokenEl ? tokenEl.value.trim() : "",
+        members: type === "group" ? collectGroupMembers() : [],
+        recordDir: fieldValue("recordDir"),
+        statWindows: fieldValue("statWindows"),
+        replayFile: fieldValue("replayFile"),
+        replaySpeed: parseFloat(fieldValue("replaySpeed")) || 0
+      }
+    }
   });
 }
 
@@ -530,6 +778,14 @@ function bindUIHandlers() {
     profileNameEl.addEventListener("change", saveSourceProfile);
   }
 
+  var typeEl = byId("sourceType");
+  if (typeEl) {
+    typeEl.addEventListener("change", function() {
+      updateSourceTypeVisibility();
+      saveSourceProfile();
+    });
+  }
+
   var hostEl = byId("lhmHost");
   var portEl = byId("lhmPort");
   if (hostEl) {
@@ -538,6 +794,20 @@ function bindUIHandlers() {
   if (portEl) {
     portEl.addEventListener("change", saveSourceProfile);
   }
+  var schemeEl = byId("lhmScheme");
+  if (schemeEl) {
+    schemeEl.addEventListener("change", function() {
+      updateSourceTypeVisibility();
+      saveSourceProfile();
+    });
+  }
+  ["lhmPath", "lhmCAFile", "lhmSkipVerify", "lhmUsername", "lhmPassword", "lhmToken",
+    "recordDir", "statWindows", "replayFile", "replaySpeed"].forEach(function(id) {
+    var el = byId(id);
+    if (el) {
+      el.addEventListener("change", saveSourceProfile);
+    }
+  });
 
   pollEl.addEventListener("change", function(e) {
     if (!websocket || websocket.readyState !== 1) {
@@ -567,6 +837,74 @@ function bindUIHandlers() {
     });
   });
 
+  var tempUnitEl = byId("temperatureUnit");
+  if (tempUnitEl) {
+    tempUnitEl.addEventListener("change", function(e) {
+      if (!websocket || websocket.readyState !== 1) {
+        return;
+      }
+      sendJson({
+        action: action,
+        event: "sendToPlugin",
+        context: sdkContext(),
+        payload: {
+          setTemperatureUnit: e.target.value
+        }
+      });
+    });
+  }
+
+  var historyMaxAgeEl = byId("historyMaxAge");
+  if (historyMaxAgeEl) {
+    historyMaxAgeEl.addEventListener("change", function(e) {
+      if (!websocket || websocket.readyState !== 1) {
+        return;
+      }
+      sendJson({
+        action: action,
+        event: "sendToPlugin",
+        context: sdkContext(),
+        payload: {
+          setHistoryMaxAge: parseInt(e.target.value, 10)
+        }
+      });
+    });
+  }
+
+  var renderingSelectEl = byId("rendering");
+  if (renderingSelectEl) {
+    renderingSelectEl.addEventListener("change", function(e) {
+      if (!websocket || websocket.readyState !== 1) {
+        return;
+      }
+      sendJson({
+        action: action,
+        event: "sendToPlugin",
+        context: sdkContext(),
+        payload: {
+          setRendering: e.target.value
+        }
+      });
+    });
+  }
+
+  var pngCompressionEl = byId("pngCompression");
+  if (pngCompressionEl) {
+    pngCompressionEl.addEventListener("change", function(e) {
+      if (!websocket || websocket.readyState !== 1) {
+        return;
+      }
+      sendJson({
+        action: action,
+        event: "sendToPlugin",
+        context: sdkContext(),
+        payload: {
+          setPngCompression: e.target.value
+        }
+      });
+    });
+  }
+
   bgEl.addEventListener("change", scheduleTileSettingsSave);
   bgEl.addEventListener("input", scheduleTileSettingsSave);
   textEl.addEventListener("change", scheduleTileSettingsSave);
@@ -29,7 +29,9 @@ func newActionManager(interval time.Duration) *actionManager {
 	}
 }
 
-func (tm *actionManager) Run(updateTiles func(*actionData), onTick func()) {
+// Run ticks every update interval and hands the valid tiles to pool; onTick
+// adds the renders of the other tile types to the same tick.
+func (tm *actionManager) Run(pool *renderPool, updateTiles func(*actionData), onTick func(*renderTick)) {
 	go func() {
 		ticker := time.NewTicker(tm.updateInterval)
 		defer ticker.Stop()
@@ -58,15 +60,19 @@ func (tm *actionManager) Run(updateTiles func(*actionData), onTick func()) {
 				}
 				tm.mux.Unlock()
 
+				tick := pool.beginTick(tm.GetInterval())
 				for _, data := range toUpdate {
-					updateTiles(data)
-					tm.mux.Lock()
-					tm.lastRun[data.context] = now
-					tm.mux.Unlock()
+					tick.render(data.context, func() {
+						updateTiles(data)
+						tm.mux.Lock()
+						tm.lastRun[data.context] = now
+						tm.mux.Unlock()
+					})
 				}
 				if onTick != nil {
-					onTick()
+					onTick(tick)
 				}
+				tick.end()
 			}
 		}
 	}()
@@ -154,6 +154,7 @@ func (p *Plugin) sendCatalogToPropertyInspector(action, context string, settings
 		Readings:       make([]*evSendReadingsPayloadReading, 0),
 		Favorites:      p.favoriteReadingsSnapshotForSource(profileID),
 		SourceProfiles: profiles,
+		StatWindows:    p.statWindowTokens(profileID),
 	}
 
 	for _, sensor := range sensors {
@@ -58,6 +58,7 @@ type stubReading struct {
 	typ   string
 	label string
 	unit  string
+	value float64
 }
 
 func (r stubReading) ID() int32                { return r.id }
@@ -65,7 +66,7 @@ func (r stubReading) TypeI() int32             { return 0 }
 func (r stubReading) Type() string             { return r.typ }
 func (r stubReading) Label() string            { return r.label }
 func (r stubReading) Unit() string             { return r.unit }
-func (r stubReading) Value() float64           { return 0 }
+func (r stubReading) Value() float64           { return r.value }
 func (r stubReading) ValueNormalized() float64 { return 0 }
 func (r stubReading) ValueMin() float64        { return 0 }
 func (r stubReading) ValueMax() float64        { return 0 }
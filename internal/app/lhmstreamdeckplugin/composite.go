@@ -1,12 +1,10 @@
 package lhmstreamdeckplugin
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
 	"image"
 	"image/color"
-	"image/png"
 	"log"
 	"math"
 	"strconv"
@@ -28,6 +26,10 @@ const (
 	compositeModeBoth  = "both"
 )
 
+// compositeLayoutMirrored draws slot 1 growing up from a centre line and slot
+// 2 growing down from it, for pairs like upload and download speed.
+const compositeLayoutMirrored = "mirrored"
+
 // compositeSlotDefaults mirrors the original tile colours exactly for slot 0,
 // with a per-slot hue shift for slots 1–3 so readings are distinguishable.
 var compositeSlotDefaults = [4]compositeSlotSettings{
@@ -49,23 +51,21 @@ type compositeState struct {
 
 // newCompositeGraph creates a graph.Graph for one slot using its settings.
 // FillAlpha scales the foreground (fill) colour; highlight stays at full brightness.
-func newCompositeGraph(slot *compositeSlotSettings) *graph.Graph {
+func newCompositeGraph(slot *compositeSlotSettings, window time.Duration, height, pixelRatio int) *graph.Graph {
 	fgColor := hexToRGBA(slot.ForegroundColor)
 	bgColor := hexToRGBA(slot.BackgroundColor)
 	hlColor := hexToRGBA(slot.HighlightColor)
 
-	// Apply FillAlpha to the fill colour (0–100 → 0.0–1.0 scale).
-	if slot.FillAlpha != 100 {
-		f := float64(slot.FillAlpha) / 100.0
-		fgColor = &color.RGBA{
-			R: uint8(math.Round(float64(fgColor.R) * f)),
-			G: uint8(math.Round(float64(fgColor.G) * f)),
-			B: uint8(math.Round(float64(fgColor.B) * f)),
-			A: 255,
-		}
-	}
+	fill := applyFillAlpha(*fgColor, slot.FillAlpha)
 
-	g := graph.NewGraph(tileWidth, tileHeight, slot.Min, slot.Max, fgColor, bgColor, hlColor)
+	g := graph.NewGraph(tileWidth, height, slot.Min, slot.Max, &fill, bgColor, hlColor)
+	g.SetPixelRatio(pixelRatio)
+	g.SetGradient(compositeGradient(slot))
+	g.SetStyle(graphStyle(slot.GraphStyle))
+	g.SetScale(graphScale(slot.GraphScale))
+	g.SetLogFloor(slot.LogFloor)
+	g.SetAutoScale(graphAutoScale(slot.AutoScale))
+	g.SetTimeWindow(window)
 	if slot.GraphHeightPct > 0 {
 		g.SetHeightPct(slot.GraphHeightPct)
 	}
@@ -79,15 +79,109 @@ func newCompositeGraph(slot *compositeSlotSettings) *graph.Graph {
 	return g
 }
 
-// initCompositeGraphs creates fresh graph.Graph instances for all slots.
-func initCompositeGraphs(settings *compositeActionSettings) [4]*graph.Graph {
+// applyFillAlpha dims a fill colour by FillAlpha (0–100), so slots overlaid
+// with blendLighten do not drown each other out.
+func applyFillAlpha(c color.RGBA, alpha int) color.RGBA {
+	if alpha == 100 {
+		return c
+	}
+	f := float64(alpha) / 100.0
+	return color.RGBA{
+		R: uint8(math.Round(float64(c.R) * f)),
+		G: uint8(math.Round(float64(c.G) * f)),
+		B: uint8(math.Round(float64(c.B) * f)),
+		A: 255,
+	}
+}
+
+// compositeGradient returns the slot's gradient dimmed like its fill colour.
+func compositeGradient(slot *compositeSlotSettings) []graph.GradientStop {
+	stops := parseGradient(slot.GraphGradient)
+	for i := range stops {
+		stops[i].Color = applyFillAlpha(stops[i].Color, slot.FillAlpha)
+	}
+	return stops
+}
+
+// initCompositeGraphs creates fresh graph.Graph instances for all slots,
+// drawn at pixelRatio when smooth.
+func initCompositeGraphs(settings *compositeActionSettings, pixelRatio int) [4]*graph.Graph {
 	var gs [4]*graph.Graph
 	for i := 0; i < 4; i++ {
-		gs[i] = newCompositeGraph(&settings.Slots[i])
+		gs[i] = newCompositeGraph(&settings.Slots[i], graphTimeWindow(settings.GraphWindow), compositeGraphHeight(settings), pixelRatio)
 	}
+	linkCompositeGraphs(settings, &gs)
 	return gs
 }
 
+func compositeMirrored(settings *compositeActionSettings) bool {
+	return settings.Layout == compositeLayoutMirrored
+}
+
+// compositeSlotsShown is the number of slots the tile polls and draws; the
+// mirrored layout has room for two.
+func compositeSlotsShown(settings *compositeActionSettings) int {
+	if compositeMirrored(settings) {
+		return 2
+	}
+	return settings.SlotCount
+}
+
+// compositeGraphHeight is the canvas height of each slot graph: the mirrored
+// layout gives each of its two slots half the tile.
+func compositeGraphHeight(settings *compositeActionSettings) int {
+	if compositeMirrored(settings) {
+		return tileHeight / 2
+	}
+	return tileHeight
+}
+
+// linkCompositeGraphs lets the two halves of a mirrored tile auto scale
+// together, so upload and download compare at the same scale; overlaid slots
+// scale on their own. Unlike a dial page and its mirror, each half still
+// freezes on its own slot's sticky threshold, since each slot has its own.
+func linkCompositeGraphs(settings *compositeActionSettings, gs *[4]*graph.Graph) {
+	if compositeMirrored(settings) && gs[0] != nil && gs[1] != nil {
+		graph.ShareScale(gs[0], gs[1])
+		return
+	}
+	for _, g := range gs {
+		if g != nil {
+			graph.ShareScale(g)
+		}
+	}
+}
+
+// restoreCompositeHistory refills the slot graphs and smoothing of a new
+// state from the saved history and shows the result, so the tile does not sit
+// on its action image until the next poll.
+func (p *Plugin) restoreCompositeHistory(ctx string, settings *compositeActionSettings, state *compositeState) {
+	var texts [4]string
+	restored := false
+	for i := 0; i < compositeSlotsShown(settings) && i < 4; i++ {
+		texts[i] = "—"
+		if !settings.Slots[i].IsValid || settings.Slots[i].SensorUID == "" {
+			continue
+		}
+		e, ok := p.restoreGraphHistory(ctx+"|"+strconv.Itoa(i), compositeHistoryKey(settings, i), state.graphs[i])
+		if !ok {
+			continue
+		}
+		restored = true
+		texts[i] = e.Text
+		if e.Smoothed != nil {
+			state.smoothedValues[i] = *e.Smoothed
+			state.smoothedInit[i] = true
+		}
+	}
+	if !restored {
+		return
+	}
+	if err := p.setImage(ctx, renderCompositeTile(settings, state, texts, [4]*Threshold{})); err != nil {
+		log.Printf("composite SetImage: %v", err)
+	}
+}
+
 // rebuildCompositeGraph replaces the graph for one slot after settings change.
 func (p *Plugin) rebuildCompositeGraph(ctx string, slotIdx int) {
 	p.mu.Lock()
@@ -97,7 +191,8 @@ func (p *Plugin) rebuildCompositeGraph(ctx string, slotIdx int) {
 	if !ok1 || !ok2 {
 		return
 	}
-	state.graphs[slotIdx] = newCompositeGraph(&settings.Slots[slotIdx])
+	state.graphs[slotIdx] = newCompositeGraph(&settings.Slots[slotIdx], graphTimeWindow(settings.GraphWindow), compositeGraphHeight(settings), p.pixelRatioLocked(ctx))
+	linkCompositeGraphs(settings, &state.graphs)
 }
 
 // decodeCompositeSettings decodes raw JSON and fills in defaults for missing fields.
@@ -117,6 +212,7 @@ func decodeCompositeSettings(raw *json.RawMessage) (compositeActionSettings, err
 	for i := range s.Slots {
 		d := compositeSlotDefaults[i]
 		slot := &s.Slots[i]
+		migrateSymmetricAutoScale(&slot.AutoScale, &slot.GraphScale)
 		if slot.ForegroundColor == "" {
 			slot.ForegroundColor = d.ForegroundColor
 		}
@@ -194,34 +290,16 @@ func blendLighten(dst, src *image.RGBA) {
 	}
 }
 
-// decodePNGToRGBA decodes a PNG byte slice into an *image.RGBA.
-func decodePNGToRGBA(b []byte) (*image.RGBA, error) {
-	img, err := png.Decode(bytes.NewReader(b))
-	if err != nil {
-		return nil, err
-	}
-	rgba, ok := img.(*image.RGBA)
-	if !ok {
-		// Convert to RGBA if not already.
-		bounds := img.Bounds()
-		rgba = image.NewRGBA(bounds)
-		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
-			for x := bounds.Min.X; x < bounds.Max.X; x++ {
-				rgba.Set(x, y, img.At(x, y))
-			}
-		}
-	}
-	return rgba, nil
-}
-
 // drawCompositeCenteredText draws txt centred horizontally on img at baselineY.
 // When strokeClr is non-nil, a 1 px outline is drawn in that color first.
-func drawCompositeCenteredText(img *image.RGBA, txt string, baselineY int, size float64, clr *color.RGBA, strokeClr *color.RGBA) {
+// img is scale times the tile size; baselineY, size and the outline are in
+// tile pixels.
+func drawCompositeCenteredText(img *image.RGBA, scale float64, txt string, baselineY int, size float64, clr *color.RGBA, strokeClr *color.RGBA) {
 	if txt == "" || clr == nil {
 		return
 	}
 	fm := graph.GetSharedFontFaceManager()
-	f, err := fm.GetFaceOfSize(size)
+	f, err := fm.GetFaceOfSize(size * scale)
 	if err != nil {
 		log.Printf("composite drawText font: %v", err)
 		return
@@ -232,10 +310,10 @@ func drawCompositeCenteredText(img *image.RGBA, txt string, baselineY int, size
 			w += float64(adv) / 64
 		}
 	}
-	cx := float64(tileWidth)/2 - w/2
+	cx := float64(img.Rect.Dx())/2 - w/2
 	pt := fixed.Point26_6{
 		X: fixed.Int26_6(cx * 64),
-		Y: fixed.Int26_6(float64(baselineY) * 64),
+		Y: fixed.Int26_6(float64(baselineY) * scale * 64),
 	}
 	d := &font.Drawer{Dst: img, Face: f}
 	if strokeClr != nil {
@@ -246,8 +324,8 @@ func drawCompositeCenteredText(img *image.RGBA, txt string, baselineY int, size
 					continue
 				}
 				d.Dot = fixed.Point26_6{
-					X: pt.X + fixed.Int26_6(dx*64),
-					Y: pt.Y + fixed.Int26_6(dy*64),
+					X: pt.X + fixed.Int26_6(float64(dx)*scale*64),
+					Y: pt.Y + fixed.Int26_6(float64(dy)*scale*64),
 				}
 				d.DrawString(txt)
 			}
@@ -260,31 +338,35 @@ func drawCompositeCenteredText(img *image.RGBA, txt string, baselineY int, size
 
 // renderCompositeTile blends the per-slot graph.Graph renders additively,
 // then draws text labels on top — matching the original tile's visual style.
-func renderCompositeTile(settings *compositeActionSettings, state *compositeState, displayTexts [4]string, activeThresholds [4]*Threshold) ([]byte, error) {
-	n := settings.SlotCount
+// The mirrored layout draws the two slot graphs back to back instead.
+func renderCompositeTile(settings *compositeActionSettings, state *compositeState, displayTexts [4]string, activeThresholds [4]*Threshold) *image.RGBA {
+	n := compositeSlotsShown(settings)
+	scale := state.graphs[0].RenderRatio() // every slot is drawn at the same ratio
 
 	// Start with a black canvas.
-	canvas := image.NewRGBA(image.Rect(0, 0, tileWidth, tileHeight))
+	canvas := image.NewRGBA(image.Rect(0, 0, tileWidth*scale, tileHeight*scale))
 
 	// --- graph layer: render each graph.Graph, blend onto canvas ---
-	for i := 0; i < n; i++ {
-		mode := effectiveCompositeSlotMode(settings.Mode, settings.Slots[i].Mode)
-		if !compositeModeHasGraph(mode) {
-			continue
-		}
-		g := state.graphs[i]
-		if g == nil {
-			continue
-		}
-		b, err := g.EncodePNG()
-		if err != nil {
-			continue
+	if compositeMirrored(settings) {
+		var halves [2]*graph.Graph
+		for i := range halves {
+			if compositeModeHasGraph(effectiveCompositeSlotMode(settings.Mode, settings.Slots[i].Mode)) {
+				halves[i] = state.graphs[i]
+			}
 		}
-		slotImg, err := decodePNGToRGBA(b)
-		if err != nil {
-			continue
+		graph.DrawMirrored(canvas, halves[0], halves[1])
+	} else {
+		for i := 0; i < n; i++ {
+			mode := effectiveCompositeSlotMode(settings.Mode, settings.Slots[i].Mode)
+			if !compositeModeHasGraph(mode) {
+				continue
+			}
+			g := state.graphs[i]
+			if g == nil {
+				continue
+			}
+			blendLighten(canvas, g.Image())
 		}
-		blendLighten(canvas, slotImg)
 	}
 
 	// --- text layer: label + value centred per slot zone, drawn over graph ---
@@ -322,16 +404,11 @@ func renderCompositeTile(settings *compositeActionSettings, state *compositeStat
 				valueClr = hexToRGBA(t.ValueTextColor)
 			}
 		}
-		drawCompositeCenteredText(canvas, label, labelY, titleSz, titleClr, strokeClr)
-		drawCompositeCenteredText(canvas, displayTexts[i], valueY, valueSz, valueClr, strokeClr)
+		drawCompositeCenteredText(canvas, float64(scale), label, labelY, titleSz, titleClr, strokeClr)
+		drawCompositeCenteredText(canvas, float64(scale), displayTexts[i], valueY, valueSz, valueClr, strokeClr)
 	}
 
-	var buf bytes.Buffer
-	enc := &png.Encoder{CompressionLevel: png.NoCompression}
-	if err := enc.Encode(&buf, canvas); err != nil {
-		return nil, err
-	}
-	return buf.Bytes(), nil
+	return canvas
 }
 
 // applyNormalCompositeColors resets a slot's graph to its configured (non-alert) colors.
@@ -348,6 +425,7 @@ func applyNormalCompositeColors(g *graph.Graph, slot *compositeSlotSettings) {
 	if slot.ValueTextColor != "" {
 		g.SetLabelColor(1, hexToRGBA(slot.ValueTextColor))
 	}
+	g.SetGradient(compositeGradient(slot))
 }
 
 // --- update logic ---
@@ -390,7 +468,7 @@ func (p *Plugin) updateCompositeTile(ctx string) {
 
 	profileID := p.resolvedSourceProfileID(settings.SourceProfileID)
 	pollTime, err := p.getCachedPollTimeForSource(profileID)
-	if err != nil || pollTime == 0 || time.Since(time.Unix(0, int64(pollTime))) > 5*time.Second {
+	if err != nil || p.pollTimeStale(pollTime) {
 		return
 	}
 	if !forceUpdate && pollTime == state.lastPollTime {
@@ -411,7 +489,10 @@ func (p *Plugin) updateCompositeTile(ctx string) {
 	var displayTexts [4]string
 	var activeThresholds [4]*Threshold
 	now := time.Now()
-	n := settings.SlotCount
+	n := compositeSlotsShown(settings)
+	temperatureUnit := p.temperatureUnit()
+	thresholdsMigrated := false
+	view, viewErr := p.viewForSource(profileID)
 
 	for i := 0; i < n; i++ {
 		slot := &settings.Slots[i]
@@ -420,7 +501,11 @@ func (p *Plugin) updateCompositeTile(ctx string) {
 			continue
 		}
 
-		r, _, err := p.getReadingForSource(profileID, slot.SensorUID, slot.ReadingID)
+		if viewErr != nil {
+			displayTexts[i] = "—"
+			continue
+		}
+		r, err := view.reading(slot.SensorUID, slot.ReadingID)
 		if err != nil {
 			displayTexts[i] = "—"
 			continue
@@ -432,23 +517,15 @@ func (p *Plugin) updateCompositeTile(ctx string) {
 			v = v / divisor
 		}
 
-		graphValue := r.Value()
-		if slot.GraphUnit != "" && strings.Contains(r.Unit(), "/s") {
-			graphValue = p.normalizeForGraph(r.Value(), r.Unit(), slot.GraphUnit)
-		}
-		if divisor != 1 {
-			graphValue = graphValue / divisor
-		}
+		unit := resolveTileUnit(r.Unit(), effectiveDisplayUnit(r.Unit(), slot.DisplayUnit, temperatureUnit), slot.GraphUnit)
+		graphValue := unit.convert(v) // divisor first, as on reading tiles
 
 		// Format display text — same logic as updateTiles.
-		displayUnit := r.Unit()
-		displayV := v
-		if slot.GraphUnit != "" && strings.Contains(r.Unit(), "/s") {
-			displayV = p.normalizeForGraph(v, r.Unit(), slot.GraphUnit)
-			displayUnit = slot.GraphUnit + "/s"
-		}
+		displayUnit := unit.symbol
+		displayV := unit.convert(v)
 
 		// EMA smoothing per slot
+		var ema *float64
 		if alpha := settings.SmoothingAlpha; alpha > 0 && alpha < 1.0 {
 			p.mu.Lock()
 			if !state.smoothedInit[i] {
@@ -458,6 +535,7 @@ func (p *Plugin) updateCompositeTile(ctx string) {
 			smoothed := alpha*graphValue + (1-alpha)*state.smoothedValues[i]
 			state.smoothedValues[i] = smoothed
 			p.mu.Unlock()
+			ema = &smoothed
 			if graphValue != 0 {
 				ratio := smoothed / graphValue
 				graphValue = smoothed
@@ -465,12 +543,26 @@ func (p *Plugin) updateCompositeTile(ctx string) {
 			}
 		}
 
-		// Threshold evaluation per slot — uses raw v, synthetic context key
+		// Threshold evaluation per slot — uses raw v, synthetic context key;
+		// temperatures compare in the slot's unit (see updateTiles)
 		slotCtx := ctx + "|" + strconv.Itoa(i)
+		evalValue := v
+		if unit.isTemperature() {
+			p.mu.Lock()
+			if migrated, ok := convertThresholds(slot.Thresholds, unit.to, false); ok {
+				slot.Thresholds = migrated
+				thresholdsMigrated = true
+			}
+			p.mu.Unlock()
+			evalValue = unit.convert(v)
+		}
 		p.mu.RLock()
 		slotThresholds := p.resolveThresholdsForEval(slot.Thresholds, slot.SuppressedGlobalIDs, hwsensorsservice.ReadingType(r.TypeI()))
 		p.mu.RUnlock()
-		active := p.evaluateThresholds(slotCtx, v, slotThresholds, now)
+		if unit.isTemperature() {
+			slotThresholds, _ = convertThresholds(slotThresholds, unit.to, false)
+		}
+		active := p.evaluateThresholds(slotCtx, evalValue, slotThresholds, now)
 		activeThresholds[i] = active
 
 		// Feed value into the graph.Graph — same as the original tile.
@@ -479,6 +571,11 @@ func (p *Plugin) updateCompositeTile(ctx string) {
 		p.mu.RUnlock()
 		if g != nil {
 			g.Update(graphValue)
+			if slot.ShowThresholdLines {
+				g.SetMarkers(thresholdMarkers(slotThresholds, unit.thresholdToGraph(), g.HighlightColor()))
+			} else {
+				g.SetMarkers(nil)
+			}
 			if forceUpdate || active != nil != (slot.CurrentThresholdID != "") {
 				if active != nil {
 					p.applyThresholdColors(g, active)
@@ -501,6 +598,14 @@ func (p *Plugin) updateCompositeTile(ctx string) {
 
 		_, txt := p.formatDisplayValue(displayV, displayUnit, slot.Format, hwsensorsservice.ReadingType(r.TypeI()))
 		displayTexts[i] = txt
+
+		var sample *float64
+		var window time.Duration
+		if g != nil {
+			sample = &graphValue
+			window = g.TimeWindow()
+		}
+		p.history.record(slotCtx, compositeHistoryKey(settings, i), now, window, sample, ema, "", txt)
 	}
 
 	p.mu.RLock()
@@ -510,13 +615,13 @@ func (p *Plugin) updateCompositeTile(ctx string) {
 	if !ok3 || !ok4 {
 		return
 	}
-
-	b, err := renderCompositeTile(latestSettings, latestState, displayTexts, activeThresholds)
-	if err != nil {
-		log.Printf("renderCompositeTile: %v", err)
-		return
+	if thresholdsMigrated {
+		if err := p.sd.SetSettings(ctx, latestSettings); err != nil {
+			log.Printf("composite migrate thresholds SetSettings: %v", err)
+		}
 	}
-	if err := p.sd.SetImage(ctx, b); err != nil {
+
+	if err := p.setImage(ctx, renderCompositeTile(latestSettings, latestState, displayTexts, activeThresholds)); err != nil {
 		log.Printf("composite SetImage: %v", err)
 		return
 	}
@@ -531,7 +636,7 @@ func (p *Plugin) updateCompositeTile(ctx string) {
 	p.mu.Unlock()
 }
 
-func (p *Plugin) updateCompositeTick() {
+func (p *Plugin) updateCompositeTick(tick *renderTick) {
 	p.mu.RLock()
 	contexts := make([]string, 0, len(p.compositeSettings))
 	for ctx := range p.compositeSettings {
@@ -539,7 +644,7 @@ func (p *Plugin) updateCompositeTick() {
 	}
 	p.mu.RUnlock()
 	for _, ctx := range contexts {
-		p.updateCompositeTile(ctx)
+		tick.render(ctx, func() { p.updateCompositeTile(ctx) })
 	}
 }
 
@@ -725,6 +830,16 @@ func (p *Plugin) handleCompositeSlotField(event *streamdeck.EvSendToPlugin, sdpi
 	case "backgroundColor":
 		slot.BackgroundColor = sdpi.Value
 		rebuildGraph = true
+	case "graphStyle":
+		slot.GraphStyle = sdpi.Value
+		if state, ok2 := p.compositeStates[event.Context]; ok2 && state.graphs[slotIdx] != nil {
+			state.graphs[slotIdx].SetStyle(graphStyle(sdpi.Value))
+		}
+	case "graphGradient":
+		slot.GraphGradient = sdpi.Value
+		if state, ok2 := p.compositeStates[event.Context]; ok2 && state.graphs[slotIdx] != nil {
+			state.graphs[slotIdx].SetGradient(compositeGradient(slot))
+		}
 	case "valueTextColor":
 		slot.ValueTextColor = sdpi.Value
 	case "titleColor":
@@ -758,6 +873,23 @@ func (p *Plugin) handleCompositeSlotField(event *streamdeck.EvSendToPlugin, sdpi
 				}
 			}
 		}
+	case "autoScale":
+		slot.AutoScale = sdpi.Value
+		if state, ok2 := p.compositeStates[event.Context]; ok2 && state.graphs[slotIdx] != nil {
+			state.graphs[slotIdx].SetAutoScale(graphAutoScale(sdpi.Value))
+		}
+	case "graphScale":
+		slot.GraphScale = sdpi.Value
+		if state, ok2 := p.compositeStates[event.Context]; ok2 && state.graphs[slotIdx] != nil {
+			state.graphs[slotIdx].SetScale(graphScale(sdpi.Value))
+		}
+	case "logFloor":
+		if v, err := strconv.ParseFloat(sdpi.Value, 64); err == nil && v >= 0 {
+			slot.LogFloor = v
+			if state, ok2 := p.compositeStates[event.Context]; ok2 && state.graphs[slotIdx] != nil {
+				state.graphs[slotIdx].SetLogFloor(v)
+			}
+		}
 	case "titleFontSize":
 		if v, err := strconv.ParseFloat(sdpi.Value, 64); err == nil {
 			slot.TitleFontSize = v
@@ -772,6 +904,8 @@ func (p *Plugin) handleCompositeSlotField(event *streamdeck.EvSendToPlugin, sdpi
 		slot.Divisor = sdpi.Value
 	case "graphUnit":
 		slot.GraphUnit = sdpi.Value
+	case "displayUnit":
+		slot.DisplayUnit = sdpi.Value
 	case "graphHeightPct":
 		if v, err := strconv.Atoi(sdpi.Value); err == nil && v >= 10 && v <= 100 {
 			slot.GraphHeightPct = v
@@ -791,6 +925,8 @@ func (p *Plugin) handleCompositeSlotField(event *streamdeck.EvSendToPlugin, sdpi
 		if state, ok2 := p.compositeStates[event.Context]; ok2 && state.graphs[slotIdx] != nil {
 			state.graphs[slotIdx].SetTextStroke(sdpi.Checked)
 		}
+	case "showThresholdLines":
+		slot.ShowThresholdLines = sdpi.Checked
 	case "textStrokeColor":
 		slot.TextStrokeColor = sdpi.Value
 		if state, ok2 := p.compositeStates[event.Context]; ok2 && state.graphs[slotIdx] != nil {
@@ -812,7 +948,7 @@ func (p *Plugin) handleCompositeSlotField(event *streamdeck.EvSendToPlugin, sdpi
 	}
 }
 
-// handleCompositeGlobalField updates a tile-wide field (mode, slotCount).
+// handleCompositeGlobalField updates a tile-wide field (mode, layout, slotCount, graphWindow).
 func (p *Plugin) handleCompositeGlobalField(event *streamdeck.EvSendToPlugin, sdpi *evSdpiCollection) {
 	p.mu.Lock()
 	settings, ok := p.compositeSettings[event.Context]
@@ -823,10 +959,29 @@ func (p *Plugin) handleCompositeGlobalField(event *streamdeck.EvSendToPlugin, sd
 	switch sdpi.Key {
 	case "composite_mode":
 		settings.Mode = sdpi.Value
+	case "composite_layout":
+		settings.Layout = sdpi.Value
+		if state, ok2 := p.compositeStates[event.Context]; ok2 {
+			for _, g := range state.graphs {
+				if g != nil {
+					g.SetHeight(compositeGraphHeight(settings))
+				}
+			}
+			linkCompositeGraphs(settings, &state.graphs)
+		}
 	case "composite_slotCount":
 		if v, err := strconv.Atoi(sdpi.Value); err == nil && v >= 2 && v <= 4 {
 			settings.SlotCount = v
 		}
+	case "composite_graphWindow":
+		settings.GraphWindow = sdpi.Value
+		if state, ok2 := p.compositeStates[event.Context]; ok2 {
+			for _, g := range state.graphs {
+				if g != nil {
+					g.SetTimeWindow(graphTimeWindow(sdpi.Value))
+				}
+			}
+		}
 	case "updateIntervalOverrideMs":
 		if v, err := strconv.Atoi(sdpi.Value); err == nil {
 			settings.UpdateIntervalOverrideMs = v
@@ -874,6 +1029,7 @@ func (p *Plugin) handleCompositeAddThreshold(event *streamdeck.EvSendToPlugin, s
 		HighlightColor:  defaultColor(slot.HighlightColor, "#009e00"),
 		ValueTextColor:  defaultColor(slot.ValueTextColor, "#ffffff"),
 		TextColor:       defaultColor(slot.ValueTextColor, "#ffffff"),
+		Unit:            thresholdUnit(slot.DisplayUnit, p.globalSettings.TemperatureUnit),
 	}
 	slot.Thresholds = append(slot.Thresholds, newT)
 	p.mu.Unlock()
@@ -3,6 +3,8 @@ package lhmstreamdeckplugin
 import (
 	"encoding/json"
 	"testing"
+
+	"github.com/moeilijk/lhm-streamdeck/pkg/graph"
 )
 
 func TestEffectiveCompositeSlotMode(t *testing.T) {
@@ -78,18 +80,11 @@ func TestRenderCompositeTileHonorsPerSlotGraphMode(t *testing.T) {
 			},
 		},
 	}
-	state := &compositeState{graphs: initCompositeGraphs(&settings)}
+	state := &compositeState{graphs: initCompositeGraphs(&settings, keyPixelRatio)}
 	state.graphs[0].Update(100)
 	state.graphs[1].Update(100)
 
-	imgBytes, err := renderCompositeTile(&settings, state, [4]string{}, [4]*Threshold{})
-	if err != nil {
-		t.Fatalf("renderCompositeTile: %v", err)
-	}
-	img, err := decodePNGToRGBA(imgBytes)
-	if err != nil {
-		t.Fatalf("decodePNGToRGBA: %v", err)
-	}
+	img := renderCompositeTile(&settings, state, [4]string{}, [4]*Threshold{})
 
 	redPixels := 0
 	greenPixels := 0
@@ -113,3 +108,93 @@ func TestRenderCompositeTileHonorsPerSlotGraphMode(t *testing.T) {
 		t.Fatalf("text-only slot rendered %d green graph pixels", greenPixels)
 	}
 }
+
+func TestRenderCompositeTileMirrored(t *testing.T) {
+	settings := compositeActionSettings{
+		SlotCount: 3,
+		Mode:      compositeModeGraph,
+		Layout:    compositeLayoutMirrored,
+	}
+	for i, fg := range []string{"#ff0000", "#0000ff", "#00ff00"} {
+		settings.Slots[i] = compositeSlotSettings{
+			ForegroundColor: fg,
+			HighlightColor:  fg,
+			BackgroundColor: "#000000",
+			FillAlpha:       100,
+			Max:             100,
+			AutoScale:       "window",
+		}
+	}
+	state := &compositeState{graphs: initCompositeGraphs(&settings, keyPixelRatio)}
+	// Auto scale is shared, so 50 on slot 1 fills half its side while slot 2
+	// runs at 100.
+	for _, v := range []float64{0, 50} {
+		state.graphs[0].Update(v)
+		state.graphs[1].Update(100)
+		state.graphs[2].Update(100)
+	}
+
+	img := renderCompositeTile(&settings, state, [4]string{}, [4]*Threshold{})
+
+	var red, blue, green int
+	for y := 0; y < tileHeight; y++ {
+		p := img.RGBAAt(tileWidth-1, y)
+		switch {
+		case p.R > 0:
+			red++
+			if y >= tileHeight/2 {
+				t.Fatalf("slot 1 drawn below the centre line at row %d", y)
+			}
+		case p.B > 0:
+			blue++
+			if y < tileHeight/2 {
+				t.Fatalf("slot 2 drawn above the centre line at row %d", y)
+			}
+		case p.G > 0:
+			green++
+		}
+	}
+	if blue != tileHeight/2 {
+		t.Fatalf("slot 2 fills %d rows, want its whole half", blue)
+	}
+	if red < tileHeight/4-1 || red > tileHeight/4+1 {
+		t.Fatalf("slot 1 fills %d rows, want about half its half", red)
+	}
+	if green != 0 {
+		t.Fatal("slot 3 drawn in the mirrored layout")
+	}
+}
+
+func TestRenderCompositeTileSmoothIsHighDPI(t *testing.T) {
+	graph.SetRendering(graph.RenderingSmooth)
+	defer graph.SetRendering(graph.RenderingPixel)
+	settings := compositeActionSettings{
+		SlotCount: 2,
+		Mode:      compositeModeGraph,
+		Layout:    compositeLayoutMirrored,
+	}
+	for i, fg := range []string{"#ff0000", "#0000ff"} {
+		settings.Slots[i] = compositeSlotSettings{
+			ForegroundColor: fg,
+			HighlightColor:  fg,
+			BackgroundColor: "#000000",
+			FillAlpha:       100,
+			Max:             100,
+		}
+	}
+	state := &compositeState{graphs: initCompositeGraphs(&settings, keyPixelRatio)}
+	state.graphs[0].Update(100)
+	state.graphs[1].Update(100)
+
+	img := renderCompositeTile(&settings, state, [4]string{}, [4]*Threshold{})
+	size := tileWidth * keyPixelRatio
+	if b := img.Bounds(); b.Dx() != size || b.Dy() != size {
+		t.Fatalf("tile is %v, want %dx%d", b, size, size)
+	}
+	if p := img.RGBAAt(size/2, size/2-2); p.R == 0 {
+		t.Errorf("slot 1 missing above the centre line: %v", p)
+	}
+	if p := img.RGBAAt(size/2, size/2+1); p.B == 0 {
+		t.Errorf("slot 2 missing below the centre line: %v", p)
+	}
+}
@@ -2,6 +2,7 @@ package lhmstreamdeckplugin
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"image/color"
 	"log"
@@ -9,6 +10,7 @@ import (
 	"time"
 
 	"github.com/gorilla/websocket"
+	lhmplugin "github.com/moeilijk/lhm-streamdeck/internal/lhm/plugin"
 	"github.com/moeilijk/lhm-streamdeck/pkg/graph"
 	hwsensorsservice "github.com/moeilijk/lhm-streamdeck/pkg/service"
 	"github.com/moeilijk/lhm-streamdeck/pkg/streamdeck"
@@ -17,6 +19,11 @@ import (
 const (
 	tileWidth  = 72
 	tileHeight = 72
+
+	// keyPixelRatio is the scale key images are drawn at when rendering
+	// smooth on a device whose key size is not known: 144x144, the high-DPI
+	// key size, which the Stream Deck app scales to the key of the device.
+	keyPixelRatio = 2
 )
 
 func boolPtr(v bool) *bool {
@@ -51,7 +58,7 @@ func isSettingsPayload(m map[string]*json.RawMessage) bool {
 	if m == nil {
 		return false
 	}
-	for _, k := range []string{"settingsConnected", "setPollInterval", "setLhmEndpoint", "updateTileAppearance",
+	for _, k := range []string{"settingsConnected", "setPollInterval", "setTemperatureUnit", "setPngCompression", "setHistoryMaxAge", "setRendering", "setLhmEndpoint", "updateTileAppearance",
 		"addSourceProfile", "deleteSourceProfile", "setSourceProfile", "setDefaultSourceProfile",
 		"setSelectedSourceProfile", "requestSettingsStatus",
 		"addGlobalThreshold", "deleteGlobalThreshold", "updateGlobalThreshold"} {
@@ -62,11 +69,35 @@ func isSettingsPayload(m map[string]*json.RawMessage) bool {
 	return false
 }
 
+// maskSourceProfiles copies profiles for the property inspector with stored
+// secrets replaced by credentialMask.
+func maskSourceProfiles(profiles []lhmSourceProfile) []lhmSourceProfile {
+	out := make([]lhmSourceProfile, len(profiles))
+	copy(out, profiles)
+	for i := range out {
+		if out[i].Password != "" {
+			out[i].Password = credentialMask
+		}
+		if out[i].Token != "" {
+			out[i].Token = credentialMask
+		}
+	}
+	return out
+}
+
+// unmaskCredential returns the secret to store for a value coming back from
+// the property inspector: the mask means "unchanged".
+func unmaskCredential(stored, submitted string) string {
+	if submitted == credentialMask {
+		return stored
+	}
+	return submitted
+}
+
 func (p *Plugin) sendSettingsStatus(action, context string, includeProfiles bool) {
 	p.mu.RLock()
 	currentRate := p.globalSettings.PollInterval
-	profiles := make([]lhmSourceProfile, len(p.globalSettings.SourceProfiles))
-	copy(profiles, p.globalSettings.SourceProfiles)
+	profiles := maskSourceProfiles(p.globalSettings.SourceProfiles)
 	defaultProfileID := p.globalSettings.DefaultSourceProfileID
 	var selectedProfileID string
 	if ts := p.settingsContexts[context]; ts != nil {
@@ -82,13 +113,29 @@ func (p *Plugin) sendSettingsStatus(action, context string, includeProfiles bool
 	}
 
 	status := "Disconnected"
-	if pt, err := p.getCachedPollTimeForSource(selectedProfileID); err == nil && pt != 0 {
+	pt, err := p.getCachedPollTimeForSource(selectedProfileID)
+	health := p.sourceHealthStatus(selectedProfileID)
+	if err == nil && pt != 0 {
 		status = "Connected"
+		if member := p.servingMemberName(selectedProfileID); member != "" {
+			status = "Connected via " + member
+		}
+	} else if errors.Is(err, hwsensorsservice.ErrAuthFailed) {
+		status = "Auth failed"
+	} else if health.State == sourceOpen.String() {
+		status = "Offline · " + retryIn(health.NextRetry)
+	} else if health.State == sourceDegraded.String() {
+		status = "Degraded"
 	}
 
 	statusPayload := map[string]interface{}{
 		"connectionStatus": status,
 		"currentRate":      currentRate,
+		"sourceHealth":     health,
+		"frames":           p.frameStatus(),
+	}
+	if p.render != nil {
+		statusPayload["renderTiming"] = p.render.timing.status()
 	}
 	if includeProfiles {
 		statusPayload["sourceProfiles"] = profiles
@@ -110,10 +157,14 @@ func (p *Plugin) OnConnected(c *websocket.Conn) {
 
 // OnWillAppear event
 func (p *Plugin) OnWillAppear(event *streamdeck.EvWillAppear) {
+	p.frames.forget(event.Context)
 	if event.Action == dialAction && event.Payload.Controller == "Encoder" {
 		p.handleDialWillAppear(event)
 		return
 	}
+	p.mu.Lock()
+	p.keyDevices[event.Context] = event.Device
+	p.mu.Unlock()
 
 	// Handle settings action separately
 	if event.Action == "com.moeilijk.lhm.settings" {
@@ -173,19 +224,27 @@ func (p *Plugin) OnWillAppear(event *streamdeck.EvWillAppear) {
 
 	if event.Action == derivedAction {
 		ds, _ := decodeDerivedSettings(event.Payload.Settings)
+		state := &derivedState{graph: initDerivedGraph(&ds, p.pixelRatio(event.Context))}
+		p.restoreDerivedHistory(event.Context, &ds, state)
 		p.mu.Lock()
 		p.derivedSettings[event.Context] = &ds
-		p.derivedStates[event.Context] = &derivedState{graph: initDerivedGraph(&ds)}
+		p.derivedStates[event.Context] = state
 		p.mu.Unlock()
+		p.restoreThresholdRuntimeState(event.Context, time.Now())
 		return
 	}
 
 	if event.Action == compositeAction {
 		cs, _ := decodeCompositeSettings(event.Payload.Settings)
+		state := &compositeState{graphs: initCompositeGraphs(&cs, p.pixelRatio(event.Context))}
+		p.restoreCompositeHistory(event.Context, &cs, state)
 		p.mu.Lock()
 		p.compositeSettings[event.Context] = &cs
-		p.compositeStates[event.Context] = &compositeState{graphs: initCompositeGraphs(&cs)}
+		p.compositeStates[event.Context] = state
 		p.mu.Unlock()
+		for i := 0; i < 4; i++ {
+			p.restoreThresholdRuntimeState(event.Context+"|"+strconv.Itoa(i), time.Now())
+		}
 		return
 	}
 
@@ -238,18 +297,28 @@ func (p *Plugin) OnWillAppear(event *streamdeck.EvWillAppear) {
 		settings.ShowTitleInGraph = boolPtr(drawTitle)
 	}
 	g := graph.NewGraph(tileWidth, tileHeight, settings.Min, settings.Max, fgColor, bgColor, hlColor)
+	g.SetPixelRatio(p.pixelRatio(event.Context))
 	g.SetLabel(0, "", 19, tColor)
 	g.SetLabelFontSize(0, tfSize)
 	g.SetLabel(1, "", 44, vtColor)
 	g.SetLabelFontSize(1, vfSize)
 	g.SetLabel(2, "", 56, vtColor)
 	g.SetLabelFontSize(2, vfSize)
+	// Serving member of a failover group profile, empty otherwise.
+	g.SetLabel(3, "", 68, tColor)
+	g.SetLabelFontSize(3, groupMemberFontSize)
 	if settings.GraphHeightPct > 0 {
 		g.SetHeightPct(settings.GraphHeightPct)
 	}
 	if settings.GraphLineThickness > 0 {
 		g.SetLineThickness(settings.GraphLineThickness)
 	}
+	g.SetGradient(parseGradient(settings.GraphGradient))
+	g.SetStyle(graphStyle(settings.GraphStyle))
+	g.SetScale(graphScale(settings.GraphScale))
+	g.SetLogFloor(settings.LogFloor)
+	g.SetAutoScale(graphAutoScale(settings.AutoScale))
+	g.SetTimeWindow(graphTimeWindow(settings.GraphWindow))
 	g.SetTextStroke(settings.TextStroke)
 	if settings.TextStrokeColor != "" {
 		g.SetTextStrokeColor(hexToRGBA(settings.TextStrokeColor))
@@ -257,10 +326,30 @@ func (p *Plugin) OnWillAppear(event *streamdeck.EvWillAppear) {
 	if drawTitle {
 		g.SetLabelText(0, settings.Title)
 	}
+	restored, hasHistory := p.restoreGraphHistory(event.Context, readingHistoryKey(&settings), g)
+	if hasHistory {
+		if drawTitle && settings.Title == "" {
+			g.SetLabelText(0, restored.Title)
+		}
+		if settings.GraphMode != "graph" {
+			g.SetLabelText(1, restored.Text)
+		}
+		// Show the restored graph now rather than the action image until
+		// the next poll; g is not shared with the renderers yet.
+		if err := p.setImage(event.Context, g.Image()); err != nil {
+			log.Printf("OnWillAppear setImage: %v\n", err)
+		}
+	}
 	p.mu.Lock()
+	if hasHistory && restored.Smoothed != nil {
+		p.smoothedValues[event.Context] = *restored.Smoothed
+	}
 	p.graphs[event.Context] = g
 	p.mu.Unlock()
 	p.resetThresholdRuntimeState(event.Context, "")
+	// An unacknowledged sticky alarm or a snooze survives page switches and
+	// restarts.
+	p.restoreThresholdRuntimeState(event.Context, time.Now())
 
 	// Reset threshold state so updateTiles will re-evaluate and apply correct colors on first run
 	if settings.CurrentThresholdID != "" {
@@ -299,10 +388,14 @@ func (p *Plugin) OnWillAppear(event *streamdeck.EvWillAppear) {
 
 // OnWillDisappear event
 func (p *Plugin) OnWillDisappear(event *streamdeck.EvWillDisappear) {
+	p.frames.forget(event.Context)
 	if event.Action == dialAction && event.Payload.Controller == "Encoder" {
 		p.handleDialWillDisappear(event)
 		return
 	}
+	p.mu.Lock()
+	delete(p.keyDevices, event.Context)
+	p.mu.Unlock()
 
 	// Handle settings action
 	if event.Action == "com.moeilijk.lhm.settings" {
@@ -317,6 +410,7 @@ func (p *Plugin) OnWillDisappear(event *streamdeck.EvWillDisappear) {
 		delete(p.derivedSettings, event.Context)
 		delete(p.derivedStates, event.Context)
 		p.mu.Unlock()
+		p.parkThresholdRuntimeState(event.Context)
 		return
 	}
 
@@ -326,7 +420,7 @@ func (p *Plugin) OnWillDisappear(event *streamdeck.EvWillDisappear) {
 		delete(p.compositeStates, event.Context)
 		p.mu.Unlock()
 		for i := 0; i < 4; i++ {
-			p.clearThresholdRuntimeState(event.Context + "|" + strconv.Itoa(i))
+			p.parkThresholdRuntimeState(event.Context + "|" + strconv.Itoa(i))
 		}
 		return
 	}
@@ -338,8 +432,9 @@ func (p *Plugin) OnWillDisappear(event *streamdeck.EvWillDisappear) {
 	p.mu.Lock()
 	delete(p.graphs, event.Context)
 	delete(p.divisorCache, event.Context)
+	delete(p.unavailableText, event.Context)
 	p.mu.Unlock()
-	p.clearThresholdRuntimeState(event.Context)
+	p.parkThresholdRuntimeState(event.Context)
 	p.am.RemoveAction(event.Context)
 }
 
@@ -395,6 +490,14 @@ func (p *Plugin) OnApplicationDidLaunch(event *streamdeck.EvApplication) {}
 // OnApplicationDidTerminate event (unused for LHM bridge)
 func (p *Plugin) OnApplicationDidTerminate(event *streamdeck.EvApplication) {}
 
+// OnDeviceDidConnect records the pixel ratio of a connected device for the
+// keys that appear on it.
+func (p *Plugin) OnDeviceDidConnect(event *streamdeck.EvDeviceDidConnect) {
+	p.mu.Lock()
+	p.deviceRatios[event.Device] = devicePixelRatio(event.DeviceInfo.Type)
+	p.mu.Unlock()
+}
+
 // OnTitleParametersDidChange event
 func (p *Plugin) OnTitleParametersDidChange(event *streamdeck.EvTitleParametersDidChange) {
 	if p.isSettingsAction(event.Action, event.Context) {
@@ -466,6 +569,7 @@ func (p *Plugin) OnTitleParametersDidChange(event *streamdeck.EvTitleParametersD
 		if event.Payload.TitleParameters.TitleColor != "" {
 			tClr := hexToRGBA(event.Payload.TitleParameters.TitleColor)
 			g.SetLabelColor(0, tClr)
+			g.SetLabelColor(3, tClr)
 		}
 	} else {
 		g.SetLabelText(0, "")
@@ -513,8 +617,13 @@ func (p *Plugin) OnPropertyInspectorConnected(event *streamdeck.EvSendToPlugin)
 	sensors, err := p.sensorsWithTimeoutForSource(profileID, 2*time.Second)
 	if err != nil {
 		log.Println("OnPropertyInspectorConnected Sensors", err)
-		go p.restartSource(p.runtimeForSource(profileID))
-		payload := evStatus{Error: true, Message: "Libre Hardware Monitor Unavailable"}
+		message := "Libre Hardware Monitor Unavailable"
+		if errors.Is(err, hwsensorsservice.ErrAuthFailed) {
+			message = "Libre Hardware Monitor Authentication Failed"
+		} else {
+			go p.restartSource(p.runtimeForSource(profileID))
+		}
+		payload := evStatus{Error: true, Message: message}
 		if err := p.sd.SendToPropertyInspector(event.Action, event.Context, payload); err != nil {
 			log.Printf("OnPropertyInspectorConnected SendToPropertyInspector: %v\n", err)
 		}
@@ -622,6 +731,51 @@ func (p *Plugin) OnSendToPlugin(event *streamdeck.EvSendToPlugin) {
 			return
 		}
 
+		// Check for setTemperatureUnit
+		if raw, ok := payload["setTemperatureUnit"]; ok {
+			var unit string
+			if err := json.Unmarshal(*raw, &unit); err == nil {
+				if err := p.setTemperatureUnit(unit); err != nil {
+					log.Printf("setTemperatureUnit: %v\n", err)
+				}
+			}
+			return
+		}
+
+		// Check for setPngCompression
+		if raw, ok := payload["setPngCompression"]; ok {
+			var level string
+			if err := json.Unmarshal(*raw, &level); err == nil {
+				if err := p.setPNGCompression(level); err != nil {
+					log.Printf("setPngCompression: %v\n", err)
+				}
+				p.sendSettingsStatus(event.Action, targetContext, false)
+			}
+			return
+		}
+
+		// Check for setHistoryMaxAge
+		if raw, ok := payload["setHistoryMaxAge"]; ok {
+			var minutes int
+			if err := json.Unmarshal(*raw, &minutes); err == nil {
+				if err := p.setHistoryMaxAge(minutes); err != nil {
+					log.Printf("setHistoryMaxAge: %v\n", err)
+				}
+			}
+			return
+		}
+
+		// Check for setRendering
+		if raw, ok := payload["setRendering"]; ok {
+			var rendering string
+			if err := json.Unmarshal(*raw, &rendering); err == nil {
+				if err := p.setRendering(rendering); err != nil {
+					log.Printf("setRendering: %v\n", err)
+				}
+			}
+			return
+		}
+
 		// Check for setSelectedSourceProfile (which profile this settings tile monitors)
 		if raw, ok := payload["setSelectedSourceProfile"]; ok {
 			var profileID string
@@ -663,6 +817,7 @@ func (p *Plugin) OnSendToPlugin(event *streamdeck.EvSendToPlugin) {
 				for i, sp := range profiles {
 					if sp.ID == profileID && sp.ID != p.globalSettings.DefaultSourceProfileID {
 						p.globalSettings.SourceProfiles = append(profiles[:i], profiles[i+1:]...)
+						withoutGroupMember(p.globalSettings.SourceProfiles, profileID)
 						break
 					}
 				}
@@ -672,9 +827,7 @@ func (p *Plugin) OnSendToPlugin(event *streamdeck.EvSendToPlugin) {
 				p.sourceMu.Lock()
 				if rt, exists := p.sources[profileID]; exists {
 					rt.mu.Lock()
-					if rt.c != nil {
-						rt.c.Kill()
-					}
+					stopSourceClientLocked(rt)
 					rt.mu.Unlock()
 					delete(p.sources, profileID)
 				}
@@ -687,7 +840,7 @@ func (p *Plugin) OnSendToPlugin(event *streamdeck.EvSendToPlugin) {
 			return
 		}
 
-		// Check for setSourceProfile (update name/host/port of a profile)
+		// Check for setSourceProfile (update a profile; masked secrets stay as stored)
 		if raw, ok := payload["setSourceProfile"]; ok {
 			var sp lhmSourceProfile
 			if err := json.Unmarshal(*raw, &sp); err == nil {
@@ -696,10 +849,25 @@ func (p *Plugin) OnSendToPlugin(event *streamdeck.EvSendToPlugin) {
 				for i := range p.globalSettings.SourceProfiles {
 					if p.globalSettings.SourceProfiles[i].ID == sp.ID {
 						old := p.globalSettings.SourceProfiles[i]
-						p.globalSettings.SourceProfiles[i].Name = sp.Name
-						p.globalSettings.SourceProfiles[i].Host = sp.Host
-						p.globalSettings.SourceProfiles[i].Port = sp.Port
-						changed = old.Host != sp.Host || old.Port != sp.Port
+						sp.Password = unmaskCredential(old.Password, sp.Password)
+						sp.Token = unmaskCredential(old.Token, sp.Token)
+						sp.Members = sanitizeGroupMembers(p.globalSettings.SourceProfiles, sp)
+						// Recording is for HTTP sources, the recording file for replay ones.
+						if sp.Type != "" {
+							sp.RecordDir = ""
+						}
+						if sp.Type != sourceTypeReplay {
+							sp.ReplayFile, sp.ReplaySpeed = "", 0
+						}
+						// Stat windows are tracked by the LHM service, which
+						// serves HTTP and replay sources only.
+						if sp.Type != "" && sp.Type != sourceTypeReplay {
+							sp.StatWindows = ""
+						} else if _, err := lhmplugin.ParseStatWindows(sp.StatWindows); err != nil {
+							sp.StatWindows = old.StatWindows
+						}
+						p.globalSettings.SourceProfiles[i] = sp
+						changed = !sameSourceProfileEndpoint(old, sp)
 						break
 					}
 				}
@@ -714,16 +882,12 @@ func (p *Plugin) OnSendToPlugin(event *streamdeck.EvSendToPlugin) {
 					p.sourceMu.RUnlock()
 					if rt != nil {
 						rt.mu.Lock()
-						rt.profile.Host = sp.Host
-						rt.profile.Port = sp.Port
-						if rt.c != nil {
-							rt.c.Kill()
-						}
-						rt.c = nil
-						rt.hw = nil
+						rt.profile = sp
+						stopSourceClientLocked(rt)
 						rt.mu.Unlock()
 						p.mu.Lock()
 						invalidatePollCacheForRuntime(rt)
+						rt.health = sourceHealth{}
 						p.mu.Unlock()
 						go p.startSourceClient(rt)
 					}
@@ -893,10 +1057,10 @@ func (p *Plugin) OnSendToPlugin(event *streamdeck.EvSendToPlugin) {
 			case "derived_unsuppressGlobal":
 				p.handleDerivedUnsuppressGlobal(event, &sdpi)
 			case "derived_formula", "derived_slotCount", "derived_format", "derived_divisor",
-				"derived_graphUnit", "derived_min", "derived_max",
+				"derived_graphUnit", "derived_displayUnit", "derived_min", "derived_max", "derived_autoScale", "derived_graphScale", "derived_logFloor", "derived_graphWindow",
 				"derived_foregroundColor", "derived_backgroundColor", "derived_highlightColor",
-				"derived_valueTextColor", "derived_titleColor", "derived_title",
-				"derived_graphHeightPct", "derived_graphLineThickness", "derived_textStroke", "derived_textStrokeColor",
+				"derived_valueTextColor", "derived_titleColor", "derived_title", "derived_graphGradient", "derived_graphStyle",
+				"derived_graphHeightPct", "derived_graphLineThickness", "derived_textStroke", "derived_textStrokeColor", "derived_showThresholdLines",
 				"derived_updateIntervalOverrideMs", "derived_smoothingAlpha", "titleFontSize", "valueFontSize":
 				p.handleDerivedGlobalField(event, &sdpi)
 			case "allSlots_sensorSelect":
@@ -930,7 +1094,7 @@ func (p *Plugin) OnSendToPlugin(event *streamdeck.EvSendToPlugin) {
 				return
 			}
 			switch sdpi.Key {
-			case "composite_mode", "composite_slotCount", "updateIntervalOverrideMs", "smoothingAlpha":
+			case "composite_mode", "composite_layout", "composite_slotCount", "composite_graphWindow", "updateIntervalOverrideMs", "smoothingAlpha":
 				p.handleCompositeGlobalField(event, &sdpi)
 			default:
 				slotIdx, field := parseCompositeSlotKey(sdpi.Key)
@@ -1034,6 +1198,16 @@ func (p *Plugin) OnSendToPlugin(event *streamdeck.EvSendToPlugin) {
 			if err != nil {
 				log.Println("handleSetGraphUnit", err)
 			}
+		case "displayUnit":
+			err := p.handleSetDisplayUnit(event, &sdpi)
+			if err != nil {
+				log.Println("handleSetDisplayUnit", err)
+			}
+		case "valueSource":
+			err := p.handleSetValueSource(event, &sdpi)
+			if err != nil {
+				log.Println("handleSetValueSource", err)
+			}
 		case "snoozeDurations":
 			err := p.handleSnoozeDurations(event, &sdpi)
 			if err != nil {
@@ -1061,7 +1235,7 @@ func (p *Plugin) OnSendToPlugin(event *streamdeck.EvSendToPlugin) {
 			if err != nil {
 				log.Println("handleSetTitleFontSize", err)
 			}
-		case "graphHeightPct", "graphLineThickness", "textStroke", "textStrokeColor", "updateIntervalOverrideMs", "smoothingAlpha":
+		case "graphHeightPct", "graphLineThickness", "textStroke", "textStrokeColor", "updateIntervalOverrideMs", "smoothingAlpha", "autoScale", "graphScale", "logFloor", "graphWindow", "graphGradient", "graphStyle", "showThresholdLines":
 			err := p.handleGraphVisuals(event, &sdpi)
 			if err != nil {
 				log.Println("handleGraphVisuals", err)
@@ -1247,7 +1421,7 @@ func (p *Plugin) OnDidReceiveGlobalSettings(event *streamdeck.EvDidReceiveGlobal
 	for _, newProf := range gs.SourceProfiles {
 		for _, oldProf := range p.globalSettings.SourceProfiles {
 			if oldProf.ID == newProf.ID {
-				if oldProf.Host != newProf.Host || oldProf.Port != newProf.Port {
+				if !sameSourceProfileEndpoint(oldProf, newProf) {
 					p.sourceMu.RLock()
 					rt := p.sources[newProf.ID]
 					p.sourceMu.RUnlock()
@@ -1267,6 +1441,7 @@ func (p *Plugin) OnDidReceiveGlobalSettings(event *streamdeck.EvDidReceiveGlobal
 	}
 	for _, d := range endpointChanges {
 		invalidatePollCacheForRuntime(d.rt)
+		d.rt.health = sourceHealth{}
 	}
 	p.mu.Unlock()
 
@@ -1279,6 +1454,7 @@ func (p *Plugin) OnDidReceiveGlobalSettings(event *streamdeck.EvDidReceiveGlobal
 	if intervalChanged {
 		interval := time.Duration(gs.PollInterval) * time.Millisecond
 		p.am.SetInterval(interval)
+		p.resubscribeSources()
 	}
 
 	// Restart bridges whose endpoint changed.
@@ -1286,11 +1462,7 @@ func (p *Plugin) OnDidReceiveGlobalSettings(event *streamdeck.EvDidReceiveGlobal
 		rt := d.rt
 		rt.mu.Lock()
 		rt.profile = d.profile
-		if rt.c != nil {
-			rt.c.Kill()
-		}
-		rt.c = nil
-		rt.hw = nil
+		stopSourceClientLocked(rt)
 		rt.mu.Unlock()
 		log.Printf("LHM endpoint changed for source %s, restarting bridge\n", d.profile.ID)
 		go p.startSourceClient(rt)
@@ -1316,5 +1488,8 @@ func (p *Plugin) OnDidReceiveGlobalSettings(event *streamdeck.EvDidReceiveGlobal
 		}
 	}
 
+	p.applyPNGCompression()
+	p.applyHistoryMaxAge()
+	p.applyRendering()
 	p.updateAllSettingsTiles()
 }
@@ -4,11 +4,13 @@ import (
 	"encoding/json"
 	"log"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/moeilijk/lhm-streamdeck/pkg/graph"
 	hwsensorsservice "github.com/moeilijk/lhm-streamdeck/pkg/service"
 	"github.com/moeilijk/lhm-streamdeck/pkg/streamdeck"
+	"github.com/moeilijk/lhm-streamdeck/pkg/units"
 )
 
 const derivedAction = "com.moeilijk.lhm.derived"
@@ -37,6 +39,7 @@ func decodeDerivedSettings(raw *json.RawMessage) (derivedActionSettings, error)
 	if s.Formula == "" {
 		s.Formula = "sum"
 	}
+	migrateSymmetricAutoScale(&s.AutoScale, &s.GraphScale)
 	if s.ForegroundColor == "" {
 		s.ForegroundColor = "#005128"
 	}
@@ -67,14 +70,18 @@ func decodeDerivedSettings(raw *json.RawMessage) (derivedActionSettings, error)
 	return s, nil
 }
 
-// initDerivedGraph creates a graph.Graph using the tile-level color settings.
-func initDerivedGraph(s *derivedActionSettings) *graph.Graph {
+// initDerivedGraph creates a graph.Graph using the tile-level color settings,
+// drawn at pixelRatio when smooth.
+func initDerivedGraph(s *derivedActionSettings, pixelRatio int) *graph.Graph {
 	fg := hexToRGBA(s.ForegroundColor)
 	bg := hexToRGBA(s.BackgroundColor)
 	hl := hexToRGBA(s.HighlightColor)
 	tc := hexToRGBA(s.TitleColor)
 	vc := hexToRGBA(s.ValueTextColor)
 	g := graph.NewGraph(tileWidth, tileHeight, s.Min, s.Max, fg, bg, hl)
+	g.SetPixelRatio(pixelRatio)
+	g.SetGradient(parseGradient(s.GraphGradient))
+	g.SetStyle(graphStyle(s.GraphStyle))
 	tfSize := s.TitleFontSize
 	if tfSize == 0 {
 		tfSize = 10.5
@@ -89,6 +96,10 @@ func initDerivedGraph(s *derivedActionSettings) *graph.Graph {
 	g.SetLabelFontSize(1, vfSize)
 	g.SetLabel(2, "", 56, vc)
 	g.SetLabelFontSize(2, vfSize)
+	g.SetScale(graphScale(s.GraphScale))
+	g.SetLogFloor(s.LogFloor)
+	g.SetAutoScale(graphAutoScale(s.AutoScale))
+	g.SetTimeWindow(graphTimeWindow(s.GraphWindow))
 	if s.GraphHeightPct > 0 {
 		g.SetHeightPct(s.GraphHeightPct)
 	}
@@ -102,6 +113,29 @@ func initDerivedGraph(s *derivedActionSettings) *graph.Graph {
 	return g
 }
 
+// restoreDerivedHistory refills the graph and smoothing of a new state from
+// the saved history and shows the result.
+func (p *Plugin) restoreDerivedHistory(ctx string, settings *derivedActionSettings, state *derivedState) {
+	e, ok := p.restoreGraphHistory(ctx, derivedHistoryKey(settings), state.graph)
+	if !ok {
+		return
+	}
+	if e.Smoothed != nil {
+		state.smoothedValue = *e.Smoothed
+		state.smoothedInit = true
+	}
+	g := state.graph
+	if err := g.SetLabelText(0, p.derivedLabelText(settings)); err != nil {
+		log.Printf("derived SetLabelText(0): %v", err)
+	}
+	if err := g.SetLabelText(1, e.Text); err != nil {
+		log.Printf("derived SetLabelText(1): %v", err)
+	}
+	if err := p.setImage(ctx, g.Image()); err != nil {
+		log.Printf("derived SetImage: %v", err)
+	}
+}
+
 // computeDerived applies the formula to the collected slot values.
 // Returns (result, ok). ok=false if the input is invalid for the formula.
 func computeDerived(formula string, values []float64) (float64, bool) {
@@ -180,7 +214,7 @@ func (p *Plugin) updateDerivedTile(ctx string) {
 
 	profileID := p.resolvedSourceProfileID(settings.SourceProfileID)
 	pollTime, err := p.getCachedPollTimeForSource(profileID)
-	if err != nil || pollTime == 0 || time.Since(time.Unix(0, int64(pollTime))) > 5*time.Second {
+	if err != nil || p.pollTimeStale(pollTime) {
 		return
 	}
 	if pollTime == state.lastPollTime {
@@ -199,21 +233,32 @@ func (p *Plugin) updateDerivedTile(ctx string) {
 	var values []float64
 	var displayUnit string
 	var readingType hwsensorsservice.ReadingType
+	temperatureUnit := p.temperatureUnit()
+	view, err := p.viewForSource(profileID)
+	if err != nil {
+		return
+	}
 
 	for i := 0; i < settings.SlotCount; i++ {
 		slot := &settings.Slots[i]
 		if !slot.IsValid || slot.SensorUID == "" {
 			continue
 		}
-		r, _, err := p.getReadingForSource(profileID, slot.SensorUID, slot.ReadingID)
+		r, err := view.reading(slot.SensorUID, slot.ReadingID)
 		if err != nil {
 			continue
 		}
 		v := r.Value()
+		slotUnit := r.Unit()
 		if slot.GraphUnit != "" {
-			v = p.normalizeForGraph(v, r.Unit(), slot.GraphUnit)
+			v = graphUnitValue(v, r.Unit(), slot.GraphUnit)
+			slotUnit = slot.GraphUnit
+			if strings.Contains(r.Unit(), "/s") {
+				slotUnit += "/s"
+			}
 		}
-		// Per-slot divisor
+		// Per-slot divisor, applied before the unit conversion as on reading
+		// tiles so offset conversions (°F) agree with them.
 		p.mu.RLock()
 		st, hasSt := p.derivedStates[ctx]
 		p.mu.RUnlock()
@@ -233,12 +278,20 @@ func (p *Plugin) updateDerivedTile(ctx string) {
 				v = v / cache.value
 			}
 		}
+		// Slots are converted before aggregating so that differences between
+		// temperatures come out right in °F or K too.
+		if du := effectiveDisplayUnit(slotUnit, settings.DisplayUnit, temperatureUnit); du != "" {
+			if unit := resolveTileUnit(slotUnit, du, ""); unit.to.ID == du {
+				v = unit.convert(v)
+				slotUnit = unit.symbol
+			}
+		}
 		values = append(values, v)
 		if displayUnit == "" {
-			if slot.GraphUnit != "" {
+			if slot.GraphUnit != "" && settings.DisplayUnit == "" {
 				displayUnit = slot.GraphUnit
 			} else {
-				displayUnit = r.Unit()
+				displayUnit = slotUnit
 			}
 			readingType = hwsensorsservice.ReadingType(r.TypeI())
 		}
@@ -276,7 +329,7 @@ func (p *Plugin) updateDerivedTile(ctx string) {
 		}
 	}
 
-	if settings.GraphUnit != "" {
+	if settings.GraphUnit != "" && settings.DisplayUnit == "" {
 		displayUnit = settings.GraphUnit
 	}
 	if settings.Formula == "pct" {
@@ -285,6 +338,7 @@ func (p *Plugin) updateDerivedTile(ctx string) {
 
 	// EMA smoothing — threshold eval uses raw aggregated; smoothing applies to display and graph
 	smoothedAggregated := aggregated
+	var ema *float64
 	if alpha := settings.SmoothingAlpha; alpha > 0 && alpha < 1.0 {
 		p.mu.Lock()
 		if !state.smoothedInit {
@@ -294,14 +348,36 @@ func (p *Plugin) updateDerivedTile(ctx string) {
 		smoothedAggregated = alpha*aggregated + (1-alpha)*state.smoothedValue
 		state.smoothedValue = smoothedAggregated
 		p.mu.Unlock()
+		ema = &smoothedAggregated
 	}
 
 	valueTextNoUnit, displayText := p.formatDisplayValue(smoothedAggregated, displayUnit, settings.Format, readingType)
 
 	now := time.Now()
+	// The aggregate is already in the tile's unit; a temperature difference
+	// ("delta") converts its thresholds as an interval, not a reading.
+	tempUnit := units.Parse(displayUnit)
+	isTemperature := readingType == hwsensorsservice.ReadingTypeTemp && tempUnit.Dim == units.Temperature
+	delta := settings.Formula == "delta"
+	if isTemperature {
+		p.mu.Lock()
+		migrated, ok := convertThresholds(settings.Thresholds, tempUnit, delta)
+		if ok {
+			settings.Thresholds = migrated
+		}
+		p.mu.Unlock()
+		if ok {
+			if err := p.sd.SetSettings(ctx, settings); err != nil {
+				log.Printf("derived migrate thresholds SetSettings: %v", err)
+			}
+		}
+	}
 	p.mu.RLock()
 	derivedThresholds := p.resolveThresholdsForEval(settings.Thresholds, settings.SuppressedGlobalIDs, readingType)
 	p.mu.RUnlock()
+	if isTemperature {
+		derivedThresholds, _ = convertThresholds(derivedThresholds, tempUnit, delta)
+	}
 	activeThreshold := p.evaluateThresholds(ctx, aggregated, derivedThresholds, now)
 
 	newThresholdID := ""
@@ -337,6 +413,7 @@ func (p *Plugin) updateDerivedTile(ctx string) {
 			HighlightColor:  settings.HighlightColor,
 			ValueTextColor:  settings.ValueTextColor,
 			TitleColor:      settings.TitleColor,
+			GraphGradient:   settings.GraphGradient,
 			Min:             settings.Min,
 			Max:             settings.Max,
 		}
@@ -364,6 +441,11 @@ func (p *Plugin) updateDerivedTile(ctx string) {
 	if !freezeGraph {
 		g.Update(renderGraphValue)
 	}
+	if settings.ShowThresholdLines {
+		g.SetMarkers(thresholdMarkers(derivedThresholds, nil, g.HighlightColor()))
+	} else {
+		g.SetMarkers(nil)
+	}
 
 	if err := g.SetLabelText(0, p.derivedLabelText(settings)); err != nil {
 		log.Printf("derived SetLabelText(0): %v", err)
@@ -380,13 +462,13 @@ func (p *Plugin) updateDerivedTile(ctx string) {
 			log.Printf("derived SetLabelText(2): %v", err)
 		}
 	}
-
-	b, err := g.EncodePNG()
-	if err != nil {
-		log.Printf("derived EncodePNG: %v", err)
-		return
+	var sample *float64
+	if !freezeGraph {
+		sample = &renderGraphValue
 	}
-	if err := p.sd.SetImage(ctx, b); err != nil {
+	p.history.record(ctx, derivedHistoryKey(settings), now, g.TimeWindow(), sample, ema, "", renderDisplayText)
+
+	if err := p.setImage(ctx, g.Image()); err != nil {
 		log.Printf("derived SetImage: %v", err)
 		return
 	}
@@ -401,7 +483,7 @@ func (p *Plugin) updateDerivedTile(ctx string) {
 	p.mu.Unlock()
 }
 
-func (p *Plugin) updateDerivedTick() {
+func (p *Plugin) updateDerivedTick(tick *renderTick) {
 	p.mu.RLock()
 	contexts := make([]string, 0, len(p.derivedSettings))
 	for ctx := range p.derivedSettings {
@@ -409,7 +491,7 @@ func (p *Plugin) updateDerivedTick() {
 	}
 	p.mu.RUnlock()
 	for _, ctx := range contexts {
-		p.updateDerivedTile(ctx)
+		tick.render(ctx, func() { p.updateDerivedTile(ctx) })
 	}
 }
 
@@ -423,7 +505,7 @@ func (p *Plugin) handleDerivedTitleParametersDidChange(event *streamdeck.EvTitle
 	}
 	state, ok := p.derivedStates[event.Context]
 	if !ok {
-		state = &derivedState{graph: initDerivedGraph(settings)}
+		state = &derivedState{graph: initDerivedGraph(settings, p.pixelRatioLocked(event.Context))}
 		p.derivedStates[event.Context] = state
 	}
 
@@ -641,6 +723,8 @@ func (p *Plugin) handleDerivedGlobalField(event *streamdeck.EvSendToPlugin, sdpi
 		settings.Divisor = sdpi.Value
 	case "derived_graphUnit":
 		settings.GraphUnit = sdpi.Value
+	case "derived_displayUnit":
+		settings.DisplayUnit = sdpi.Value
 	case "derived_min":
 		if v, err := strconv.Atoi(sdpi.Value); err == nil {
 			settings.Min = v
@@ -655,8 +739,40 @@ func (p *Plugin) handleDerivedGlobalField(event *streamdeck.EvSendToPlugin, sdpi
 				state.graph.SetMax(v)
 			}
 		}
+	case "derived_autoScale":
+		settings.AutoScale = sdpi.Value
+		if state != nil && state.graph != nil {
+			state.graph.SetAutoScale(graphAutoScale(sdpi.Value))
+		}
+	case "derived_graphScale":
+		settings.GraphScale = sdpi.Value
+		if state != nil && state.graph != nil {
+			state.graph.SetScale(graphScale(sdpi.Value))
+		}
+	case "derived_logFloor":
+		if v, err := strconv.ParseFloat(sdpi.Value, 64); err == nil && v >= 0 {
+			settings.LogFloor = v
+			if state != nil && state.graph != nil {
+				state.graph.SetLogFloor(v)
+			}
+		}
+	case "derived_graphWindow":
+		settings.GraphWindow = sdpi.Value
+		if state != nil && state.graph != nil {
+			state.graph.SetTimeWindow(graphTimeWindow(sdpi.Value))
+		}
 	case "derived_foregroundColor":
 		settings.ForegroundColor = sdpi.Value
+	case "derived_graphStyle":
+		settings.GraphStyle = sdpi.Value
+		if state != nil && state.graph != nil {
+			state.graph.SetStyle(graphStyle(sdpi.Value))
+		}
+	case "derived_graphGradient":
+		settings.GraphGradient = sdpi.Value
+		if state != nil && state.graph != nil {
+			state.graph.SetGradient(parseGradient(sdpi.Value))
+		}
 	case "derived_backgroundColor":
 		settings.BackgroundColor = sdpi.Value
 	case "derived_highlightColor":
@@ -720,6 +836,8 @@ func (p *Plugin) handleDerivedGlobalField(event *streamdeck.EvSendToPlugin, sdpi
 		if state != nil && state.graph != nil {
 			state.graph.SetTextStroke(sdpi.Checked)
 		}
+	case "derived_showThresholdLines":
+		settings.ShowThresholdLines = sdpi.Checked
 	case "derived_textStrokeColor":
 		settings.TextStrokeColor = sdpi.Value
 		if state != nil && state.graph != nil {
@@ -840,5 +958,5 @@ func (p *Plugin) rebuildDerivedGraph(ctx string) {
 	if !ok1 || !ok2 {
 		return
 	}
-	state.graph = initDerivedGraph(settings)
+	state.graph = initDerivedGraph(settings, p.pixelRatioLocked(ctx))
 }
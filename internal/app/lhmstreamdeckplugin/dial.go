@@ -1,14 +1,12 @@
 package lhmstreamdeckplugin
 
 import (
-	"bytes"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"image"
 	"image/color"
 	imagedraw "image/draw"
-	"image/png"
 	"log"
 	"strings"
 	"time"
@@ -29,11 +27,12 @@ const (
 
 type dialState struct {
 	graphs   []*graph.Graph
+	mirrors  []*graph.Graph // per page: the lower graph of a mirrored page, else nil
 	overview bool
 }
 
 type dialPageRender struct {
-	image        []byte
+	image        *image.RGBA
 	messageTitle string
 	messageValue string
 	// bringToFront is set on the tick a page's threshold becomes active (and is
@@ -78,6 +77,9 @@ func decodeDialSettings(raw *json.RawMessage) (dialActionSettings, error) {
 	if len(s.Pages) > 0 {
 		s.ActiveIndex %= len(s.Pages)
 	}
+	for i := range s.Pages {
+		migrateSymmetricAutoScale(&s.Pages[i].AutoScale, &s.Pages[i].GraphScale)
+	}
 	return s, nil
 }
 
@@ -120,9 +122,16 @@ func dialColor(hex string, def color.RGBA) *color.RGBA {
 // settings. Keeping the graph object lets its plotted history survive page or
 // style edits; only a reading change rebuilds it (see buildDialGraphs).
 func applyDialGraphSettings(g *graph.Graph, s *actionSettings) {
+	g.SetHeight(dialGraphHeight(s))
+	setDialLabelRows(g, s, false)
 	minValue, maxValue := dialGraphScale(s)
 	g.SetMin(minValue)
 	g.SetMax(maxValue)
+	g.SetScale(graphScale(s.GraphScale))
+	g.SetLogFloor(s.LogFloor)
+	g.SetAutoScale(graphAutoScale(s.AutoScale))
+	g.SetTimeWindow(graphTimeWindow(s.GraphWindow))
+	g.SetStyle(graphStyle(s.GraphStyle))
 	g.SetForegroundColor(dialColor(s.ForegroundColor, color.RGBA{0, 81, 40, 255}))
 	g.SetBackgroundColor(dialColor(s.BackgroundColor, color.RGBA{0, 0, 0, 255}))
 	g.SetHighlightColor(dialColor(s.HighlightColor, color.RGBA{0, 158, 0, 255}))
@@ -144,6 +153,55 @@ func applyDialGraphSettings(g *graph.Graph, s *actionSettings) {
 	}
 }
 
+// Default colors of the lower graph of a mirrored page.
+var (
+	dialMirrorForeground = color.RGBA{0, 63, 115, 255}
+	dialMirrorHighlight  = color.RGBA{0, 162, 255, 255}
+)
+
+// dialMirrored reports whether a page draws a second reading downward from a
+// centre line under its own.
+func dialMirrored(s *actionSettings) bool {
+	return s.MirrorReadingID != 0
+}
+
+// dialGraphHeight is the canvas height of a page graph; the two graphs of a
+// mirrored page get half the dial each.
+func dialGraphHeight(s *actionSettings) int {
+	if dialMirrored(s) {
+		return dialHeight / 2
+	}
+	return dialHeight
+}
+
+// setDialLabelRows places the title, value and alert labels of a page graph.
+// Each half of a mirrored page has its title on the outer edge and its value
+// next to the centre line; the alert shares the upper title's row.
+func setDialLabelRows(g *graph.Graph, s *actionSettings, lower bool) {
+	rows := [3]uint{24, 58, 82}
+	switch {
+	case lower:
+		rows = [3]uint{30, 4, 30}
+	case dialMirrored(s):
+		rows = [3]uint{8, 30, 12}
+	}
+	for key, y := range rows {
+		_ = g.SetLabelY(key, y)
+	}
+}
+
+// applyDialMirrorSettings updates the lower graph of a mirrored page: the
+// page's settings in the mirror colors.
+func applyDialMirrorSettings(g *graph.Graph, s *actionSettings) {
+	applyDialGraphSettings(g, s)
+	g.SetForegroundColor(dialColor(s.MirrorForegroundColor, dialMirrorForeground))
+	g.SetHighlightColor(dialColor(s.MirrorHighlightColor, dialMirrorHighlight))
+	if s.TextStrokeColor != "" {
+		g.SetTextStrokeColor(hexToRGBA(s.TextStrokeColor))
+	}
+	setDialLabelRows(g, s, true)
+}
+
 func newDialGraph(s *actionSettings) *graph.Graph {
 	minValue, maxValue := dialGraphScale(s)
 	g := graph.NewGraph(dialWidth, dialHeight, minValue, maxValue,
@@ -157,6 +215,17 @@ func newDialGraph(s *actionSettings) *graph.Graph {
 	return g
 }
 
+// newDialMirrorGraph builds the lower graph of a page, or nil when the page
+// is not mirrored.
+func newDialMirrorGraph(s *actionSettings) *graph.Graph {
+	if !dialMirrored(s) {
+		return nil
+	}
+	g := newDialGraph(s)
+	applyDialMirrorSettings(g, s)
+	return g
+}
+
 // dialPageSameReading reports whether two pages plot the same data series, so a
 // graph (and its history) can be reused across a settings save.
 func dialPageSameReading(a, b *actionSettings) bool {
@@ -184,6 +253,58 @@ func buildDialGraphs(oldSettings *dialActionSettings, oldState *dialState, s *di
 	return graphs
 }
 
+// buildDialMirrors is buildDialGraphs for the lower graphs of mirrored pages:
+// one is kept while its page still mirrors the same reading.
+func buildDialMirrors(oldSettings *dialActionSettings, oldState *dialState, s *dialActionSettings) []*graph.Graph {
+	mirrors := make([]*graph.Graph, len(s.Pages))
+	for i := range s.Pages {
+		page := &s.Pages[i]
+		reuse := oldState != nil && i < len(oldState.mirrors) && oldState.mirrors[i] != nil &&
+			oldSettings != nil && i < len(oldSettings.Pages) && dialMirrored(page) &&
+			oldSettings.Pages[i].SensorUID == page.SensorUID &&
+			oldSettings.Pages[i].MirrorReadingID == page.MirrorReadingID
+		if reuse {
+			mirrors[i] = oldState.mirrors[i]
+			applyDialMirrorSettings(mirrors[i], page)
+		} else {
+			mirrors[i] = newDialMirrorGraph(page)
+		}
+	}
+	return mirrors
+}
+
+// linkDialGraphs lets both halves of each mirrored page auto scale together.
+func linkDialGraphs(state *dialState) {
+	for i, g := range state.graphs {
+		if g == nil {
+			continue
+		}
+		if i < len(state.mirrors) && state.mirrors[i] != nil {
+			graph.ShareScale(g, state.mirrors[i])
+		} else {
+			graph.ShareScale(g)
+		}
+	}
+}
+
+// dialPageImage renders a page graph, with its lower half when mirrored.
+func dialPageImage(g, mirror *graph.Graph) *image.RGBA {
+	if mirror == nil {
+		return g.Image()
+	}
+	canvas := image.NewRGBA(image.Rect(0, 0, dialWidth, dialHeight))
+	graph.DrawMirrored(canvas, g, mirror)
+	return canvas
+}
+
+// mirrorAt returns the lower graph of page i, or nil.
+func (s *dialState) mirrorAt(i int) *graph.Graph {
+	if i < 0 || i >= len(s.mirrors) {
+		return nil
+	}
+	return s.mirrors[i]
+}
+
 func defaultDialTitleFontSize(v float64) float64 {
 	if v > 0 {
 		return v
@@ -199,10 +320,15 @@ func defaultDialValueFontSize(v float64) float64 {
 }
 
 func initDialState(s *dialActionSettings) *dialState {
-	state := &dialState{graphs: make([]*graph.Graph, len(s.Pages))}
+	state := &dialState{
+		graphs:  make([]*graph.Graph, len(s.Pages)),
+		mirrors: make([]*graph.Graph, len(s.Pages)),
+	}
 	for i := range s.Pages {
 		state.graphs[i] = newDialGraph(&s.Pages[i])
+		state.mirrors[i] = newDialMirrorGraph(&s.Pages[i])
 	}
+	linkDialGraphs(state)
 	return state
 }
 
@@ -259,17 +385,29 @@ func dialOverviewIndices(active, count, limit int) []int {
 }
 
 func pngDataURL(b []byte) string {
-	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(b)
+	return pngDataURLPrefix + base64.StdEncoding.EncodeToString(b)
 }
 
-func (p *Plugin) sendDialCanvas(ctx string, b []byte) {
+func (p *Plugin) sendDialCanvas(ctx string, img *image.RGBA) {
+	if !p.frames.changed(ctx, img) {
+		return
+	}
+	b, err := graph.EncodeImage(img)
+	if err != nil {
+		p.frames.forget(ctx)
+		log.Printf("dial encode: %v", err)
+		return
+	}
 	payload := map[string]interface{}{
 		"full-canvas": pngDataURL(b),
 		"title":       "",
 	}
 	if err := p.sd.SetFeedback(ctx, payload); err != nil {
+		p.frames.forget(ctx)
 		log.Printf("dial setFeedback: %v", err)
+		return
 	}
+	p.frames.sent(len(b))
 }
 
 func (p *Plugin) showDialMessage(ctx, title, value string) {
@@ -287,12 +425,7 @@ func (p *Plugin) showDialMessage(ctx, title, value string) {
 	_ = g.SetLabelText(0, title)
 	_ = g.SetLabelText(1, value)
 	g.Update(0)
-	b, err := g.EncodePNG()
-	if err != nil {
-		log.Printf("dial message encode: %v", err)
-		return
-	}
-	p.sendDialCanvas(ctx, b)
+	p.sendDialCanvas(ctx, g.Image())
 }
 
 func fillRect(img *image.RGBA, r image.Rectangle, c color.Color) {
@@ -548,13 +681,9 @@ func dialOverviewRects(count int) []image.Rectangle {
 	}
 }
 
-func decorateDialImage(b []byte, active, count int, showIndicator bool, indicatorStyle string, sepWidth int, sepColor color.RGBA, indicatorColor color.RGBA, indicatorSize float64) ([]byte, error) {
-	src, err := png.Decode(bytes.NewReader(b))
-	if err != nil {
-		return nil, err
-	}
-	canvas := image.NewRGBA(src.Bounds())
-	imagedraw.Draw(canvas, canvas.Bounds(), src, image.Point{}, imagedraw.Src)
+// decorateDialImage draws the edge separators and, when showIndicator is set,
+// the page indicator over a fullscreen page image in place.
+func decorateDialImage(canvas *image.RGBA, active, count int, showIndicator bool, indicatorStyle string, sepWidth int, sepColor color.RGBA, indicatorColor color.RGBA, indicatorSize float64) {
 	drawDialEdgeSeparators(canvas, sepWidth, sepColor)
 	if showIndicator {
 		// Draw the page indicator in the LEFT column (vertically), matching the
@@ -563,20 +692,15 @@ func decorateDialImage(b []byte, active, count int, showIndicator bool, indicato
 		// bottom-aligned horizontal indicator sat right on top of the graph fill.
 		drawDialVerticalPageIndicator(canvas, active, count, indicatorStyle, indicatorColor, indicatorSize)
 	}
-	var out bytes.Buffer
-	if err := png.Encode(&out, canvas); err != nil {
-		return nil, err
-	}
-	return out.Bytes(), nil
 }
 
-func (p *Plugin) renderDialOverview(settings *dialActionSettings, state *dialState) ([]byte, error) {
+func (p *Plugin) renderDialOverview(settings *dialActionSettings, state *dialState) *image.RGBA {
 	canvas := image.NewRGBA(image.Rect(0, 0, dialWidth, dialHeight))
 	fillRect(canvas, canvas.Bounds(), color.RGBA{5, 8, 11, 255})
 
 	indices := dialOverviewIndices(settings.ActiveIndex, len(settings.Pages), dialOverviewCap(settings))
 	if len(indices) == 0 {
-		return nil, nil
+		return nil
 	}
 
 	rects := dialOverviewRects(len(indices))
@@ -587,14 +711,7 @@ func (p *Plugin) renderDialOverview(settings *dialActionSettings, state *dialSta
 		}
 		card := rects[slot]
 		fillRect(canvas, card, color.RGBA{14, 18, 24, 255})
-		b, err := state.graphs[pageIndex].EncodePNG()
-		if err != nil {
-			return nil, err
-		}
-		src, err := png.Decode(bytes.NewReader(b))
-		if err != nil {
-			return nil, err
-		}
+		src := dialPageImage(state.graphs[pageIndex], state.mirrorAt(pageIndex))
 		inner := card.Inset(3)
 		xdraw.CatmullRom.Scale(canvas, inner, src, centeredAspectCrop(src.Bounds(), inner), xdraw.Over, nil)
 		if pageIndex == settings.ActiveIndex {
@@ -607,11 +724,7 @@ func (p *Plugin) renderDialOverview(settings *dialActionSettings, state *dialSta
 	drawDialEdgeSeparators(canvas, dialSeparatorWidth(settings), dialSeparatorColor(settings))
 	drawDialPageIndicator(canvas, settings.ActiveIndex, len(settings.Pages), dialIndicatorStyle(settings), dialIndicatorColor(settings), dialIndicatorSize(settings))
 
-	var buf bytes.Buffer
-	if err := png.Encode(&buf, canvas); err != nil {
-		return nil, err
-	}
-	return buf.Bytes(), nil
+	return canvas
 }
 
 // dialStackedLeftColumn is the default width reserved on the left of the stacked
@@ -858,11 +971,26 @@ func setDialPixel(img *image.RGBA, x, y int, c color.RGBA) {
 	}
 }
 
-// drawDialSparkline plots a graph's history as a filled area chart that fills the
+// drawDialSparkline plots a graph's history in the graph's style across the
 // whole rect, mapping the most recent sample to the right edge so the graph
 // visibly builds rightward. The series is scaled to the rect height natively, so
 // the data is never distorted by cropping or stretching a pre-rendered tile.
 func drawDialSparkline(img *image.RGBA, rect image.Rectangle, g *graph.Graph) {
+	plotDialSparkline(img, rect, g, false)
+}
+
+// plotDialSparkline is drawDialSparkline, growing down from the top of rect
+// instead of up from its bottom when down is set. Smooth rendering draws it
+// anti-aliased like the key images.
+func plotDialSparkline(img *image.RGBA, rect image.Rectangle, g *graph.Graph, down bool) {
+	if graph.CurrentRendering() == graph.RenderingSmooth {
+		g.PaintSparkline(img, rect, down)
+		return
+	}
+	row := func(y int) int { return rect.Max.Y - 1 - y }
+	if down {
+		row = func(y int) int { return rect.Min.Y + y }
+	}
 	series := g.Series()
 	if len(series) == 0 {
 		return
@@ -877,7 +1005,9 @@ func drawDialSparkline(img *image.RGBA, rect image.Rectangle, g *graph.Graph) {
 	if w <= 0 || h <= 0 {
 		return
 	}
+	style := g.Style()
 	n := len(series)
+	prevY := -1
 	for col := 0; col < w; col++ {
 		idx := n - 1 - (w - 1 - col)
 		if idx < 0 {
@@ -890,15 +1020,26 @@ func drawDialSparkline(img *image.RGBA, rect image.Rectangle, g *graph.Graph) {
 			frac = 1
 		}
 		fillH := int(frac*float64(h) + 0.5)
+		if fillH > h-1 {
+			fillH = h - 1
+		}
 		x := rect.Min.X + col
-		lineY := rect.Max.Y - 1 - fillH
-		if lineY < rect.Min.Y {
-			lineY = rect.Min.Y
+		if style.Fills() {
+			for y := 0; y < fillH; y++ {
+				setDialPixel(img, x, row(y), fg)
+			}
 		}
-		for y := rect.Max.Y - 1; y > lineY; y-- {
-			setDialPixel(img, x, y, fg)
+		if prevY >= 0 {
+			lo, hi, prevLo, prevHi := style.Connector(prevY, fillH)
+			for y := lo; y <= hi; y++ {
+				setDialPixel(img, x, row(y), hl)
+			}
+			for y := prevLo; y <= prevHi; y++ {
+				setDialPixel(img, x-1, row(y), hl)
+			}
 		}
-		setDialPixel(img, x, lineY, hl)
+		setDialPixel(img, x, row(fillH), hl)
+		prevY = fillH
 	}
 }
 
@@ -932,11 +1073,18 @@ func drawDialStripText(img *image.RGBA, rect image.Rectangle, text string, size
 // building) with the page title and current value drawn over the left, outlined
 // for legibility. All strips are equal height and show the title and value; the
 // active strip gets a bright border while the neighbours are lightly dimmed. No
-// scaling or cropping is used, so the graph is never distorted.
-func drawDialStrip(canvas *image.RGBA, rect image.Rectangle, g *graph.Graph, active bool) {
+// scaling or cropping is used, so the graph is never distorted. A mirrored
+// page splits the strip around its centre and shows both values.
+func drawDialStrip(canvas *image.RGBA, rect image.Rectangle, g, mirror *graph.Graph, active bool) {
 	fillRect(canvas, rect, color.RGBA{14, 18, 24, 255})
 	inner := rect.Inset(1)
-	drawDialSparkline(canvas, inner, g)
+	if mirror != nil {
+		mid := inner.Min.Y + inner.Dy()/2
+		drawDialSparkline(canvas, image.Rect(inner.Min.X, inner.Min.Y, inner.Max.X, mid), g)
+		plotDialSparkline(canvas, image.Rect(inner.Min.X, mid, inner.Max.X, inner.Max.Y), mirror, true)
+	} else {
+		drawDialSparkline(canvas, inner, g)
+	}
 
 	if !active {
 		// Lightly dim the neighbouring strips so the active reading stays the focus.
@@ -945,6 +1093,9 @@ func drawDialStrip(canvas *image.RGBA, rect image.Rectangle, g *graph.Graph, act
 
 	title := strings.TrimSpace(g.LabelText(0))
 	value := strings.TrimSpace(g.LabelText(1))
+	if mirror != nil {
+		value += " / " + strings.TrimSpace(mirror.LabelText(1))
+	}
 	stroke := color.RGBA{0, 0, 0, 220}
 	titleColor := color.RGBA{200, 206, 214, 255}
 	if c, ok := g.LabelColor(0); ok {
@@ -968,13 +1119,13 @@ func drawDialStrip(canvas *image.RGBA, rect image.Rectangle, g *graph.Graph, act
 // strips with the active reading dominant in the centre and a dimmed peek of the
 // previous/next page above and below, plus the vertical page indicator on the
 // left.
-func (p *Plugin) renderDialStacked(settings *dialActionSettings, state *dialState) ([]byte, error) {
+func (p *Plugin) renderDialStacked(settings *dialActionSettings, state *dialState) *image.RGBA {
 	canvas := image.NewRGBA(image.Rect(0, 0, dialWidth, dialHeight))
 	fillRect(canvas, canvas.Bounds(), color.RGBA{5, 8, 11, 255})
 
 	count := len(settings.Pages)
 	if count == 0 {
-		return nil, nil
+		return nil
 	}
 	indices, activeSlot, rects := dialStackedLayout(settings.ActiveIndex, count, dialStackedGutter(dialIndicatorSize(settings)), dialOverviewCap(settings))
 
@@ -982,22 +1133,65 @@ func (p *Plugin) renderDialStacked(settings *dialActionSettings, state *dialStat
 		if pageIndex < 0 || pageIndex >= len(state.graphs) || state.graphs[pageIndex] == nil {
 			continue
 		}
-		drawDialStrip(canvas, rects[slot], state.graphs[pageIndex], slot == activeSlot)
+		drawDialStrip(canvas, rects[slot], state.graphs[pageIndex], state.mirrorAt(pageIndex), slot == activeSlot)
 	}
 
 	drawDialVerticalPageIndicator(canvas, settings.ActiveIndex, count, dialIndicatorStyle(settings), dialIndicatorColor(settings), dialIndicatorSize(settings))
 
-	var buf bytes.Buffer
-	if err := png.Encode(&buf, canvas); err != nil {
-		return nil, err
-	}
-	return buf.Bytes(), nil
+	return canvas
 }
 
 func dialPageContext(ctx string, index int) string {
 	return fmt.Sprintf("%s|dial|page|%d", ctx, index)
 }
 
+// dialValue is a reading of a dial page converted for display.
+type dialValue struct {
+	raw     float64 // divided, in the reading's own unit; thresholds compare this
+	graph   float64 // plotted value, smoothed
+	display float64 // shown value, smoothed
+	unit    tileUnit
+	ema     *float64 // smoothing state to save, nil without smoothing
+}
+
+// dialReadingValue applies the page's divisor, unit and smoothing to r.
+// Smoothing is keyed by ctx.
+func (p *Plugin) dialReadingValue(ctx string, page *actionSettings, r hwsensorsservice.Reading) (dialValue, error) {
+	v := r.Value()
+	divisor, err := p.getCachedDivisor(ctx, page.Divisor)
+	if err != nil {
+		return dialValue{}, err
+	}
+	if divisor != 1 {
+		v /= divisor
+	}
+
+	unit := resolveTileUnit(r.Unit(), effectiveDisplayUnit(r.Unit(), page.DisplayUnit, p.temperatureUnit()), page.GraphUnit)
+	graphValue := unit.convert(v) // divisor first, as on reading tiles
+	displayValue := graphValue
+
+	// EMA smoothing — same behavior as the normal reading tile (plugin.go),
+	// keyed per page. Threshold eval uses raw v; smoothing affects graph/display.
+	var ema *float64
+	if alpha := page.SmoothingAlpha; alpha > 0 && alpha < 1.0 {
+		p.mu.Lock()
+		prev, ok := p.smoothedValues[ctx]
+		if !ok {
+			prev = graphValue
+		}
+		smoothed := emaSmooth(alpha, graphValue, prev)
+		p.smoothedValues[ctx] = smoothed
+		p.mu.Unlock()
+		ema = &smoothed
+		if graphValue != 0 {
+			ratio := smoothed / graphValue
+			graphValue = smoothed
+			displayValue *= ratio
+		}
+	}
+	return dialValue{raw: v, graph: graphValue, display: displayValue, unit: unit, ema: ema}, nil
+}
+
 func (p *Plugin) updateDialPage(ctx string, settings *dialActionSettings, state *dialState, index int, active bool, now time.Time) (dialPageRender, bool) {
 	var render dialPageRender
 	if index < 0 || index >= len(settings.Pages) {
@@ -1027,6 +1221,9 @@ func (p *Plugin) updateDialPage(ctx string, settings *dialActionSettings, state
 		if active {
 			render.messageTitle = "LHM Dial"
 			render.messageValue = "LHM unavailable"
+			if text := unavailableTileText(err); text != "" {
+				render.messageValue = text
+			}
 		}
 		return render, false
 	}
@@ -1053,53 +1250,32 @@ func (p *Plugin) updateDialPage(ctx string, settings *dialActionSettings, state
 		_ = g.SetLabelText(0, "")
 	}
 
-	v := r.Value()
-	divisor, err := p.getCachedDivisor(pageCtx, page.Divisor)
+	dv, err := p.dialReadingValue(pageCtx, page, r)
 	if err != nil {
 		log.Printf("dial divisor: %v", err)
 		return render, settingsChanged
 	}
-	if divisor != 1 {
-		v /= divisor
-	}
+	v, unit, graphValue, displayValue, ema := dv.raw, dv.unit, dv.graph, dv.display, dv.ema
+	displayUnit := unit.symbol
 
-	graphValue := r.Value()
-	if page.GraphUnit != "" && strings.Contains(r.Unit(), "/s") {
-		graphValue = p.normalizeForGraph(r.Value(), r.Unit(), page.GraphUnit)
-	}
-	if divisor != 1 {
-		graphValue /= divisor
-	}
-
-	displayValue := v
-	displayUnit := r.Unit()
-	if page.GraphUnit != "" && strings.Contains(r.Unit(), "/s") {
-		displayValue = p.normalizeForGraph(v, r.Unit(), page.GraphUnit)
-		displayUnit = page.GraphUnit + "/s"
-	}
+	valueTextNoUnit, displayText := p.formatDisplayValue(displayValue, displayUnit, page.Format, hwsensorsservice.ReadingType(r.TypeI()))
 
-	// EMA smoothing — same behavior as the normal reading tile (plugin.go),
-	// keyed per page. Threshold eval uses raw v; smoothing affects graph/display.
-	if alpha := page.SmoothingAlpha; alpha > 0 && alpha < 1.0 {
-		p.mu.Lock()
-		prev, ok := p.smoothedValues[pageCtx]
-		if !ok {
-			prev = graphValue
-		}
-		smoothed := emaSmooth(alpha, graphValue, prev)
-		p.smoothedValues[pageCtx] = smoothed
-		p.mu.Unlock()
-		if graphValue != 0 {
-			ratio := smoothed / graphValue
-			graphValue = smoothed
-			displayValue *= ratio
+	// Temperature thresholds compare in the page's unit (see updateTiles). The
+	// PI saves whole pages, so it gets the migrated thresholds right away.
+	evalValue := v
+	if unit.isTemperature() {
+		if migrated, ok := convertThresholds(page.Thresholds, unit.to, false); ok {
+			page.Thresholds = migrated
+			settingsChanged = true
+			_ = p.sd.SendToPropertyInspector(dialAction, ctx, map[string]interface{}{"dialSettings": settings})
 		}
+		evalValue = unit.convert(v)
 	}
-
-	valueTextNoUnit, displayText := p.formatDisplayValue(displayValue, displayUnit, page.Format, hwsensorsservice.ReadingType(r.TypeI()))
-
 	thresholds := p.resolveThresholdsForEval(page.Thresholds, page.SuppressedGlobalIDs, hwsensorsservice.ReadingType(r.TypeI()))
-	activeThreshold := p.evaluateThresholds(pageCtx, v, thresholds, now)
+	if unit.isTemperature() {
+		thresholds, _ = convertThresholds(thresholds, unit.to, false)
+	}
+	activeThreshold := p.evaluateThresholds(pageCtx, evalValue, thresholds, now)
 	newThresholdID := ""
 	alertText := ""
 	if activeThreshold != nil {
@@ -1127,7 +1303,7 @@ func (p *Plugin) updateDialPage(ctx string, settings *dialActionSettings, state
 	renderDisplayText, renderAlertText, renderGraphValue, freezeGraph := p.resolveThresholdDisplay(
 		pageCtx,
 		activeThreshold,
-		v,
+		evalValue,
 		graphValue,
 		displayText,
 		alertText,
@@ -1155,27 +1331,85 @@ func (p *Plugin) updateDialPage(ctx string, settings *dialActionSettings, state
 		_ = g.SetLabelText(1, renderDisplayText)
 		if renderAlertText != "" {
 			_ = g.SetLabelText(2, renderAlertText)
+			if dialMirrored(page) {
+				_ = g.SetLabelText(0, "") // the alert takes the title's row
+			}
 		} else {
 			_ = g.SetLabelText(2, "")
 		}
 	}
+	var sample *float64
+	if page.GraphMode != "text" && !freezeGraph {
+		sample = &renderGraphValue
+	}
+	p.history.record(pageCtx, readingHistoryKey(page), now, g.TimeWindow(), sample, ema, g.LabelText(0), g.LabelText(1))
+	if m := state.mirrorAt(index); m != nil {
+		p.updateDialMirror(pageCtx, page, profileID, m, now, freezeGraph)
+	}
 
 	if active {
-		b, err := g.EncodePNG()
-		if err != nil {
-			log.Printf("dial encode: %v", err)
-			return render, settingsChanged
-		}
-		b, err = decorateDialImage(b, settings.ActiveIndex, len(settings.Pages), dialIndicatorFullscreen(settings), dialIndicatorStyle(settings), dialSeparatorWidth(settings), dialSeparatorColor(settings), dialIndicatorColor(settings), dialIndicatorSize(settings))
-		if err != nil {
-			log.Printf("dial decorate: %v", err)
-			return render, settingsChanged
-		}
-		render.image = b
+		img := dialPageImage(g, state.mirrorAt(index))
+		decorateDialImage(img, settings.ActiveIndex, len(settings.Pages), dialIndicatorFullscreen(settings), dialIndicatorStyle(settings), dialSeparatorWidth(settings), dialSeparatorColor(settings), dialIndicatorColor(settings), dialIndicatorSize(settings))
+		render.image = img
 	}
 	return render, settingsChanged
 }
 
+// dialMirrorContext keys the smoothing and history of a page's mirror reading.
+func dialMirrorContext(pageCtx string) string {
+	return pageCtx + "|mirror"
+}
+
+// dialMirrorPage returns the page as it applies to its mirror reading, for
+// the history key.
+func dialMirrorPage(page *actionSettings) *actionSettings {
+	mp := *page
+	mp.ReadingID = page.MirrorReadingID
+	mp.ReadingLabel = page.MirrorReadingLabel
+	return &mp
+}
+
+// updateDialMirror feeds the mirror reading of a page into its lower graph,
+// converted and formatted the way the page shows its own reading. Thresholds
+// only watch the page's own reading, but while one freezes the page's graph
+// the lower graph holds too: both halves share a time axis and a scale.
+func (p *Plugin) updateDialMirror(pageCtx string, page *actionSettings, profileID string, m *graph.Graph, now time.Time, freezeGraph bool) {
+	ctx := dialMirrorContext(pageCtx)
+	r, _, err := p.getReadingForSource(profileID, page.SensorUID, page.MirrorReadingID)
+	if err != nil {
+		_ = m.SetLabelText(1, "Reading missing")
+		return
+	}
+	title := page.MirrorReadingLabel
+	if title == "" {
+		title = r.Label()
+	}
+	if page.GraphMode == "graph" || (page.ShowTitleInGraph != nil && !*page.ShowTitleInGraph) {
+		title = ""
+	}
+	_ = m.SetLabelText(0, title)
+
+	dv, err := p.dialReadingValue(ctx, page, r)
+	if err != nil {
+		log.Printf("dial mirror divisor: %v", err)
+		return
+	}
+	var sample *float64
+	switch {
+	case page.GraphMode == "text":
+		m.Clear()
+	case !freezeGraph:
+		m.Update(dv.graph)
+		sample = &dv.graph
+	}
+	text := ""
+	if page.GraphMode != "graph" {
+		_, text = p.formatDisplayValue(dv.display, dv.unit.symbol, page.Format, hwsensorsservice.ReadingType(r.TypeI()))
+	}
+	_ = m.SetLabelText(1, text)
+	p.history.record(ctx, readingHistoryKey(dialMirrorPage(page)), now, m.TimeWindow(), sample, dv.ema, title, text)
+}
+
 func (p *Plugin) updateDialFeedback(ctx string) {
 	p.mu.RLock()
 	settings := p.dialSettings[ctx]
@@ -1218,19 +1452,14 @@ func (p *Plugin) updateDialFeedback(ctx string) {
 		_ = p.sd.SetSettings(ctx, settings)
 	}
 	if state.overview {
-		var b []byte
-		var err error
+		var img *image.RGBA
 		if dialOverviewStyle(settings) == "stacked" {
-			b, err = p.renderDialStacked(settings, state)
+			img = p.renderDialStacked(settings, state)
 		} else {
-			b, err = p.renderDialOverview(settings, state)
-		}
-		if err != nil {
-			log.Printf("dial overview encode: %v", err)
-			return
+			img = p.renderDialOverview(settings, state)
 		}
-		if len(b) > 0 {
-			p.sendDialCanvas(ctx, b)
+		if img != nil {
+			p.sendDialCanvas(ctx, img)
 		}
 		return
 	}
@@ -1238,13 +1467,13 @@ func (p *Plugin) updateDialFeedback(ctx string) {
 		p.showDialMessage(ctx, activeRender.messageTitle, activeRender.messageValue)
 		return
 	}
-	if len(activeRender.image) == 0 {
+	if activeRender.image == nil {
 		return
 	}
 	p.sendDialCanvas(ctx, activeRender.image)
 }
 
-func (p *Plugin) updateDialTick() {
+func (p *Plugin) updateDialTick(tick *renderTick) {
 	p.mu.RLock()
 	contexts := make([]string, 0, len(p.dialSettings))
 	for ctx := range p.dialSettings {
@@ -1252,7 +1481,7 @@ func (p *Plugin) updateDialTick() {
 	}
 	p.mu.RUnlock()
 	for _, ctx := range contexts {
-		p.updateDialFeedback(ctx)
+		tick.render(ctx, func() { p.updateDialFeedback(ctx) })
 	}
 }
 
@@ -1263,21 +1492,79 @@ func (p *Plugin) handleDialWillAppear(event *streamdeck.EvWillAppear) {
 	}
 	state := initDialState(&settings)
 	state.overview = dialDefaultOverview(&settings)
+	smoothed := p.restoreDialHistory(event.Context, &settings, state)
 	p.mu.Lock()
+	for pageCtx, v := range smoothed {
+		p.smoothedValues[pageCtx] = v
+	}
 	p.dialSettings[event.Context] = &settings
 	p.dialStates[event.Context] = state
 	p.mu.Unlock()
+	now := time.Now()
+	for i := range settings.Pages {
+		p.restoreThresholdRuntimeState(dialPageContext(event.Context, i), now)
+	}
 	if err := p.sd.SetFeedbackLayout(event.Context, "$A0"); err != nil {
 		log.Printf("dial setFeedbackLayout: %v", err)
 	}
 	p.updateDialFeedback(event.Context)
 }
 
+// restoreDialHistory refills the page graphs of a new state from the saved
+// history and returns the EMA state to restore, keyed by page context. The
+// first feedback update then draws the restored graphs.
+func (p *Plugin) restoreDialHistory(ctx string, settings *dialActionSettings, state *dialState) map[string]float64 {
+	smoothed := make(map[string]float64)
+	for i := range settings.Pages {
+		if i >= len(state.graphs) || !settings.Pages[i].IsValid {
+			continue
+		}
+		// Pages inherit the dial's source the way updateDialPage fills it in.
+		page := settings.Pages[i]
+		if page.SourceProfileID == "" {
+			page.SourceProfileID = settings.SourceProfileID
+		}
+		pageCtx := dialPageContext(ctx, i)
+		if m := state.mirrorAt(i); m != nil {
+			mirrorCtx := dialMirrorContext(pageCtx)
+			if e, ok := p.restoreGraphHistory(mirrorCtx, readingHistoryKey(dialMirrorPage(&page)), m); ok {
+				_ = m.SetLabelText(0, e.Title)
+				_ = m.SetLabelText(1, e.Text)
+				if e.Smoothed != nil {
+					smoothed[mirrorCtx] = *e.Smoothed
+				}
+			}
+		}
+		e, ok := p.restoreGraphHistory(pageCtx, readingHistoryKey(&page), state.graphs[i])
+		if !ok {
+			continue
+		}
+		g := state.graphs[i]
+		if page.Title == "" && (page.ShowTitleInGraph == nil || *page.ShowTitleInGraph) {
+			_ = g.SetLabelText(0, e.Title)
+		}
+		if page.GraphMode != "graph" {
+			_ = g.SetLabelText(1, e.Text)
+		}
+		if e.Smoothed != nil {
+			smoothed[pageCtx] = *e.Smoothed
+		}
+	}
+	return smoothed
+}
+
 func (p *Plugin) handleDialWillDisappear(event *streamdeck.EvWillDisappear) {
 	p.mu.Lock()
+	pages := 0
+	if settings := p.dialSettings[event.Context]; settings != nil {
+		pages = len(settings.Pages)
+	}
 	delete(p.dialSettings, event.Context)
 	delete(p.dialStates, event.Context)
 	p.mu.Unlock()
+	for i := 0; i < pages; i++ {
+		p.parkThresholdRuntimeState(dialPageContext(event.Context, i))
+	}
 }
 
 func (p *Plugin) handleDialPropertyInspectorConnected(event *streamdeck.EvSendToPlugin) {
@@ -1364,8 +1651,10 @@ func (p *Plugin) handleDialSendToPlugin(event *streamdeck.EvSendToPlugin, payloa
 		}
 		newState := &dialState{
 			graphs:   buildDialGraphs(oldSettings, oldState, &settings),
+			mirrors:  buildDialMirrors(oldSettings, oldState, &settings),
 			overview: overview,
 		}
+		linkDialGraphs(newState)
 		p.dialSettings[event.Context] = &settings
 		p.dialStates[event.Context] = newState
 		p.mu.Unlock()
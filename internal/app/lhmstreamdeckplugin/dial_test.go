@@ -1,11 +1,9 @@
 package lhmstreamdeckplugin
 
 import (
-	"bytes"
 	"encoding/json"
 	"image"
 	"image/color"
-	"image/png"
 	"os"
 	"testing"
 	"time"
@@ -226,38 +224,26 @@ func TestDefaultDialFontSizes(t *testing.T) {
 func TestDialSeparatorDynamicWidthAndColor(t *testing.T) {
 	bg := color.RGBA{20, 120, 30, 255}
 	sep := color.RGBA{200, 30, 30, 255}
-	fixture := func() []byte {
+	fixture := func() *image.RGBA {
 		img := image.NewRGBA(image.Rect(0, 0, dialWidth, dialHeight))
 		fillRect(img, img.Bounds(), bg)
-		var buf bytes.Buffer
-		if err := png.Encode(&buf, img); err != nil {
-			t.Fatalf("encode fixture: %v", err)
-		}
-		return buf.Bytes()
+		return img
 	}
-	at := func(b []byte, x, y int) color.RGBA {
-		im, err := png.Decode(bytes.NewReader(b))
-		if err != nil {
-			t.Fatalf("decode: %v", err)
-		}
-		return color.RGBAModel.Convert(im.At(x, y)).(color.RGBA)
+	at := func(im *image.RGBA, x, y int) color.RGBA {
+		return im.RGBAAt(x, y)
 	}
 	y := dialHeight / 2
 
 	// width 0 = off: edges keep the original graph pixels.
-	off, err := decorateDialImage(fixture(), 0, 1, false, "auto", 0, sep, dialIndicatorDefaultColor, dialIndicatorDefaultSize)
-	if err != nil {
-		t.Fatalf("decorate off: %v", err)
-	}
+	off := fixture()
+	decorateDialImage(off, 0, 1, false, "auto", 0, sep, dialIndicatorDefaultColor, dialIndicatorDefaultSize)
 	if at(off, 0, y) != bg || at(off, dialWidth-1, y) != bg {
 		t.Fatalf("width 0 must not draw a separator")
 	}
 
 	// width 5 + color: a 5px band of that color on each edge, center untouched.
-	on, err := decorateDialImage(fixture(), 0, 1, false, "auto", 5, sep, dialIndicatorDefaultColor, dialIndicatorDefaultSize)
-	if err != nil {
-		t.Fatalf("decorate on: %v", err)
-	}
+	on := fixture()
+	decorateDialImage(on, 0, 1, false, "auto", 5, sep, dialIndicatorDefaultColor, dialIndicatorDefaultSize)
 	if at(on, 0, y) != sep || at(on, 4, y) != sep || at(on, dialWidth-1, y) != sep || at(on, dialWidth-5, y) != sep {
 		t.Fatalf("width 5 must draw a 5px colored band on both edges")
 	}
@@ -453,18 +439,15 @@ func TestRenderDialStackedNativeStrips(t *testing.T) {
 	}
 	settings := &dialActionSettings{Pages: pages, ActiveIndex: 2, OverviewStyle: "stacked"}
 
-	b, err := (&Plugin{}).renderDialStacked(settings, state)
-	if err != nil {
-		t.Fatalf("renderDialStacked: %v", err)
-	}
-	if b == nil {
+	img := (&Plugin{}).renderDialStacked(settings, state)
+	if img == nil {
 		t.Fatal("renderDialStacked returned nil image")
 	}
-	img, err := png.Decode(bytes.NewReader(b))
-	if err != nil {
-		t.Fatalf("decode: %v", err)
-	}
 	if dump := os.Getenv("LHM_DUMP_DIAL"); dump != "" {
+		b, err := graph.EncodeImage(img)
+		if err != nil {
+			t.Fatalf("encode: %v", err)
+		}
 		if err := os.WriteFile(dump, b, 0o644); err != nil {
 			t.Fatalf("dump: %v", err)
 		}
@@ -537,14 +520,7 @@ func TestRenderDialStackedHonoursIndicatorSize(t *testing.T) {
 			IndicatorColor: "#ff0000",
 			IndicatorSize:  &sz,
 		}
-		b, err := (&Plugin{}).renderDialStacked(settings, state)
-		if err != nil {
-			t.Fatalf("renderDialStacked(size=%v): %v", size, err)
-		}
-		img, err := png.Decode(bytes.NewReader(b))
-		if err != nil {
-			t.Fatalf("decode(size=%v): %v", size, err)
-		}
+		img := (&Plugin{}).renderDialStacked(settings, state)
 		count := 0
 		for y := 0; y < dialHeight; y++ {
 			for x := 0; x < dialStackedGutter(size); x++ {
@@ -591,14 +567,7 @@ func TestRenderDialStackedCountHonoursIndicatorSize(t *testing.T) {
 			IndicatorColor: "#ff0000",
 			IndicatorSize:  &sz,
 		}
-		b, err := (&Plugin{}).renderDialStacked(settings, state)
-		if err != nil {
-			t.Fatalf("renderDialStacked(size=%v): %v", size, err)
-		}
-		img, err := png.Decode(bytes.NewReader(b))
-		if err != nil {
-			t.Fatalf("decode(size=%v): %v", size, err)
-		}
+		img := (&Plugin{}).renderDialStacked(settings, state)
 		count := 0
 		for y := 0; y < dialHeight; y++ {
 			for x := 0; x < dialStackedGutter(size); x++ {
@@ -829,24 +798,16 @@ func TestDialIndicatorFullscreenToggle(t *testing.T) {
 		t.Fatalf("indicator fullscreen must be on when enabled")
 	}
 
-	fixture := func() []byte {
+	fixture := func() *image.RGBA {
 		img := image.NewRGBA(image.Rect(0, 0, dialWidth, dialHeight))
 		fillRect(img, img.Bounds(), color.RGBA{0, 0, 0, 255})
-		var buf bytes.Buffer
-		if err := png.Encode(&buf, img); err != nil {
-			t.Fatalf("encode fixture: %v", err)
-		}
-		return buf.Bytes()
+		return img
 	}
 	black := color.RGBA{0, 0, 0, 255}
 	// The fullscreen indicator is drawn vertically in the LEFT gutter (like the
 	// stacked overview), so count any non-black pixels there. The bottom-centre,
 	// where the old horizontal indicator sat, must now stay clear of the graph.
-	leftGutterPixels := func(b []byte) int {
-		im, err := png.Decode(bytes.NewReader(b))
-		if err != nil {
-			t.Fatalf("decode: %v", err)
-		}
+	leftGutterPixels := func(im *image.RGBA) int {
 		n := 0
 		for y := 2; y < dialHeight-2; y++ {
 			for x := 2; x < 16; x++ {
@@ -859,19 +820,15 @@ func TestDialIndicatorFullscreenToggle(t *testing.T) {
 	}
 
 	// Toggle off: fullscreen keeps its original look, no indicator drawn.
-	off, err := decorateDialImage(fixture(), 1, 3, false, "auto", 0, color.RGBA{}, dialIndicatorDefaultColor, dialIndicatorDefaultSize)
-	if err != nil {
-		t.Fatalf("decorate off: %v", err)
-	}
+	off := fixture()
+	decorateDialImage(off, 1, 3, false, "auto", 0, color.RGBA{}, dialIndicatorDefaultColor, dialIndicatorDefaultSize)
 	if px := leftGutterPixels(off); px != 0 {
 		t.Fatalf("indicator must not be drawn in fullscreen when toggle is off (left-gutter pixels=%d)", px)
 	}
 
 	// Toggle on: the indicator is drawn in fullscreen, in the LEFT gutter.
-	on, err := decorateDialImage(fixture(), 1, 3, true, "auto", 0, color.RGBA{}, dialIndicatorDefaultColor, dialIndicatorDefaultSize)
-	if err != nil {
-		t.Fatalf("decorate on: %v", err)
-	}
+	on := fixture()
+	decorateDialImage(on, 1, 3, true, "auto", 0, color.RGBA{}, dialIndicatorDefaultColor, dialIndicatorDefaultSize)
 	if px := leftGutterPixels(on); px == 0 {
 		t.Fatalf("indicator must be drawn in the left gutter in fullscreen when toggle is on")
 	}
@@ -932,14 +889,10 @@ func TestUpdateDialPageDrawsFullscreenIndicatorOnlyWhenEnabled(t *testing.T) {
 		}
 		state := initDialState(settings)
 		r, _ := p.updateDialPage(ctx, settings, state, settings.ActiveIndex, true, now)
-		if len(r.image) == 0 {
+		if r.image == nil {
 			t.Fatalf("no fullscreen image rendered (indicator=%v)", fullscreenIndicator)
 		}
-		im, err := png.Decode(bytes.NewReader(r.image))
-		if err != nil {
-			t.Fatalf("decode fullscreen image: %v", err)
-		}
-		return im
+		return r.image
 	}
 
 	off := render(false)
@@ -1042,6 +995,42 @@ func TestBuildDialGraphsPreservesHistory(t *testing.T) {
 	}
 }
 
+func TestBuildDialMirrors(t *testing.T) {
+	old := &dialActionSettings{Pages: []actionSettings{
+		{SensorUID: "net", ReadingID: 1, MirrorReadingID: 2},
+		{SensorUID: "cpu", ReadingID: 3},
+	}}
+	oldState := initDialState(old)
+	if oldState.mirrors[0] == nil || oldState.mirrors[1] != nil {
+		t.Fatalf("mirrors = %v, want a graph for page 0 only", oldState.mirrors)
+	}
+
+	// A color edit keeps the mirror and its history.
+	colorEdit := &dialActionSettings{Pages: []actionSettings{
+		{SensorUID: "net", ReadingID: 1, MirrorReadingID: 2, MirrorHighlightColor: "#ff0000"},
+		{SensorUID: "cpu", ReadingID: 3},
+	}}
+	if got := buildDialMirrors(old, oldState, colorEdit); got[0] != oldState.mirrors[0] {
+		t.Errorf("mirror graph rebuilt on color edit; history lost")
+	}
+
+	// Another mirror reading rebuilds it; turning it off drops it.
+	readingChange := &dialActionSettings{Pages: []actionSettings{
+		{SensorUID: "net", ReadingID: 1, MirrorReadingID: 4},
+		{SensorUID: "cpu", ReadingID: 3},
+	}}
+	if got := buildDialMirrors(old, oldState, readingChange); got[0] == nil || got[0] == oldState.mirrors[0] {
+		t.Errorf("mirror graph not rebuilt on reading change")
+	}
+	off := &dialActionSettings{Pages: []actionSettings{
+		{SensorUID: "net", ReadingID: 1},
+		{SensorUID: "cpu", ReadingID: 3},
+	}}
+	if got := buildDialMirrors(old, oldState, off); got[0] != nil {
+		t.Errorf("mirror graph kept after turning the mirror off")
+	}
+}
+
 func TestUpdateDialPageKeepsSnoozeWhenThresholdDrops(t *testing.T) {
 	const (
 		ctx       = "dial-ctx"
@@ -1233,3 +1222,80 @@ func TestHandleDialPageTouchClearsStickyThresholdWithoutSnoozeDurations(t *testi
 		t.Fatalf("expected no-op touch after sticky threshold cleared")
 	}
 }
+
+func TestDialSparklineFollowsPageStyle(t *testing.T) {
+	page := actionSettings{Min: 0, Max: 100, GraphStyle: "dots"}
+	g := newDialGraph(&page)
+	for _, v := range []float64{20, 80, 50} {
+		g.Update(v)
+	}
+	if g.Style() != graph.StyleDots {
+		t.Fatalf("Style() = %v, want dots", g.Style())
+	}
+	countFill := func() int {
+		img := image.NewRGBA(image.Rect(0, 0, 40, 20))
+		drawDialSparkline(img, img.Bounds(), g)
+		fg, n := g.ForegroundColor(), 0
+		for y := 0; y < 20; y++ {
+			for x := 0; x < 40; x++ {
+				if img.RGBAAt(x, y) == fg {
+					n++
+				}
+			}
+		}
+		return n
+	}
+	if n := countFill(); n != 0 {
+		t.Fatalf("dots sparkline has %d fill pixels, want none", n)
+	}
+
+	page.GraphStyle = ""
+	applyDialGraphSettings(g, &page)
+	if n := countFill(); n == 0 {
+		t.Fatal("area sparkline has no fill")
+	}
+}
+
+func TestDialMirrorHoldsWhileThePageIsFrozen(t *testing.T) {
+	const (
+		pageCtx   = "dial-ctx|dial|page|0"
+		sensorUID = "/nic"
+	)
+	p := &Plugin{
+		sources:          make(map[string]*sourceRuntime),
+		lastPollTime:     make(map[string]uint64),
+		smoothedValues:   make(map[string]float64),
+		divisorCache:     make(map[string]divisorCacheEntry),
+		pollTimeCacheTTL: time.Second,
+	}
+	p.sources[""] = &sourceRuntime{
+		hw: stubHardwareService{
+			readingsBySensor: map[string][]hwsensorsservice.Reading{
+				sensorUID: {
+					stubReading{id: 1, typ: "Throughput", label: "Download", unit: "KB/s", value: 900},
+					stubReading{id: 2, typ: "Throughput", label: "Upload", unit: "KB/s", value: 40},
+				},
+			},
+		},
+	}
+	settings := &dialActionSettings{Pages: []actionSettings{{
+		SensorUID: sensorUID, ReadingID: 1, MirrorReadingID: 2, IsValid: true, Min: 0, Max: 1000,
+	}}}
+	state := initDialState(settings)
+	m := state.mirrorAt(0)
+	page := &settings.Pages[0]
+	now := time.Unix(1200, 0)
+
+	p.updateDialMirror(pageCtx, page, "", m, now, false)
+	p.updateDialMirror(pageCtx, page, "", m, now.Add(time.Second), true)
+	if n := len(m.Samples()); n != 1 {
+		t.Fatalf("mirror has %d samples, want it to hold while the page is frozen", n)
+	}
+	if text := m.LabelText(1); text == "" {
+		t.Fatal("frozen mirror lost its value text")
+	}
+	p.updateDialMirror(pageCtx, page, "", m, now.Add(2*time.Second), false)
+	if n := len(m.Samples()); n != 2 {
+		t.Fatalf("mirror has %d samples, want it to scroll again", n)
+	}
+}
@@ -0,0 +1,334 @@
+package lhmstreamdeckplugin
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/maphash"
+	"image"
+	"image/png"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/moeilijk/lhm-streamdeck/pkg/graph"
+	"github.com/moeilijk/lhm-streamdeck/pkg/streamdeck"
+)
+
+// frameCache remembers a hash of the last image sent to each context so a
+// frame identical to the one already on the key or dial is neither encoded
+// as PNG nor pushed over the websocket again. The zero value is ready to use.
+type frameCache struct {
+	mu         sync.Mutex
+	seed       maphash.Seed
+	hashes     map[string]uint64
+	rendered   uint64 // frames handed to setImage/sendDialCanvas
+	suppressed uint64 // of those, frames identical to the previous one
+	bytesSent  uint64 // data URL bytes of the frames that were sent
+}
+
+// changed reports whether img differs from the last frame sent to context
+// and records it as sent if so. It hashes the pixels and the size, so an
+// unchanged frame is caught before it is encoded.
+func (fc *frameCache) changed(context string, img *image.RGBA) bool {
+	var size [8]byte
+	binary.LittleEndian.PutUint32(size[:4], uint32(img.Rect.Dx()))
+	binary.LittleEndian.PutUint32(size[4:], uint32(img.Rect.Dy()))
+	var h maphash.Hash
+	h.SetSeed(fc.hashSeed())
+	h.Write(size[:])
+	h.Write(img.Pix)
+	return fc.record(context, h.Sum64())
+}
+
+// changedPNG is changed for an image that is already encoded.
+func (fc *frameCache) changedPNG(context string, b []byte) bool {
+	return fc.record(context, maphash.Bytes(fc.hashSeed(), b))
+}
+
+func (fc *frameCache) hashSeed() maphash.Seed {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	if fc.hashes == nil {
+		fc.hashes = make(map[string]uint64)
+		fc.seed = maphash.MakeSeed()
+	}
+	return fc.seed
+}
+
+func (fc *frameCache) record(context string, h uint64) bool {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	fc.rendered++
+	if last, ok := fc.hashes[context]; ok && last == h {
+		fc.suppressed++
+		return false
+	}
+	fc.hashes[context] = h
+	return true
+}
+
+// sent counts a frame of n PNG bytes that went out.
+func (fc *frameCache) sent(n int) {
+	fc.mu.Lock()
+	fc.bytesSent += uint64(len(pngDataURLPrefix) + base64.StdEncoding.EncodedLen(n))
+	fc.mu.Unlock()
+}
+
+// forget drops the last frame of context, so the next one is sent whatever
+// it looks like: after willAppear the key shows the action's default image,
+// and a failed send may not have reached it.
+func (fc *frameCache) forget(context string) {
+	fc.mu.Lock()
+	delete(fc.hashes, context)
+	fc.mu.Unlock()
+}
+
+// frameStatus is the frame counters shown in the settings PI.
+type frameStatus struct {
+	Rendered   uint64 `json:"rendered"`
+	Suppressed uint64 `json:"suppressed"`
+	BytesSent  uint64 `json:"bytesSent"`
+	Transport  string `json:"transport"`
+	Level      string `json:"compression"`
+}
+
+func (fc *frameCache) status() frameStatus {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	return frameStatus{Rendered: fc.rendered, Suppressed: fc.suppressed, BytesSent: fc.bytesSent}
+}
+
+const pngDataURLPrefix = "data:image/png;base64,"
+
+// setImage encodes and sends a key image unless the key already shows it.
+func (p *Plugin) setImage(context string, img *image.RGBA) error {
+	if !p.frames.changed(context, img) {
+		return nil
+	}
+	b, err := graph.EncodeImage(img)
+	if err != nil {
+		p.frames.forget(context)
+		return err
+	}
+	return p.sendImage(context, b)
+}
+
+// setPNG sends an encoded key image unless the key already shows it.
+func (p *Plugin) setPNG(context string, b []byte) error {
+	if !p.frames.changedPNG(context, b) {
+		return nil
+	}
+	return p.sendImage(context, b)
+}
+
+func (p *Plugin) sendImage(context string, b []byte) error {
+	if err := p.sd.SetImage(context, b); err != nil {
+		p.frames.forget(context)
+		return err
+	}
+	p.frames.sent(len(b))
+	return nil
+}
+
+// Transports are the host applications the plugin can be connected to.
+const (
+	transportStreamDeck = "streamdeck"
+	transportOpenDeck   = "opendeck"
+)
+
+// detectTransport tells OpenDeck from Elgato's Stream Deck software by the
+// application in the registration info: its name, or a version string that
+// names OpenDeck (Elgato's is a bare build number). Only when neither says
+// is the platform consulted, since Elgato's software does not run on Linux.
+func detectTransport(info string) string {
+	var reg struct {
+		Application struct {
+			Name     string `json:"name"`
+			Version  string `json:"version"`
+			Platform string `json:"platform"`
+		} `json:"application"`
+	}
+	if err := json.Unmarshal([]byte(info), &reg); err != nil {
+		return transportStreamDeck
+	}
+	app := reg.Application
+	switch {
+	case app.Name != "":
+		if namesOpenDeck(app.Name) {
+			return transportOpenDeck
+		}
+		return transportStreamDeck
+	case namesOpenDeck(app.Version):
+		return transportOpenDeck
+	case app.Platform == "linux":
+		return transportOpenDeck
+	}
+	return transportStreamDeck
+}
+
+func namesOpenDeck(s string) bool {
+	return strings.Contains(strings.ToLower(s), "opendeck")
+}
+
+// pngCompressionLevels maps the compression setting to a PNG level.
+var pngCompressionLevels = map[string]png.CompressionLevel{
+	"none":    png.NoCompression,
+	"fast":    png.BestSpeed,
+	"default": png.DefaultCompression,
+	"best":    png.BestCompression,
+}
+
+// defaultPNGCompression is the level used until one is chosen for transport.
+// Elgato's software runs on the same machine, where encoding time matters
+// more than size; OpenDeck pushes every image through its frontend, where
+// the payload size does.
+func defaultPNGCompression(transport string) string {
+	if transport == transportOpenDeck {
+		return "best"
+	}
+	return "none"
+}
+
+// pngCompressionLocked returns the compression setting of the current
+// transport. Requires p.mu.
+func (p *Plugin) pngCompressionLocked() string {
+	if level, ok := p.globalSettings.PNGCompression[p.transport]; ok {
+		if _, valid := pngCompressionLevels[level]; valid {
+			return level
+		}
+	}
+	return defaultPNGCompression(p.transport)
+}
+
+// applyPNGCompression sets the encoder level from the global settings.
+func (p *Plugin) applyPNGCompression() {
+	p.mu.RLock()
+	level := p.pngCompressionLocked()
+	p.mu.RUnlock()
+	graph.SetCompressionLevel(pngCompressionLevels[level])
+}
+
+// setPNGCompression stores the compression level of the current transport.
+func (p *Plugin) setPNGCompression(level string) error {
+	if _, ok := pngCompressionLevels[level]; !ok {
+		return fmt.Errorf("unknown PNG compression %q", level)
+	}
+	p.mu.Lock()
+	levels := make(map[string]string, len(p.globalSettings.PNGCompression)+1)
+	for t, l := range p.globalSettings.PNGCompression {
+		levels[t] = l
+	}
+	levels[p.transport] = level
+	p.globalSettings.PNGCompression = levels
+	gs := p.globalSettings
+	p.mu.Unlock()
+
+	if err := p.sd.SetGlobalSettings(gs); err != nil {
+		log.Printf("setPNGCompression SetGlobalSettings: %v\n", err)
+	}
+	p.applyPNGCompression()
+	log.Printf("PNG compression for %s set to %s\n", p.transport, level)
+	return nil
+}
+
+// renderings maps the rendering setting to how graphs are drawn. Smooth is
+// opt-in; "pixel" is what the setting was stored as when smooth was the
+// default.
+var renderings = map[string]graph.Rendering{
+	"":       graph.RenderingPixel,
+	"pixel":  graph.RenderingPixel,
+	"smooth": graph.RenderingSmooth,
+}
+
+// applyRendering sets how graphs are drawn from the global settings.
+func (p *Plugin) applyRendering() {
+	p.mu.RLock()
+	r := renderings[p.globalSettings.Rendering]
+	p.mu.RUnlock()
+	graph.SetRendering(r)
+}
+
+// setRendering stores how graphs are drawn: "smooth" for anti-aliased
+// graphs on high-resolution keys, "" for the classic hard-edged look.
+func (p *Plugin) setRendering(rendering string) error {
+	if _, ok := renderings[rendering]; !ok {
+		return fmt.Errorf("unknown rendering %q", rendering)
+	}
+	p.mu.Lock()
+	p.globalSettings.Rendering = rendering
+	gs := p.globalSettings
+	p.mu.Unlock()
+
+	if err := p.sd.SetGlobalSettings(gs); err != nil {
+		log.Printf("setRendering SetGlobalSettings: %v\n", err)
+	}
+	p.applyRendering()
+	p.updateAllSettingsTiles()
+	return nil
+}
+
+// deviceKeySizes is the key size in pixels of the Stream Deck models with
+// keys, by device type.
+var deviceKeySizes = map[int]int{
+	0: 72,  // Stream Deck
+	1: 80,  // Stream Deck Mini
+	2: 96,  // Stream Deck XL
+	7: 120, // Stream Deck +
+	9: 96,  // Stream Deck Neo
+}
+
+// devicePixelRatio returns the scale smooth key images are drawn at on a
+// device type: enough 72x72 tiles to cover its key, or keyPixelRatio when its
+// key size is not known.
+func devicePixelRatio(deviceType int) int {
+	size, ok := deviceKeySizes[deviceType]
+	if !ok {
+		return keyPixelRatio
+	}
+	return (size + tileWidth - 1) / tileWidth
+}
+
+// registeredDeviceRatios returns the pixel ratio of every device in the
+// registration info.
+func registeredDeviceRatios(info string) map[string]int {
+	var reg struct {
+		Devices []streamdeck.EvDeviceInfo `json:"devices"`
+	}
+	ratios := make(map[string]int)
+	if err := json.Unmarshal([]byte(info), &reg); err != nil {
+		return ratios
+	}
+	for _, d := range reg.Devices {
+		if d.ID != "" {
+			ratios[d.ID] = devicePixelRatio(d.Type)
+		}
+	}
+	return ratios
+}
+
+// pixelRatio returns the scale smooth key images of context are drawn at.
+func (p *Plugin) pixelRatio(context string) int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.pixelRatioLocked(context)
+}
+
+// pixelRatioLocked is pixelRatio. Requires p.mu.
+func (p *Plugin) pixelRatioLocked(context string) int {
+	if r, ok := p.deviceRatios[p.keyDevices[context]]; ok {
+		return r
+	}
+	return keyPixelRatio
+}
+
+// frameStatus returns the frame counters with the transport they apply to.
+func (p *Plugin) frameStatus() frameStatus {
+	st := p.frames.status()
+	p.mu.RLock()
+	st.Transport = p.transport
+	st.Level = p.pngCompressionLocked()
+	p.mu.RUnlock()
+	return st
+}
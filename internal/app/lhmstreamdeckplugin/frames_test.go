@@ -0,0 +1,144 @@
+package lhmstreamdeckplugin
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/moeilijk/lhm-streamdeck/pkg/graph"
+	"github.com/moeilijk/lhm-streamdeck/pkg/streamdeck"
+)
+
+func TestFrameCacheSuppressesRepeatedFrames(t *testing.T) {
+	var fc frameCache
+	frame := func(w, h int, c color.RGBA) *image.RGBA {
+		img := image.NewRGBA(image.Rect(0, 0, w, h))
+		fillRect(img, img.Bounds(), c)
+		return img
+	}
+	red, green := color.RGBA{255, 0, 0, 255}, color.RGBA{0, 255, 0, 255}
+	a, b := frame(4, 2, red), frame(4, 2, green)
+
+	if !fc.changed("ctx", a) {
+		t.Fatal("first frame was suppressed")
+	}
+	fc.sent(10)
+	if fc.changed("ctx", frame(4, 2, red)) {
+		t.Fatal("frame with identical pixels was not suppressed")
+	}
+	if !fc.changed("other", a) {
+		t.Fatal("frame of another context was suppressed")
+	}
+	if !fc.changed("ctx", b) {
+		t.Fatal("new frame was suppressed")
+	}
+	if !fc.changed("ctx", a) {
+		t.Fatal("return to an earlier frame was suppressed")
+	}
+	if !fc.changed("ctx", frame(2, 4, red)) {
+		t.Fatal("frame of another size with the same pixels was suppressed")
+	}
+
+	fc.forget("ctx")
+	if !fc.changed("ctx", a) {
+		t.Fatal("frame after forget was suppressed")
+	}
+
+	enc := []byte("encoded")
+	if !fc.changedPNG("status", enc) || fc.changedPNG("status", enc) {
+		t.Fatal("encoded frames are not compared by content")
+	}
+
+	st := fc.status()
+	if st.Rendered != 9 || st.Suppressed != 2 {
+		t.Fatalf("status = %+v, want 9 rendered, 2 suppressed", st)
+	}
+	if want := uint64(len(pngDataURL(make([]byte, 10)))); st.BytesSent != want {
+		t.Fatalf("BytesSent = %d, want %d", st.BytesSent, want)
+	}
+}
+
+func TestDetectTransport(t *testing.T) {
+	tests := []struct {
+		info string
+		want string
+	}{
+		{`{"application":{"platform":"windows","version":"6.7"}}`, transportStreamDeck},
+		{`{"application":{"platform":"mac"}}`, transportStreamDeck},
+		{`{"application":{"platform":"linux"}}`, transportOpenDeck},
+		{`{"application":{"name":"OpenDeck","platform":"windows"}}`, transportOpenDeck},
+		{`{"application":{"name":"Stream Deck","platform":"linux"}}`, transportStreamDeck},
+		{`{"application":{"platform":"mac","version":"OpenDeck 2.5.0"}}`, transportOpenDeck},
+		{`{"application":{"platform":"linux","version":"2.5.0"}}`, transportOpenDeck},
+		{`not json`, transportStreamDeck},
+	}
+	for _, tt := range tests {
+		if got := detectTransport(tt.info); got != tt.want {
+			t.Errorf("detectTransport(%s) = %q, want %q", tt.info, got, tt.want)
+		}
+	}
+}
+
+func TestPNGCompressionIsPerTransport(t *testing.T) {
+	p := &Plugin{transport: transportOpenDeck}
+	if got := p.pngCompressionLocked(); got != "best" {
+		t.Fatalf("OpenDeck default = %q, want best", got)
+	}
+
+	p.globalSettings.PNGCompression = map[string]string{transportStreamDeck: "fast"}
+	if got := p.pngCompressionLocked(); got != "best" {
+		t.Fatalf("Stream Deck level leaked into OpenDeck: %q", got)
+	}
+
+	p.transport = transportStreamDeck
+	if got := p.pngCompressionLocked(); got != "fast" {
+		t.Fatalf("Stream Deck level = %q, want fast", got)
+	}
+
+	p.globalSettings.PNGCompression[transportStreamDeck] = "max"
+	if got := p.pngCompressionLocked(); got != "none" {
+		t.Fatalf("unknown level = %q, want the none default", got)
+	}
+}
+
+func TestApplyRenderingDefaultsToPixel(t *testing.T) {
+	defer graph.SetRendering(graph.RenderingPixel)
+	p := &Plugin{}
+	for _, tc := range []struct {
+		setting string
+		want    graph.Rendering
+	}{
+		{"", graph.RenderingPixel},
+		{"pixel", graph.RenderingPixel},
+		{"smooth", graph.RenderingSmooth},
+		{"bogus", graph.RenderingPixel},
+	} {
+		p.globalSettings.Rendering = tc.setting
+		p.applyRendering()
+		if got := graph.CurrentRendering(); got != tc.want {
+			t.Errorf("rendering %q = %v, want %v", tc.setting, got, tc.want)
+		}
+	}
+	if err := p.setRendering("bogus"); err == nil {
+		t.Error("setRendering accepted an unknown rendering")
+	}
+}
+
+func TestPixelRatioFollowsDevice(t *testing.T) {
+	info := `{"application":{"platform":"windows"},"devices":[` +
+		`{"id":"classic","name":"Stream Deck","type":0,"size":{"columns":5,"rows":3}},` +
+		`{"id":"xl","name":"Stream Deck XL","type":2,"size":{"columns":8,"rows":4}}]}`
+	p := &Plugin{deviceRatios: registeredDeviceRatios(info), keyDevices: map[string]string{
+		"a": "classic", "b": "xl", "c": "plus", "d": "unknown",
+	}}
+	p.OnDeviceDidConnect(&streamdeck.EvDeviceDidConnect{
+		Device:     "plus",
+		DeviceInfo: streamdeck.EvDeviceInfo{Name: "Stream Deck +", Type: 7},
+	})
+
+	for ctx, want := range map[string]int{"a": 1, "b": 2, "c": 2, "d": keyPixelRatio, "gone": keyPixelRatio} {
+		if got := p.pixelRatio(ctx); got != want {
+			t.Errorf("pixelRatio(%s) = %d, want %d", ctx, got, want)
+		}
+	}
+}
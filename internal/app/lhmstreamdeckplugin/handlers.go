@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"image/color"
 	"log"
+	"math"
 	"strconv"
 	"strings"
 	"sync"
@@ -72,6 +73,20 @@ func getDefaultMinMaxForReading(r hwsensorsservice.Reading) (int, int) {
 	case "Yes/No":
 		return 0, 1
 	}
+	switch hwsensorsservice.ReadingType(r.TypeI()) {
+	case hwsensorsservice.ReadingTypeControl, hwsensorsservice.ReadingTypeLevel, hwsensorsservice.ReadingTypeHumidity:
+		return 0, 100
+	case hwsensorsservice.ReadingTypeNoise:
+		return 0, 80
+	case hwsensorsservice.ReadingTypeThroughput, hwsensorsservice.ReadingTypeData,
+		hwsensorsservice.ReadingTypeSmallData, hwsensorsservice.ReadingTypeEnergy,
+		hwsensorsservice.ReadingTypeFlow, hwsensorsservice.ReadingTypeFrequency,
+		hwsensorsservice.ReadingTypeTimeSpan, hwsensorsservice.ReadingTypeTiming,
+		hwsensorsservice.ReadingTypeConductivity, hwsensorsservice.ReadingTypeFactor:
+		// Rates, counters and factors start at zero; LHM's since-start
+		// minimum is usually an idle value that would hide most of the range.
+		return 0, max(1, int(math.Ceil(math.Max(r.ValueMax(), r.Value())*1.2)))
+	}
 	min := r.ValueMin()
 	max := r.ValueMax()
 	min -= min * .2
@@ -273,6 +288,34 @@ func (p *Plugin) handleSetGraphUnit(event *streamdeck.EvSendToPlugin, sdpi *evSd
 	return nil
 }
 
+func (p *Plugin) handleSetDisplayUnit(event *streamdeck.EvSendToPlugin, sdpi *evSdpiCollection) error {
+	settings, err := p.am.getSettings(event.Context)
+	if err != nil {
+		return fmt.Errorf("handleSetDisplayUnit getSettings: %v", err)
+	}
+	settings.DisplayUnit = sdpi.Value
+	err = p.sd.SetSettings(event.Context, &settings)
+	if err != nil {
+		return fmt.Errorf("handleSetDisplayUnit SetSettings: %v", err)
+	}
+	p.am.SetAction(event.Action, event.Context, &settings)
+	return nil
+}
+
+func (p *Plugin) handleSetValueSource(event *streamdeck.EvSendToPlugin, sdpi *evSdpiCollection) error {
+	settings, err := p.am.getSettings(event.Context)
+	if err != nil {
+		return fmt.Errorf("handleSetValueSource getSettings: %v", err)
+	}
+	settings.ValueSource = sdpi.Value
+	err = p.sd.SetSettings(event.Context, &settings)
+	if err != nil {
+		return fmt.Errorf("handleSetValueSource SetSettings: %v", err)
+	}
+	p.am.SetAction(event.Action, event.Context, &settings)
+	return nil
+}
+
 func parseSnoozeDurations(sdpi *evSdpiCollection) []int {
 	values := make([]int, 0, len(sdpi.Selection))
 	for _, raw := range sdpi.Selection {
@@ -327,6 +370,122 @@ var (
 	colorCache   = make(map[string]*color.RGBA)
 )
 
+// graphAutoScale maps an autoScale setting to the graph's mode; anything
+// unknown plots between min and max.
+func graphAutoScale(mode string) graph.AutoScale {
+	switch mode {
+	case "window":
+		return graph.AutoScaleWindow
+	case "start":
+		return graph.AutoScaleSinceStart
+	default:
+		return graph.AutoScaleOff
+	}
+}
+
+// graphStyle maps a graphStyle setting to the graph's style; anything unknown
+// draws a filled area.
+func graphStyle(style string) graph.Style {
+	switch style {
+	case "line":
+		return graph.StyleLine
+	case "step":
+		return graph.StyleStep
+	case "bars":
+		return graph.StyleBars
+	case "dots":
+		return graph.StyleDots
+	default:
+		return graph.StyleArea
+	}
+}
+
+// graphScale maps a graphScale setting to the graph's scale; anything unknown
+// is linear.
+func graphScale(scale string) graph.Scale {
+	switch scale {
+	case "log":
+		return graph.ScaleLog10
+	case "symmetric":
+		return graph.ScaleSymmetric
+	default:
+		return graph.ScaleLinear
+	}
+}
+
+// graphTimeWindow parses a graphWindow setting; anything unparseable, like
+// the empty default, plots one column per sample.
+func graphTimeWindow(s string) time.Duration {
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
+
+// thresholdMarkers returns a reference line for each enabled threshold, with
+// the hysteresis the value must clear by as its band. toGraph maps threshold
+// values into the graph's unit (nil when they already match); thresholds
+// without a highlight color use fallback.
+func thresholdMarkers(thresholds []Threshold, toGraph func(float64) float64, fallback color.RGBA) []graph.Marker {
+	if toGraph == nil {
+		toGraph = func(v float64) float64 { return v }
+	}
+	var markers []graph.Marker
+	for i := range thresholds {
+		t := &thresholds[i]
+		if !t.Enabled || t.Operator == "" {
+			continue
+		}
+		lo, hi := t.Value, t.Value
+		if h := t.Hysteresis; h > 0 {
+			switch t.Operator {
+			case ">", ">=":
+				lo -= h
+			case "<", "<=":
+				hi += h
+			case "==":
+				lo, hi = lo-h, hi+h
+			}
+		}
+		m := graph.Marker{Value: toGraph(t.Value), Lo: toGraph(lo), Hi: toGraph(hi), Color: fallback}
+		if m.Lo > m.Hi {
+			m.Lo, m.Hi = m.Hi, m.Lo
+		}
+		if t.HighlightColor != "" {
+			m.Color = *hexToRGBA(t.HighlightColor)
+		}
+		markers = append(markers, m)
+	}
+	return markers
+}
+
+// parseGradient parses the gradient stops of a graphGradient setting, written
+// as "value:#rrggbb" pairs separated by commas, e.g. "40:#00ff00, 95:#ff0000".
+// Malformed stops are skipped; fewer than two stops mean no gradient.
+func parseGradient(s string) []graph.GradientStop {
+	var stops []graph.GradientStop
+	for _, part := range strings.Split(s, ",") {
+		value, hex, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok {
+			continue
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		hex = strings.TrimSpace(hex)
+		if err != nil || len(hex) != 7 || hex[0] != '#' {
+			continue
+		}
+		if _, err := strconv.ParseUint(hex[1:], 16, 32); err != nil {
+			continue
+		}
+		stops = append(stops, graph.GradientStop{Value: v, Color: *hexToRGBA(hex)})
+	}
+	if len(stops) < 2 {
+		return nil
+	}
+	return stops
+}
+
 func hexToRGBA(hex string) *color.RGBA {
 	colorCacheMu.RLock()
 	if c, ok := colorCache[hex]; ok {
@@ -624,6 +783,28 @@ func (p *Plugin) handleGraphVisuals(event *streamdeck.EvSendToPlugin, sdpi *evSd
 			settings.GraphLineThickness = v
 			g.SetLineThickness(v)
 		}
+	case "autoScale":
+		settings.AutoScale = sdpi.Value
+		g.SetAutoScale(graphAutoScale(sdpi.Value))
+	case "graphScale":
+		settings.GraphScale = sdpi.Value
+		g.SetScale(graphScale(sdpi.Value))
+	case "logFloor":
+		if v, err2 := strconv.ParseFloat(sdpi.Value, 64); err2 == nil && v >= 0 {
+			settings.LogFloor = v
+			g.SetLogFloor(v)
+		}
+	case "graphWindow":
+		settings.GraphWindow = sdpi.Value
+		g.SetTimeWindow(graphTimeWindow(sdpi.Value))
+	case "graphGradient":
+		settings.GraphGradient = sdpi.Value
+		g.SetGradient(parseGradient(sdpi.Value))
+	case "graphStyle":
+		settings.GraphStyle = sdpi.Value
+		g.SetStyle(graphStyle(sdpi.Value))
+	case "showThresholdLines":
+		settings.ShowThresholdLines = sdpi.Checked
 	case "textStroke":
 		settings.TextStroke = sdpi.Checked
 		g.SetTextStroke(sdpi.Checked)
@@ -680,6 +861,7 @@ func (p *Plugin) applyNormalColors(g *graph.Graph, s *actionSettings) {
 	} else {
 		g.SetLabelColor(2, &color.RGBA{255, 255, 255, 255})
 	}
+	g.SetGradient(parseGradient(s.GraphGradient))
 }
 
 // ============================================================================
@@ -729,6 +911,7 @@ func (p *Plugin) handleAddThreshold(event *streamdeck.EvSendToPlugin, sdpi *evSd
 		ForegroundColor: fg,
 		HighlightColor:  hl,
 		ValueTextColor:  vt,
+		Unit:            thresholdUnit(settings.DisplayUnit, p.temperatureUnit()),
 	}
 
 	settings.Thresholds = append(settings.Thresholds, newThreshold)
@@ -937,7 +1120,9 @@ func (p *Plugin) applyThresholdColors(g *graph.Graph, t *Threshold) {
 		g.SetBackgroundColor(hexToRGBA(t.BackgroundColor))
 	}
 	if t.ForegroundColor != "" {
+		// The alert's fill wins over a gradient until the alert clears.
 		g.SetForegroundColor(hexToRGBA(t.ForegroundColor))
+		g.SetGradient(nil)
 	}
 	if t.HighlightColor != "" {
 		g.SetHighlightColor(hexToRGBA(t.HighlightColor))
@@ -972,13 +1157,12 @@ func (p *Plugin) resolveThresholdsForEval(local []Threshold, suppressed []string
 	if len(globals) == 0 {
 		return local
 	}
-	rtStr := readingType.String()
 	result := make([]Threshold, len(local), len(local)+len(globals))
 	copy(result, local)
 outer:
 	for i := range globals {
 		gt := globals[i]
-		if gt.ReadingType != "" && gt.ReadingType != rtStr {
+		if !readingType.Matches(gt.ReadingType) {
 			continue
 		}
 		for _, sid := range suppressed {
@@ -1085,6 +1269,13 @@ func (p *Plugin) handleGlobalThresholdUpdate(id, field, value string, checked bo
 		p.mu.Unlock()
 		return
 	}
+	// Globals scoped to temperatures are entered in the plugin-wide unit;
+	// other globals keep °C for the temperatures they happen to match.
+	tempType := hwsensorsservice.ReadingTypeTemp.String()
+	tempUnit, _ := lookupTemperatureUnit(p.globalSettings.TemperatureUnit)
+	if t.ReadingType == tempType {
+		convertThreshold(t, tempUnit, false)
+	}
 	switch field {
 	case "thresholdEnabled":
 		t.Enabled = checked
@@ -1118,6 +1309,9 @@ func (p *Plugin) handleGlobalThresholdUpdate(id, field, value string, checked bo
 		t.ValueTextColor = value
 	case "thresholdReadingType":
 		t.ReadingType = value
+		if value == tempType {
+			convertThreshold(t, tempUnit, false)
+		}
 	}
 	gs := p.globalSettings
 	p.mu.Unlock()
@@ -1,8 +1,10 @@
 package lhmstreamdeckplugin
 
 import (
+	"encoding/json"
 	"image/color"
 	"testing"
+	"time"
 
 	"github.com/moeilijk/lhm-streamdeck/pkg/graph"
 	hwsensorsservice "github.com/moeilijk/lhm-streamdeck/pkg/service"
@@ -92,6 +94,64 @@ func TestRuntimeForSourceReconcilesLoadedSourceProfile(t *testing.T) {
 	}
 }
 
+func TestSourceProfileCredentialsAreMaskedForPropertyInspector(t *testing.T) {
+	stored := []lhmSourceProfile{
+		{ID: "lhm", Host: "10.0.0.2", Port: 8085, Username: "admin", Password: "hunter2"},
+		{ID: "companion", Host: "10.0.0.3", Port: 8085, Token: "s3cret"},
+		{ID: "open", Host: "10.0.0.4", Port: 8085},
+	}
+
+	masked := maskSourceProfiles(stored)
+	if masked[0].Username != "admin" || masked[0].Password != credentialMask {
+		t.Fatalf("basic auth profile = %+v, want username kept and password masked", masked[0])
+	}
+	if masked[1].Token != credentialMask {
+		t.Fatalf("token profile = %+v, want token masked", masked[1])
+	}
+	if masked[2].Password != "" || masked[2].Token != "" {
+		t.Fatalf("profile without credentials = %+v, want no mask", masked[2])
+	}
+	if stored[0].Password != "hunter2" || stored[1].Token != "s3cret" {
+		t.Fatal("masking modified the stored profiles")
+	}
+
+	if got := unmaskCredential("hunter2", credentialMask); got != "hunter2" {
+		t.Fatalf("unchanged mask = %q, want stored secret", got)
+	}
+	if got := unmaskCredential("hunter2", "correct horse"); got != "correct horse" {
+		t.Fatalf("new password = %q, want submitted value", got)
+	}
+	if got := unmaskCredential("hunter2", ""); got != "" {
+		t.Fatalf("cleared password = %q, want empty", got)
+	}
+
+	changed := stored[0]
+	changed.Password = "correct horse"
+	if sameSourceProfileEndpoint(stored[0], changed) {
+		t.Fatal("a password change must restart the source")
+	}
+}
+
+func TestProfileEndpoint(t *testing.T) {
+	cases := []struct {
+		prof lhmSourceProfile
+		want string
+	}{
+		{lhmSourceProfile{}, "http://127.0.0.1:8085/data.json"},
+		{lhmSourceProfile{Host: "10.0.0.2", Port: 9000}, "http://10.0.0.2:9000/data.json"},
+		{lhmSourceProfile{Host: "fd00::2", Port: 8085}, "http://[fd00::2]:8085/data.json"},
+		{lhmSourceProfile{Host: "[fd00::2]", Port: 8085}, "http://[fd00::2]:8085/data.json"},
+		{lhmSourceProfile{Host: "lhm.example.org", Port: 443, Scheme: "HTTPS", Path: "lhm/data.json"}, "https://lhm.example.org:443/lhm/data.json"},
+		{lhmSourceProfile{Host: "lhm.example.org", Port: 8443, Scheme: "https", Path: "/proxy/host one/data.json"}, "https://lhm.example.org:8443/proxy/host%20one/data.json"},
+		{lhmSourceProfile{Host: "10.0.0.2", Port: 8085, Scheme: "ftp"}, "http://10.0.0.2:8085/data.json"},
+	}
+	for _, tc := range cases {
+		if got := profileEndpoint(tc.prof); got != tc.want {
+			t.Errorf("profileEndpoint(%+v) = %q, want %q", tc.prof, got, tc.want)
+		}
+	}
+}
+
 type stubHardwareService struct {
 	readingsBySensor map[string][]hwsensorsservice.Reading
 }
@@ -107,3 +167,109 @@ func (s stubHardwareService) Sensors() ([]hwsensorsservice.Sensor, error) {
 func (s stubHardwareService) ReadingsForSensorID(id string) ([]hwsensorsservice.Reading, error) {
 	return s.readingsBySensor[id], nil
 }
+
+func (s stubHardwareService) Snapshot(pollTime uint64) (*hwsensorsservice.Snapshot, error) {
+	return &hwsensorsservice.Snapshot{PollTime: pollTime, Readings: s.readingsBySensor}, nil
+}
+
+func TestDialPageAutoScaleFollowsSettings(t *testing.T) {
+	page := actionSettings{Min: 0, Max: 100, AutoScale: "window", GraphScale: "symmetric"}
+	g := newDialGraph(&page)
+	for _, v := range []float64{-5, 12, 3} {
+		g.Update(v)
+	}
+	if lo, hi := g.Range(); lo != -12 || hi != 12 {
+		t.Fatalf("symmetric Range() = %v..%v, want -12..12", lo, hi)
+	}
+
+	page.AutoScale, page.GraphScale = "", ""
+	applyDialGraphSettings(g, &page)
+	if lo, hi := g.Range(); lo != 0 || hi != 100 {
+		t.Fatalf("Range() after turning auto scale off = %v..%v, want 0..100", lo, hi)
+	}
+	if got := len(g.Samples()); got != 3 {
+		t.Fatalf("history has %d samples after the scale change, want 3", got)
+	}
+}
+
+func TestSymmetricAutoScaleMigratesToScale(t *testing.T) {
+	raw := json.RawMessage(`{"autoScale":"symmetric"}`)
+	s, migrated, err := decodeActionSettings(&raw)
+	if err != nil || !migrated {
+		t.Fatalf("decodeActionSettings = migrated %v, err %v", migrated, err)
+	}
+	if s.AutoScale != "window" || s.GraphScale != "symmetric" {
+		t.Fatalf("migrated to autoScale %q graphScale %q, want window/symmetric", s.AutoScale, s.GraphScale)
+	}
+
+	raw = json.RawMessage(`{"pages":[{"autoScale":"symmetric","graphScale":"log"}]}`)
+	d, err := decodeDialSettings(&raw)
+	if err != nil {
+		t.Fatalf("decodeDialSettings: %v", err)
+	}
+	if p := d.Pages[0]; p.AutoScale != "window" || p.GraphScale != "log" {
+		t.Fatalf("dial page migrated to %q/%q, want window with its log scale kept", p.AutoScale, p.GraphScale)
+	}
+}
+
+func TestGraphTimeWindow(t *testing.T) {
+	for in, want := range map[string]time.Duration{
+		"":     0,
+		"5m":   5 * time.Minute,
+		"2h":   2 * time.Hour,
+		"-1m":  0,
+		"junk": 0,
+	} {
+		if got := graphTimeWindow(in); got != want {
+			t.Errorf("graphTimeWindow(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestThresholdMarkers(t *testing.T) {
+	hl := color.RGBA{1, 2, 3, 255}
+	thresholds := []Threshold{
+		{Enabled: true, Operator: ">=", Value: 80, Hysteresis: 5, HighlightColor: "#ff0000"},
+		{Enabled: true, Operator: "<", Value: 10, Hysteresis: 2},
+		{Enabled: false, Operator: ">", Value: 90},
+		{Enabled: true, Operator: "==", Value: 50},
+	}
+	// Thresholds in bytes on a graph plotted in kilobytes.
+	kb := func(v float64) float64 { return v / 1024 }
+	got := thresholdMarkers(thresholds, kb, hl)
+	want := []graph.Marker{
+		{Value: 80.0 / 1024, Lo: 75.0 / 1024, Hi: 80.0 / 1024, Color: color.RGBA{255, 0, 0, 255}},
+		{Value: 10.0 / 1024, Lo: 10.0 / 1024, Hi: 12.0 / 1024, Color: hl},
+		{Value: 50.0 / 1024, Lo: 50.0 / 1024, Hi: 50.0 / 1024, Color: hl},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("thresholdMarkers() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("marker %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestParseGradient(t *testing.T) {
+	got := parseGradient(" 40:#00ff00, 70 : #FFBF00,junk, 95:#ff0000, 99:red")
+	want := []graph.GradientStop{
+		{Value: 40, Color: color.RGBA{0, 255, 0, 255}},
+		{Value: 70, Color: color.RGBA{255, 191, 0, 255}},
+		{Value: 95, Color: color.RGBA{255, 0, 0, 255}},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("parseGradient() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("stop %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	for _, s := range []string{"", "40:#00ff00", "40:#00ff00, x:#ff0000"} {
+		if stops := parseGradient(s); stops != nil {
+			t.Errorf("parseGradient(%q) = %+v, want no gradient", s, stops)
+		}
+	}
+}
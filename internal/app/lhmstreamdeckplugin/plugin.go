@@ -2,14 +2,21 @@ package lhmstreamdeckplugin
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"image"
 	"image/color"
 	"image/draw"
 	"image/png"
+	"io"
 	"log"
+	"net"
+	"net/url"
 	"os"
 	"os/exec"
+	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -26,16 +33,28 @@ import (
 )
 
 // sourceRuntime holds the bridge process and gRPC client for a single LHM source profile.
-// Poll time cache is protected by Plugin.mu; c/hw/peg are protected by the runtime's own mu.
+// Poll time cache and snapshot are protected by Plugin.mu; c/hw/peg/subCancel are
+// protected by the runtime's own mu.
 type sourceRuntime struct {
-	profile lhmSourceProfile
-	mu      sync.RWMutex
-	c       *plugin.Client
-	hw      hwsensorsservice.HardwareService
-	peg     processExitGroup
+	profile   lhmSourceProfile
+	mu        sync.RWMutex
+	c         *plugin.Client
+	hw        hwsensorsservice.HardwareService
+	peg       processExitGroup
+	subCancel context.CancelFunc // stops the snapshot subscription, nil when polling
 	// poll time cache — accessed under Plugin.mu
 	cachedPollTime uint64
 	cachedAt       time.Time
+	// snapshot every tile reads from during the current tick — accessed under
+	// Plugin.mu. pushed is set while the bridge streams snapshots; otherwise
+	// one snapshot is fetched per poll time, serialized by fetchMu.
+	snapshot *hwsensorsservice.Snapshot
+	pushed   bool
+	fetchMu  sync.Mutex
+	// readings of snapshot indexed for lookups — accessed under Plugin.mu
+	view *sourceView
+	// consecutive failures and circuit state — accessed under Plugin.mu
+	health sourceHealth
 }
 
 // Plugin handles information between Libre Hardware Monitor and Stream Deck
@@ -46,17 +65,28 @@ type Plugin struct {
 	sources map[string]*sourceRuntime
 	sd      *streamdeck.StreamDeck
 	am      *actionManager
+	render  *renderPool
 	graphs  map[string]*graph.Graph
 
+	transport string      // host application, see detectTransport
+	frames    frameCache  // last frame sent per context
+	history   tileHistory // last graph values per context, kept across restarts
+
+	deviceRatios map[string]int    // smooth key pixel ratio per device ID
+	keyDevices   map[string]string // device ID per key context
+
 	// Cached assets and state for performance
 	placeholderImage []byte               // cached startup chip placeholder image (set once at init, read-only after)
 	lastPollTime     map[string]uint64    // last processed PollTime per context
 	lastRenderTime   map[string]time.Time // wall-time of last render per context (for per-tile interval override)
+	unavailableText  map[string]string    // text drawn on each tile currently showing a status image
 	smoothedValues   map[string]float64   // last smoothed graph value per context (for EMA)
 	divisorCache     map[string]divisorCacheEntry
 	thresholdStates  map[string]map[string]*thresholdRuntimeState
 	thresholdSnoozes map[string]*thresholdSnoozeState
 	thresholdDirty   map[string]bool
+	parkedThresholds map[string]*savedThresholdContext // threshold state of contexts not on screen
+	thresholdFile    stateFile
 
 	// Global settings
 	globalSettings   globalSettings                   // plugin-wide settings (poll interval)
@@ -88,10 +118,15 @@ type divisorCacheEntry struct {
 
 const defaultPollInterval = time.Second
 const settingsTitleFontSize = 9.0
+const settingsHealthFontSize = 8.0
+const groupMemberFontSize = 7.0
 const defaultThresholdHysteresis = 1.0
 const defaultThresholdDwellMs = int(defaultPollInterval / time.Millisecond)
 const defaultThresholdCooldownMs = int((5 * defaultPollInterval) / time.Millisecond)
 
+// settingsHealthColor draws the source state line on settings tiles.
+var settingsHealthColor = color.RGBA{255, 96, 96, 255}
+
 func pollTimeCacheTTLForInterval(interval time.Duration) time.Duration {
 	if interval <= 0 {
 		interval = defaultPollInterval
@@ -114,7 +149,7 @@ func pollTimeCacheTTLForInterval(interval time.Duration) time.Duration {
 
 // profileEndpoint builds the LHM endpoint URL for a source profile.
 func profileEndpoint(prof lhmSourceProfile) string {
-	host := prof.Host
+	host := strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(prof.Host), "["), "]")
 	port := prof.Port
 	if host == "" {
 		host = "127.0.0.1"
@@ -122,7 +157,18 @@ func profileEndpoint(prof lhmSourceProfile) string {
 	if port <= 0 || port > 65535 {
 		port = 8085
 	}
-	return fmt.Sprintf("http://%s:%d/data.json", host, port)
+	scheme := strings.ToLower(prof.Scheme)
+	if scheme != "https" {
+		scheme = "http"
+	}
+	path := strings.TrimSpace(prof.Path)
+	if path == "" {
+		path = "/data.json"
+	} else if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	u := url.URL{Scheme: scheme, Host: net.JoinHostPort(host, strconv.Itoa(port)), Path: path}
+	return u.String()
 }
 
 // resolvedSourceProfileID returns the effective profile ID for a tile,
@@ -204,8 +250,16 @@ func (p *Plugin) sourceProfileByID(profileID string) (lhmSourceProfile, bool) {
 	return lhmSourceProfile{}, false
 }
 
+// sameSourceProfileEndpoint reports whether two profiles reach the same data
+// source the same way, or are groups over the same members in the same order;
+// only the display name may differ.
 func sameSourceProfileEndpoint(a, b lhmSourceProfile) bool {
-	return a.ID == b.ID && a.Host == b.Host && a.Port == b.Port
+	if !slices.Equal(a.Members, b.Members) {
+		return false
+	}
+	a.Name, b.Name = "", ""
+	a.Members, b.Members = nil, nil
+	return reflect.DeepEqual(a, b)
 }
 
 func (p *Plugin) reconcileSourceRuntime(profileID string, rt *sourceRuntime) {
@@ -217,18 +271,15 @@ func (p *Plugin) reconcileSourceRuntime(profileID string, rt *sourceRuntime) {
 	rt.mu.Lock()
 	changed := !sameSourceProfileEndpoint(rt.profile, prof)
 	if changed {
-		if rt.c != nil {
-			rt.c.Kill()
-		}
+		stopSourceClientLocked(rt)
 		rt.profile = prof
-		rt.c = nil
-		rt.hw = nil
 	}
 	rt.mu.Unlock()
 
 	if changed {
 		p.mu.Lock()
 		invalidatePollCacheForRuntime(rt)
+		rt.health = sourceHealth{}
 		p.mu.Unlock()
 	}
 }
@@ -240,13 +291,50 @@ func bridgeBinaryName() string {
 	return "./lhm-bridge"
 }
 
-// startSourceClientLocked starts the bridge for rt. Caller must hold rt.mu write lock.
+// startSourceClientLocked starts the bridge for rt and subscribes to its
+// snapshots. Caller must hold rt.mu write lock.
 func (p *Plugin) startSourceClientLocked(rt *sourceRuntime) error {
+	stopSubscriptionLocked(rt)
+	p.mu.Lock()
+	invalidatePollCacheForRuntime(rt)
+	p.mu.Unlock()
+	if err := p.connectSourceLocked(rt); err != nil {
+		return err
+	}
+	p.subscribeSourceLocked(rt)
+	return nil
+}
+
+// connectSourceLocked wires rt.hw to the profile's data source. Caller must hold rt.mu write lock.
+func (p *Plugin) connectSourceLocked(rt *sourceRuntime) error {
+	if rt.profile.Type == sourceTypeGroup {
+		rt.hw = newSourceGroupService(p, rt)
+		return nil
+	}
 	if runtime.GOOS == "linux" {
 		return startLinuxSource(rt)
 	}
+	if rt.profile.Type == sourceTypeHwmon {
+		return fmt.Errorf("local hwmon source is only available on Linux")
+	}
 	cmd := exec.Command(bridgeBinaryName())
-	cmd.Env = append(os.Environ(), "LHM_ENDPOINT="+profileEndpoint(rt.profile))
+	cmd.Env = append(os.Environ(),
+		"LHM_ENDPOINT="+profileEndpoint(rt.profile),
+		"LHM_USERNAME="+rt.profile.Username,
+		"LHM_PASSWORD="+rt.profile.Password,
+		"LHM_TOKEN="+rt.profile.Token,
+		"LHM_CA_FILE="+rt.profile.CAFile,
+		"LHM_TLS_SKIP_VERIFY="+strconv.FormatBool(rt.profile.InsecureSkipVerify),
+		"LHM_STAT_WINDOWS="+rt.profile.StatWindows,
+	)
+	if rt.profile.Type == sourceTypeReplay {
+		cmd.Env = append(cmd.Env,
+			"LHM_REPLAY_FILE="+rt.profile.ReplayFile,
+			"LHM_REPLAY_SPEED="+strconv.FormatFloat(rt.profile.ReplaySpeed, 'f', -1, 64),
+		)
+	} else {
+		cmd.Env = append(cmd.Env, "LHM_RECORD_DIR="+rt.profile.RecordDir)
+	}
 
 	client := plugin.NewClient(&plugin.ClientConfig{
 		HandshakeConfig:  hwsensorsservice.Handshake,
@@ -278,13 +366,142 @@ func (p *Plugin) startSourceClientLocked(rt *sourceRuntime) error {
 	return nil
 }
 
+// subscribeSourceLocked starts consuming pushed snapshots when rt.hw supports
+// it. Caller must hold rt.mu write lock.
+func (p *Plugin) subscribeSourceLocked(rt *sourceRuntime) {
+	sub, ok := rt.hw.(hwsensorsservice.Subscriber)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	rt.subCancel = cancel
+	go p.consumeSnapshots(ctx, rt, rt.profile.ID, sub, p.am.GetInterval())
+}
+
+// stopSourceClientLocked stops rt's subscription, kills its bridge and closes
+// an in-process service, e.g. to finish its recording, leaving rt without a
+// client. Caller must hold rt.mu write lock.
+func stopSourceClientLocked(rt *sourceRuntime) {
+	stopSubscriptionLocked(rt)
+	if rt.c != nil {
+		rt.c.Kill()
+	} else if c, ok := rt.hw.(io.Closer); ok {
+		if err := c.Close(); err != nil {
+			log.Printf("source %s: close: %v\n", rt.profile.ID, err)
+		}
+	}
+	rt.c = nil
+	rt.hw = nil
+}
+
+// stopSubscriptionLocked cancels rt's snapshot subscription, if any. The
+// pushed snapshot itself is dropped by invalidatePollCacheForRuntime.
+// Caller must hold rt.mu write lock.
+func stopSubscriptionLocked(rt *sourceRuntime) {
+	if rt.subCancel != nil {
+		rt.subCancel()
+		rt.subCancel = nil
+	}
+}
+
+const subscribeRetryMax = 30 * time.Second
+
+// subscribeRetryBase is the first delay before resubscribing after a stream
+// ended; tests shorten it.
+var subscribeRetryBase = time.Second
+
+// subscribeRetryDelay returns the delay before the n-th consecutive attempt
+// to resubscribe, doubling from subscribeRetryBase up to subscribeRetryMax.
+func subscribeRetryDelay(n int) time.Duration {
+	d := subscribeRetryBase
+	for i := 1; i < n && d < subscribeRetryMax; i++ {
+		d *= 2
+	}
+	return min(d, subscribeRetryMax)
+}
+
+// consumeSnapshots stores every pushed snapshot on rt until ctx is cancelled.
+// When the stream ends the runtime polls while the subscription is retried
+// with backoff. Bridges that predate the Subscribe RPC are polled from the
+// start. profileID is only used for logging.
+func (p *Plugin) consumeSnapshots(ctx context.Context, rt *sourceRuntime, profileID string, sub hwsensorsservice.Subscriber, interval time.Duration) {
+	for attempt := 0; ctx.Err() == nil; {
+		if attempt > 0 {
+			timer := time.NewTimer(subscribeRetryDelay(attempt))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+		ch, err := sub.Subscribe(ctx, interval)
+		if err != nil {
+			if errors.Is(err, hwsensorsservice.ErrSubscribeUnsupported) {
+				log.Printf("source %s: bridge has no Subscribe RPC, polling instead\n", profileID)
+				return
+			}
+			if ctx.Err() == nil {
+				log.Printf("source %s: Subscribe failed, polling until it is retried: %v\n", profileID, err)
+			}
+			attempt++
+			continue
+		}
+		received := false
+		for snap := range ch {
+			p.mu.Lock()
+			live := ctx.Err() == nil
+			if live {
+				rt.snapshot = snap
+				rt.pushed = true
+			}
+			p.mu.Unlock()
+			if live {
+				received = true
+				p.recordSourceFetch(rt, snap.Err)
+			}
+		}
+		p.mu.Lock()
+		if ctx.Err() == nil {
+			rt.snapshot = nil
+			rt.pushed = false
+		}
+		p.mu.Unlock()
+		if received {
+			attempt = 0
+		}
+		attempt++
+		if ctx.Err() == nil {
+			log.Printf("source %s: snapshot stream ended, polling until it is resubscribed\n", profileID)
+		}
+	}
+}
+
+// resubscribeSources restarts every snapshot subscription, e.g. so the
+// bridges pick up a new poll interval.
+func (p *Plugin) resubscribeSources() {
+	p.sourceMu.RLock()
+	rts := make([]*sourceRuntime, 0, len(p.sources))
+	for _, rt := range p.sources {
+		rts = append(rts, rt)
+	}
+	p.sourceMu.RUnlock()
+
+	for _, rt := range rts {
+		rt.mu.Lock()
+		if rt.subCancel != nil {
+			stopSubscriptionLocked(rt)
+			p.subscribeSourceLocked(rt)
+		}
+		rt.mu.Unlock()
+	}
+}
+
 // restartSource kills and restarts the bridge for a profile.
 func (p *Plugin) restartSource(rt *sourceRuntime) {
 	rt.mu.Lock()
 	defer rt.mu.Unlock()
-	if rt.c != nil {
-		rt.c.Kill()
-	}
+	stopSourceClientLocked(rt)
 	_ = p.startSourceClientLocked(rt)
 }
 
@@ -297,6 +514,9 @@ func (p *Plugin) sensorsWithTimeoutForSource(profileID string, d time.Duration)
 	if hw == nil {
 		return nil, fmt.Errorf("LHM bridge not ready")
 	}
+	if snap := p.currentSnapshot(rt); snap != nil && snap.Err == nil && !snap.Lazy() {
+		return snap.Sensors, nil
+	}
 	ch := make(chan sensorResult, 1)
 	go func() {
 		s, err := hw.Sensors()
@@ -319,26 +539,125 @@ func (p *Plugin) sensorsWithTimeout(d time.Duration) ([]hwsensorsservice.Sensor,
 
 // getReadingForSource fetches a reading from the given profile's bridge.
 func (p *Plugin) getReadingForSource(profileID, suid string, rid int32) (hwsensorsservice.Reading, []hwsensorsservice.Reading, error) {
-	rt := p.runtimeForSource(profileID)
+	v, err := p.viewForSource(profileID)
+	if err != nil {
+		return nil, nil, err
+	}
+	r, err := v.reading(suid, rid)
+	if err != nil {
+		return nil, nil, err
+	}
+	rs, _ := v.snap.ReadingsForSensorID(suid)
+	return r, rs, nil
+}
+
+// snapshotForSource returns the snapshot all tiles of a profile read from in
+// the current tick: the latest pushed one, or one fetched per poll time so 50
+// tiles cost a single Snapshot call instead of one ReadingsForSensorID each.
+func (p *Plugin) snapshotForSource(profileID string) (*hwsensorsservice.Snapshot, error) {
+	return p.snapshotForRuntime(p.runtimeForSource(profileID))
+}
+
+// snapshotForRuntime is snapshotForSource for a runtime already looked up.
+func (p *Plugin) snapshotForRuntime(rt *sourceRuntime) (*hwsensorsservice.Snapshot, error) {
 	rt.mu.RLock()
 	hw := rt.hw
 	rt.mu.RUnlock()
 	if hw == nil {
-		return nil, nil, fmt.Errorf("LHM bridge not ready")
+		return nil, fmt.Errorf("LHM bridge not ready")
 	}
-	rbs, err := hw.ReadingsForSensorID(suid)
+	if snap := p.pushedSnapshot(rt); snap != nil {
+		if snap.Err != nil {
+			return nil, snap.Err
+		}
+		return snap, nil
+	}
+	// A fresh PollTime only counts as a good fetch together with the
+	// snapshot it leads to; recording it on its own would reset the circuit
+	// breaker every tick while Snapshot keeps failing.
+	pollTime, fetched, err := p.refreshPollTime(rt, hw)
 	if err != nil {
-		return nil, nil, fmt.Errorf("getReading ReadingsBySensor failed: %v", err)
+		if fetched {
+			p.recordSourceFetch(rt, err)
+		}
+		return nil, err
 	}
-	for _, r := range rbs {
-		if r.ID() == rid {
-			return r, rbs, nil
+
+	rt.fetchMu.Lock()
+	defer rt.fetchMu.Unlock()
+	if snap := p.currentSnapshot(rt); snap != nil && snap.PollTime >= pollTime {
+		if fetched {
+			p.recordSourceFetch(rt, nil)
 		}
+		return snap, nil
+	}
+	// A fetched PollTime already passed the circuit breaker (and claimed
+	// the probe while it is open); a cached one has to ask.
+	if !fetched {
+		if err := p.allowSourceFetch(rt); err != nil {
+			return nil, err
+		}
+	}
+	snap, err := hw.Snapshot(pollTime)
+	if err == nil && snap.Err != nil {
+		err = snap.Err
+	}
+	p.recordSourceFetch(rt, err)
+	if err != nil {
+		// Tiles asking before the poll time cache expires get the source's
+		// error instead of fetching again.
+		p.mu.Lock()
+		rt.cachedPollTime = 0
+		rt.cachedAt = time.Now()
+		p.mu.Unlock()
+		return nil, err
 	}
-	return nil, rbs, fmt.Errorf("ReadingID does not exist: %s", suid)
+	p.mu.Lock()
+	if !rt.pushed {
+		rt.snapshot = snap
+	}
+	p.mu.Unlock()
+	return snap, nil
 }
 
-// getCachedPollTimeForSource returns the cached poll time for a profile.
+// currentSnapshot returns the latest snapshot of rt, pushed or fetched, or nil
+// when there is none yet.
+func (p *Plugin) currentSnapshot(rt *sourceRuntime) *hwsensorsservice.Snapshot {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return rt.snapshot
+}
+
+// pushedSnapshot returns the latest snapshot pushed for rt, or nil when the
+// runtime is polling.
+func (p *Plugin) pushedSnapshot(rt *sourceRuntime) *hwsensorsservice.Snapshot {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if !rt.pushed {
+		return nil
+	}
+	return rt.snapshot
+}
+
+// pollTimeStale reports whether a source's poll time is too old to render.
+// A pushed snapshot can be up to one poll interval old when a tile ticks, so
+// slow intervals get more slack than the default five seconds.
+func (p *Plugin) pollTimeStale(pollTime uint64) bool {
+	if pollTime == 0 {
+		return true
+	}
+	maxAge := 5 * time.Second
+	if p.am != nil {
+		if d := 2 * p.am.GetInterval(); d > maxAge {
+			maxAge = d
+		}
+	}
+	return time.Since(time.Unix(0, int64(pollTime))) > maxAge
+}
+
+// getCachedPollTimeForSource returns the cached poll time for a profile. While
+// the bridge pushes snapshots this is the poll time of the latest one and no
+// RPC is made.
 func (p *Plugin) getCachedPollTimeForSource(profileID string) (uint64, error) {
 	rt := p.runtimeForSource(profileID)
 	rt.mu.RLock()
@@ -347,6 +666,28 @@ func (p *Plugin) getCachedPollTimeForSource(profileID string) (uint64, error) {
 	if hw == nil {
 		return 0, fmt.Errorf("LHM bridge not ready")
 	}
+	return p.pollTimeForRuntime(rt, hw)
+}
+
+// pollTimeForRuntime returns the cached poll time of rt, asking hw only once
+// the cache has expired and the source's circuit is not open.
+func (p *Plugin) pollTimeForRuntime(rt *sourceRuntime, hw hwsensorsservice.HardwareService) (uint64, error) {
+	pollTime, fetched, err := p.refreshPollTime(rt, hw)
+	if fetched {
+		p.recordSourceFetch(rt, err)
+	}
+	return pollTime, err
+}
+
+// refreshPollTime is pollTimeForRuntime without recording the outcome in the
+// source's health; fetched reports whether hw was asked.
+func (p *Plugin) refreshPollTime(rt *sourceRuntime, hw hwsensorsservice.HardwareService) (pollTime uint64, fetched bool, err error) {
+	if snap := p.pushedSnapshot(rt); snap != nil {
+		if snap.Err != nil {
+			return 0, false, snap.Err
+		}
+		return snap.PollTime, false, nil
+	}
 
 	p.mu.RLock()
 	cacheTTL := p.pollTimeCacheTTL
@@ -355,32 +696,41 @@ func (p *Plugin) getCachedPollTimeForSource(profileID string) (uint64, error) {
 	}
 	if !rt.cachedAt.IsZero() && time.Since(rt.cachedAt) < cacheTTL {
 		pt := rt.cachedPollTime
+		if pt == 0 {
+			err = rt.health.err()
+		}
 		p.mu.RUnlock()
-		return pt, nil
+		return pt, false, err
 	}
 	p.mu.RUnlock()
 
-	pollTime, err := hw.PollTime()
+	if err := p.allowSourceFetch(rt); err != nil {
+		return 0, false, err
+	}
+	pollTime, err = hw.PollTime()
 
 	p.mu.Lock()
 	if err != nil {
 		rt.cachedPollTime = 0
 		rt.cachedAt = time.Now()
 		p.mu.Unlock()
-		return 0, err
+		return 0, true, err
 	}
 	rt.cachedPollTime = pollTime
 	rt.cachedAt = time.Now()
 	p.mu.Unlock()
 
-	return pollTime, nil
+	return pollTime, true, nil
 }
 
-// invalidatePollCacheForSource clears the poll time cache for a profile.
+// invalidatePollCacheForSource clears the poll time cache and snapshot for a profile.
 // Caller must hold p.mu write lock.
 func invalidatePollCacheForRuntime(rt *sourceRuntime) {
 	rt.cachedPollTime = 0
 	rt.cachedAt = time.Time{}
+	rt.snapshot = nil
+	rt.pushed = false
+	rt.view = nil
 }
 
 // startSourceClient acquires rt.mu and starts the bridge for the given runtime.
@@ -394,15 +744,18 @@ func (p *Plugin) startSourceClient(rt *sourceRuntime) error {
 func NewPlugin(port, uuid, event, info string) (*Plugin, error) {
 	p := &Plugin{
 		am:                newActionManager(defaultPollInterval),
+		render:            newRenderPool(defaultRenderWorkers()),
 		sources:           make(map[string]*sourceRuntime),
 		graphs:            make(map[string]*graph.Graph),
 		lastPollTime:      make(map[string]uint64),
 		lastRenderTime:    make(map[string]time.Time),
+		unavailableText:   make(map[string]string),
 		smoothedValues:    make(map[string]float64),
 		divisorCache:      make(map[string]divisorCacheEntry),
 		thresholdStates:   make(map[string]map[string]*thresholdRuntimeState),
 		thresholdSnoozes:  make(map[string]*thresholdSnoozeState),
 		thresholdDirty:    make(map[string]bool),
+		parkedThresholds:  make(map[string]*savedThresholdContext),
 		pollTimeCacheTTL:  pollTimeCacheTTLForInterval(defaultPollInterval),
 		settingsContexts:  make(map[string]*settingsTileSettings),
 		compositeSettings: make(map[string]*compositeActionSettings),
@@ -411,6 +764,8 @@ func NewPlugin(port, uuid, event, info string) (*Plugin, error) {
 		derivedStates:     make(map[string]*derivedState),
 		dialSettings:      make(map[string]*dialActionSettings),
 		dialStates:        make(map[string]*dialState),
+		deviceRatios:      registeredDeviceRatios(info),
+		keyDevices:        make(map[string]string),
 	}
 
 	// Cache placeholder image at startup.
@@ -428,10 +783,43 @@ func NewPlugin(port, uuid, event, info string) (*Plugin, error) {
 
 	// Bridge starts are deferred until global settings arrive (OnDidReceiveGlobalSettings).
 	// NewPlugin does not start any bridge here.
+	p.transport = detectTransport(info)
+	p.applyPNGCompression()
+
+	// Graph history of the previous run. The age setting arrives with the
+	// global settings, so nothing is pruned until applyHistoryMaxAge runs:
+	// a longer age than the default must not lose entries in the meantime.
+	p.history.path = tileHistoryFile
+	p.history.maxAge = defaultHistoryMaxAge
+	p.history.ageUnknown = true
+	if err := p.history.load(time.Now()); err != nil {
+		log.Printf("tile history load: %v\n", err)
+	}
+	p.thresholdFile.path = thresholdStateFile
+	if err := p.loadThresholdState(time.Now()); err != nil {
+		log.Printf("threshold state load: %v\n", err)
+	}
+
 	p.sd = streamdeck.NewStreamDeck(port, uuid, event, info)
 	return p, nil
 }
 
+// stateSaveInterval is how often the state files are written while running,
+// so little is lost when the plugin is killed rather than shut down.
+const stateSaveInterval = 30 * time.Second
+
+// saveState writes the graph history and threshold state files.
+func (p *Plugin) saveState() {
+	p.saveHistory()
+	p.saveThresholdState()
+}
+
+func (p *Plugin) saveStateEvery(interval time.Duration) {
+	for range time.Tick(interval) {
+		p.saveState()
+	}
+}
+
 // RunForever starts the plugin and waits for events, indefinitely
 func (p *Plugin) RunForever() error {
 	defer func() {
@@ -443,9 +831,7 @@ func (p *Plugin) RunForever() error {
 		p.sourceMu.RUnlock()
 		for _, rt := range rts {
 			rt.mu.Lock()
-			if rt.c != nil {
-				rt.c.Kill()
-			}
+			stopSourceClientLocked(rt)
 			if rt.peg != nil {
 				_ = rt.peg.dispose()
 			}
@@ -453,8 +839,11 @@ func (p *Plugin) RunForever() error {
 		}
 	}()
 
+	defer p.saveState()
+
 	p.sd.SetDelegate(p)
-	p.am.Run(p.updateTiles, p.updateAuxTiles)
+	p.am.Run(p.render, p.updateTiles, p.updateAuxTiles)
+	go p.saveStateEvery(stateSaveInterval)
 
 	// Watch-dog: restart any bridge that has exited.
 	go func() {
@@ -472,9 +861,12 @@ func (p *Plugin) RunForever() error {
 				needsRestart := !needsStart && rt.c != nil && rt.c.Exited()
 				rt.mu.RUnlock()
 
-				if needsStart || needsRestart {
+				// A source whose circuit is open is only restarted once its
+				// backoff has elapsed, so a dead endpoint is not hammered.
+				if (needsStart || needsRestart) && !p.sourceBackingOff(rt) {
 					if err := p.startSourceClient(rt); err != nil {
 						log.Printf("startSourceClient %s failed: %v\n", rt.profile.ID, err)
+						p.recordSourceFetch(rt, err)
 					}
 				}
 			}
@@ -515,53 +907,21 @@ func (p *Plugin) applyDefaultFormatValueOnly(v float64, t hwsensorsservice.Readi
 		return fmt.Sprintf("%.0f", v)
 	case hwsensorsservice.ReadingTypeOther:
 		return fmt.Sprintf("%.0f", v)
+	case hwsensorsservice.ReadingTypeThroughput, hwsensorsservice.ReadingTypeData,
+		hwsensorsservice.ReadingTypeTiming, hwsensorsservice.ReadingTypeConductivity:
+		return fmt.Sprintf("%.1f", v)
+	case hwsensorsservice.ReadingTypeFactor:
+		return fmt.Sprintf("%.2f", v)
+	case hwsensorsservice.ReadingTypeSmallData, hwsensorsservice.ReadingTypeEnergy,
+		hwsensorsservice.ReadingTypeFlow, hwsensorsservice.ReadingTypeNoise,
+		hwsensorsservice.ReadingTypeFrequency, hwsensorsservice.ReadingTypeTimeSpan,
+		hwsensorsservice.ReadingTypeHumidity, hwsensorsservice.ReadingTypeControl,
+		hwsensorsservice.ReadingTypeLevel:
+		return fmt.Sprintf("%.0f", v)
 	}
 	return "Bad Format"
 }
 
-// normalizeForGraph converts data size values to the target unit for consistent graph scaling.
-// This prevents jumps when LHM switches units (e.g., 1000 KB/s → 1 MB/s).
-// targetUnit can be: "B", "KB", "MB", "GB", "TB" or empty (no normalization).
-func (p *Plugin) normalizeForGraph(value float64, sourceUnit string, targetUnit string) float64 {
-	if targetUnit == "" {
-		return value // no normalization
-	}
-
-	// Convert source value to bytes first
-	sourceLower := strings.ToLower(sourceUnit)
-	var bytes float64
-	switch {
-	case strings.HasPrefix(sourceLower, "tb") || strings.HasPrefix(sourceLower, "tib"):
-		bytes = value * 1024 * 1024 * 1024 * 1024
-	case strings.HasPrefix(sourceLower, "gb") || strings.HasPrefix(sourceLower, "gib"):
-		bytes = value * 1024 * 1024 * 1024
-	case strings.HasPrefix(sourceLower, "mb") || strings.HasPrefix(sourceLower, "mib"):
-		bytes = value * 1024 * 1024
-	case strings.HasPrefix(sourceLower, "kb") || strings.HasPrefix(sourceLower, "kib"):
-		bytes = value * 1024
-	case strings.HasPrefix(sourceLower, "b/") || sourceLower == "b":
-		bytes = value
-	default:
-		return value // not a data size unit, no conversion
-	}
-
-	// Convert bytes to target unit
-	switch strings.ToUpper(targetUnit) {
-	case "TB":
-		return bytes / (1024 * 1024 * 1024 * 1024)
-	case "GB":
-		return bytes / (1024 * 1024 * 1024)
-	case "MB":
-		return bytes / (1024 * 1024)
-	case "KB":
-		return bytes / 1024
-	case "B":
-		return bytes
-	default:
-		return value
-	}
-}
-
 func (p *Plugin) getCachedPollTime() (uint64, error) {
 	return p.getCachedPollTimeForSource(p.resolvedSourceProfileID(""))
 }
@@ -629,27 +989,25 @@ func (p *Plugin) updateTiles(data *actionData) {
 		return
 	}
 
-	showUnavailable := func() {
-		if !data.settings.InErrorState {
-			payload := evStatus{Error: true, Message: "Libre Hardware Monitor Unavailable"}
+	showUnavailable := func(cause error) {
+		authFailed := errors.Is(cause, hwsensorsservice.ErrAuthFailed)
+		transition := !data.settings.InErrorState || data.settings.AuthFailed != authFailed
+		if transition {
+			message := "Libre Hardware Monitor Unavailable"
+			if authFailed {
+				message = "Libre Hardware Monitor Authentication Failed"
+			}
+			payload := evStatus{Error: true, Message: message}
 			err := p.sd.SendToPropertyInspector("com.moeilijk.lhm.reading", data.context, payload)
 			if err != nil {
 				log.Println("updateTiles SendToPropertyInspector", err)
 			}
 			data.settings.InErrorState = true
+			data.settings.AuthFailed = authFailed
 			p.sd.SetSettings(data.context, &data.settings)
-
-			// Only set image on state transition (optimization #2)
-			if len(p.placeholderImage) > 0 {
-				if err := p.sd.SetImage(data.context, p.placeholderImage); err != nil {
-					log.Printf("Failed to setImage: %v\n", err)
-				}
-			}
 		}
-		// Clear lastPollTime so we re-render when LHM comes back
-		p.mu.Lock()
-		delete(p.lastPollTime, data.context)
-		p.mu.Unlock()
+
+		p.showStatusTile(data.context, unavailableTileText(cause), transition)
 	}
 
 	// show ui on property inspector if in error state
@@ -660,7 +1018,11 @@ func (p *Plugin) updateTiles(data *actionData) {
 			log.Println("updateTiles SendToPropertyInspector", err)
 		}
 		data.settings.InErrorState = false
+		data.settings.AuthFailed = false
 		p.sd.SetSettings(data.context, &data.settings)
+		p.mu.Lock()
+		delete(p.unavailableText, data.context)
+		p.mu.Unlock()
 	}
 
 	s := data.settings
@@ -670,11 +1032,11 @@ func (p *Plugin) updateTiles(data *actionData) {
 	pollTime, err := p.getCachedPollTimeForSource(profileID)
 	if err != nil {
 		log.Printf("PollTime failed: %v\n", err)
-		showUnavailable()
+		showUnavailable(err)
 		return
 	}
-	if pollTime == 0 || time.Since(time.Unix(0, int64(pollTime))) > 5*time.Second {
-		showUnavailable()
+	if p.pollTimeStale(pollTime) {
+		showUnavailable(nil)
 		return
 	}
 
@@ -703,43 +1065,45 @@ func (p *Plugin) updateTiles(data *actionData) {
 	r, _, err := p.getReadingForSource(profileID, s.SensorUID, s.ReadingID)
 	if err != nil {
 		log.Printf("getReading failed: %v\n", err)
-		showUnavailable()
+		showUnavailable(err)
 		return
 	}
 	if s.ShowTitleInGraph != nil && *s.ShowTitleInGraph && s.Title == "" {
 		g.SetLabelText(0, r.Label())
 	}
+	g.SetLabelText(3, p.servingMemberName(profileID))
 
-	v := r.Value()
+	raw, tracked := readingValue(r, s.ValueSource)
+	if !tracked {
+		p.showStatusTile(data.context, untrackedWindowText(s.ValueSource), false)
+		return
+	}
+	p.mu.Lock()
+	delete(p.unavailableText, data.context)
+	p.mu.Unlock()
+	v := raw
 	divisor, err := p.getCachedDivisor(data.context, s.Divisor)
 	if err != nil {
 		log.Printf("Failed to parse float: %s\n", s.Divisor)
 		return
 	}
 	if divisor != 1 {
-		v = r.Value() / divisor
+		v = raw / divisor
 	}
 
-	// Normalize the graph value to handle unit changes (e.g., KB/s → MB/s)
-	// Only applies to throughput readings (units containing "/s")
-	graphValue := r.Value()
-	if s.GraphUnit != "" && strings.Contains(r.Unit(), "/s") {
-		graphValue = p.normalizeForGraph(r.Value(), r.Unit(), s.GraphUnit)
-	}
-	if divisor != 1 {
-		graphValue = graphValue / divisor
-	}
+	// Convert into the tile's unit; a fixed unit also keeps the graph steady
+	// when LHM switches units (e.g., KB/s → MB/s). The divisor is applied
+	// first everywhere, so graph, display and thresholds agree for offset
+	// conversions such as °F.
+	unit := resolveTileUnit(r.Unit(), effectiveDisplayUnit(r.Unit(), s.DisplayUnit, p.temperatureUnit()), s.GraphUnit)
+	graphValue := unit.convert(v)
 
 	// Determine display value and unit
-	displayValue := v
-	displayUnit := r.Unit()
-	if s.GraphUnit != "" && strings.Contains(r.Unit(), "/s") {
-		// Convert display value to match GraphUnit
-		displayValue = p.normalizeForGraph(v, r.Unit(), s.GraphUnit)
-		displayUnit = s.GraphUnit + "/s"
-	}
+	displayValue := graphValue
+	displayUnit := unit.symbol
 
 	// EMA smoothing — threshold eval uses raw v; smoothing applies to graph and display values
+	var ema *float64
 	if alpha := s.SmoothingAlpha; alpha > 0 && alpha < 1.0 {
 		p.mu.Lock()
 		prev, ok := p.smoothedValues[data.context]
@@ -749,6 +1113,7 @@ func (p *Plugin) updateTiles(data *actionData) {
 		smoothed := emaSmooth(alpha, graphValue, prev)
 		p.smoothedValues[data.context] = smoothed
 		p.mu.Unlock()
+		ema = &smoothed
 		if graphValue != 0 {
 			ratio := smoothed / graphValue
 			graphValue = smoothed
@@ -760,9 +1125,29 @@ func (p *Plugin) updateTiles(data *actionData) {
 
 	now := time.Now()
 
-	// Check threshold alerts (evaluate by priority, highest first)
+	// Check threshold alerts (evaluate by priority, highest first). Temperature
+	// thresholds are compared in the tile's unit; saved ones entered in another
+	// unit are migrated to it once.
+	evalValue := v
+	if unit.isTemperature() {
+		if migrated, ok := convertThresholds(s.Thresholds, unit.to, false); ok {
+			s.Thresholds = migrated
+			p.am.SetAction(data.action, data.context, s)
+			_ = p.sd.SetSettings(data.context, s)
+			_ = p.sendThresholdsToPI(data.action, data.context, s)
+		}
+		evalValue = unit.convert(v)
+	}
 	thresholds := p.resolveThresholdsForEval(s.Thresholds, s.SuppressedGlobalIDs, hwsensorsservice.ReadingType(r.TypeI()))
-	activeThreshold := p.evaluateThresholds(data.context, v, thresholds, now)
+	if unit.isTemperature() {
+		thresholds, _ = convertThresholds(thresholds, unit.to, false)
+	}
+	activeThreshold := p.evaluateThresholds(data.context, evalValue, thresholds, now)
+	if s.ShowThresholdLines && s.GraphMode != "text" {
+		g.SetMarkers(thresholdMarkers(thresholds, unit.thresholdToGraph(), g.HighlightColor()))
+	} else {
+		g.SetMarkers(nil)
+	}
 
 	newThresholdID := ""
 	alertText := ""
@@ -800,7 +1185,7 @@ func (p *Plugin) updateTiles(data *actionData) {
 	renderDisplayText, renderAlertText, renderGraphValue, freezeGraph := p.resolveThresholdDisplay(
 		data.context,
 		activeThreshold,
-		v,
+		evalValue,
 		graphValue,
 		displayText,
 		alertText,
@@ -836,14 +1221,13 @@ func (p *Plugin) updateTiles(data *actionData) {
 			g.SetLabelText(2, "")
 		}
 	}
-
-	b, err := g.EncodePNG()
-	if err != nil {
-		log.Printf("Failed to encode graph: %v\n", err)
-		return
+	var sample *float64
+	if s.GraphMode != "text" && !freezeGraph {
+		sample = &renderGraphValue
 	}
+	p.history.record(data.context, readingHistoryKey(s), now, g.TimeWindow(), sample, ema, g.LabelText(0), g.LabelText(1))
 
-	err = p.sd.SetImage(data.context, b)
+	err = p.setImage(data.context, g.Image())
 	if err != nil {
 		log.Printf("Failed to setImage: %v\n", err)
 		return
@@ -863,17 +1247,19 @@ func (p *Plugin) refreshAction(action, context string) {
 		log.Printf("refreshAction getSettings: %v\n", err)
 		return
 	}
-	p.updateTiles(&actionData{
-		action:   action,
-		context:  context,
-		settings: &settings,
+	p.render.refresh(context, func() {
+		p.updateTiles(&actionData{
+			action:   action,
+			context:  context,
+			settings: &settings,
+		})
 	})
 }
 
-func (p *Plugin) updateAuxTiles() {
-	p.updateCompositeTick()
-	p.updateDerivedTick()
-	p.updateDialTick()
+func (p *Plugin) updateAuxTiles(tick *renderTick) {
+	p.updateCompositeTick(tick)
+	p.updateDerivedTick(tick)
+	p.updateDialTick(tick)
 }
 
 func (p *Plugin) applyThresholdText(template, valueTextNoUnit, unit string) string {
@@ -886,15 +1272,20 @@ func (p *Plugin) applyThresholdText(template, valueTextNoUnit, unit string) stri
 func (p *Plugin) updateSettingsTile(context string) {
 	p.mu.RLock()
 	intervalMs := p.globalSettings.PollInterval
+	profileID := p.globalSettings.DefaultSourceProfileID
 	var tileSettings *settingsTileSettings
 	if ts := p.settingsContexts[context]; ts != nil {
 		cp := *ts
 		tileSettings = &cp
+		if ts.SelectedSourceProfileID != "" {
+			profileID = ts.SelectedSourceProfileID
+		}
 	}
 	p.mu.RUnlock()
 	if intervalMs <= 0 {
 		intervalMs = int(p.am.GetInterval().Milliseconds())
 	}
+	healthLine := p.settingsTileHealthLine(profileID)
 	if tileSettings == nil {
 		tileSettings = &settingsTileSettings{
 			TileBackground:   "#000000",
@@ -937,8 +1328,8 @@ func (p *Plugin) updateSettingsTile(context string) {
 	// true  -> startup placeholder background + current interval
 	// false -> user-selected solid background + current interval
 	if tileSettings.ShowLabel {
-		if img, err := p.renderSettingsPlaceholderTile(intervalMs, renderedTitle, drawTitle, titleColor, textColor); err == nil {
-			if err := p.sd.SetImage(context, img); err != nil {
+		if img, err := p.renderSettingsPlaceholderTile(intervalMs, renderedTitle, drawTitle, titleColor, textColor, healthLine); err == nil {
+			if err := p.setPNG(context, img); err != nil {
 				log.Printf("updateSettingsTile SetImage failed: %v\n", err)
 			}
 			return
@@ -948,6 +1339,7 @@ func (p *Plugin) updateSettingsTile(context string) {
 
 	// Create a graph just for rendering the tile
 	g := graph.NewGraph(tileWidth, tileHeight, 0, 100, bgColor, bgColor, bgColor)
+	g.SetPixelRatio(p.pixelRatio(context))
 
 	// Render title + value in the image, aligned like graph tiles.
 	titleText := ""
@@ -958,21 +1350,20 @@ func (p *Plugin) updateSettingsTile(context string) {
 	g.SetLabelFontSize(0, settingsTitleFontSize)
 	g.SetLabel(1, fmt.Sprintf("%dms", intervalMs), 44, textColor)
 	g.SetLabelFontSize(1, 10.5)
+	if healthLine != "" {
+		g.SetLabel(2, healthLine, 60, &settingsHealthColor)
+		g.SetLabelFontSize(2, settingsHealthFontSize)
+	}
 
 	// Render and set image
 	g.Update(0) // Initialize the graph
-	b, err := g.EncodePNG()
-	if err != nil {
-		log.Printf("updateSettingsTile EncodePNG failed: %v\n", err)
-		return
-	}
-	if err := p.sd.SetImage(context, b); err != nil {
+	if err := p.setImage(context, g.Image()); err != nil {
 		log.Printf("updateSettingsTile SetImage failed: %v\n", err)
 	}
 
 }
 
-func (p *Plugin) renderSettingsPlaceholderTile(intervalMs int, title string, drawTitle bool, titleColor, textColor *color.RGBA) ([]byte, error) {
+func (p *Plugin) renderSettingsPlaceholderTile(intervalMs int, title string, drawTitle bool, titleColor, textColor *color.RGBA, healthLine string) ([]byte, error) {
 	if len(p.placeholderImage) == 0 {
 		return nil, fmt.Errorf("placeholder image not cached")
 	}
@@ -999,12 +1390,93 @@ func (p *Plugin) renderSettingsPlaceholderTile(intervalMs int, title string, dra
 		drawCenteredText(canvas, faceTitle, titleColor, title, 19)
 	}
 	drawCenteredText(canvas, faceValue, textColor, fmt.Sprintf("%dms", intervalMs), 44)
+	if healthLine != "" {
+		faceHealth, err := ffm.GetFaceOfSize(settingsHealthFontSize)
+		if err != nil {
+			return nil, fmt.Errorf("font health: %w", err)
+		}
+		drawCenteredText(canvas, faceHealth, &settingsHealthColor, healthLine, 60)
+	}
 
-	var out bytes.Buffer
-	if err := png.Encode(&out, canvas); err != nil {
+	out, err := graph.EncodeImage(canvas)
+	if err != nil {
 		return nil, fmt.Errorf("encode placeholder tile: %w", err)
 	}
-	return out.Bytes(), nil
+	return out, nil
+}
+
+// unavailableTileText returns the text drawn over the placeholder art of an
+// unavailable tile: rejected credentials and an open circuit are told apart
+// from a source that is simply down, which shows the bare placeholder.
+func unavailableTileText(cause error) string {
+	if errors.Is(cause, hwsensorsservice.ErrAuthFailed) {
+		return "Auth failed"
+	}
+	var open *sourceOpenError
+	if errors.As(cause, &open) {
+		return retryIn(open.retry)
+	}
+	return ""
+}
+
+// showStatusTile puts the placeholder art, with text over it unless text is
+// empty, on a tile. The image is only set when the text changes or force is
+// set, which while a circuit is open is once a second for the retry
+// countdown. lastPollTime is cleared so the tile re-renders on recovery.
+func (p *Plugin) showStatusTile(context, text string, force bool) {
+	p.mu.Lock()
+	if p.unavailableText == nil {
+		p.unavailableText = make(map[string]string)
+	}
+	drawn, ok := p.unavailableText[context]
+	redraw := force || !ok || drawn != text
+	p.unavailableText[context] = text
+	delete(p.lastPollTime, context)
+	p.mu.Unlock()
+
+	if !redraw {
+		return
+	}
+	img := p.placeholderImage
+	if text != "" {
+		if b, err := p.renderStatusTile(text); err == nil {
+			img = b
+		} else {
+			log.Printf("renderStatusTile: %v\n", err)
+		}
+	}
+	if len(img) > 0 {
+		if err := p.setPNG(context, img); err != nil {
+			log.Printf("Failed to setImage: %v\n", err)
+		}
+	}
+}
+
+// renderStatusTile draws text over the placeholder art of an unavailable tile.
+func (p *Plugin) renderStatusTile(text string) ([]byte, error) {
+	canvas := image.NewRGBA(image.Rect(0, 0, tileWidth, tileHeight))
+	if len(p.placeholderImage) > 0 {
+		base, err := png.Decode(bytes.NewReader(p.placeholderImage))
+		if err != nil {
+			return nil, fmt.Errorf("decode placeholder image: %w", err)
+		}
+		draw.Draw(canvas, canvas.Bounds(), base, image.Point{}, draw.Src)
+	} else {
+		draw.Draw(canvas, canvas.Bounds(), image.NewUniform(color.RGBA{0, 0, 0, 255}), image.Point{}, draw.Src)
+	}
+
+	ffm := graph.GetSharedFontFaceManager()
+	face, err := ffm.GetFaceOfSize(10.5)
+	if err != nil {
+		return nil, fmt.Errorf("font value: %w", err)
+	}
+	drawCenteredText(canvas, face, &color.RGBA{255, 96, 96, 255}, text, 44)
+
+	out, err := graph.EncodeImage(canvas)
+	if err != nil {
+		return nil, fmt.Errorf("encode status tile: %w", err)
+	}
+	return out, nil
 }
 
 func drawCenteredText(dst *image.RGBA, face font.Face, clr *color.RGBA, text string, baselineY int) {
@@ -1073,15 +1545,12 @@ func (p *Plugin) setLhmEndpoint(host string, port int) {
 	rt.mu.Lock()
 	rt.profile.Host = host
 	rt.profile.Port = port
-	if rt.c != nil {
-		rt.c.Kill()
-	}
-	rt.c = nil
-	rt.hw = nil
+	stopSourceClientLocked(rt)
 	rt.mu.Unlock()
 
 	p.mu.Lock()
 	invalidatePollCacheForRuntime(rt)
+	rt.health = sourceHealth{}
 	p.mu.Unlock()
 
 	log.Printf("LHM endpoint changed to %s for source %s, restarting bridge\n", profileEndpoint(rt.profile), defaultID)
@@ -1101,6 +1570,7 @@ func (p *Plugin) setPollInterval(intervalMs int) {
 
 	// Update action manager ticker
 	p.am.SetInterval(interval)
+	p.resubscribeSources()
 
 	// Update cache TTL and global settings under lock
 	p.mu.Lock()
@@ -0,0 +1,228 @@
+package lhmstreamdeckplugin
+
+import (
+	"log"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// maxRenderWorkers caps the render pool; beyond a handful of workers the
+// Stream Deck socket, not drawing, is the bottleneck.
+const maxRenderWorkers = 4
+
+// renderPool renders tiles on a bounded set of workers. A context never has
+// more than one render in flight: a tick that finds the previous render of a
+// context still running skips that context, so a slow tile drops a frame
+// instead of queueing up, and each context still renders in order.
+type renderPool struct {
+	jobs   chan func()
+	timing renderTiming
+
+	mu      sync.Mutex
+	busy    map[string]bool
+	pending map[string]func() // refresh to run once the context's render finishes
+}
+
+func newRenderPool(workers int) *renderPool {
+	if workers < 1 {
+		workers = 1
+	}
+	rp := &renderPool{
+		jobs:    make(chan func(), workers*4),
+		busy:    make(map[string]bool),
+		pending: make(map[string]func()),
+	}
+	for i := 0; i < workers; i++ {
+		go func() {
+			for job := range rp.jobs {
+				job()
+			}
+		}()
+	}
+	return rp
+}
+
+// defaultRenderWorkers is one worker per CPU, up to maxRenderWorkers.
+func defaultRenderWorkers() int {
+	return min(runtime.NumCPU(), maxRenderWorkers)
+}
+
+// submit queues fn as the next render of context, reporting false when the
+// previous one is still running. done, when set, is called after fn returns.
+// submit blocks while the queue is full.
+func (rp *renderPool) submit(context string, fn func(), done func()) bool {
+	rp.mu.Lock()
+	if rp.busy[context] {
+		rp.mu.Unlock()
+		rp.timing.skip()
+		return false
+	}
+	rp.busy[context] = true
+	rp.mu.Unlock()
+
+	rp.jobs <- func() {
+		fn()
+		if done != nil {
+			done()
+		}
+		rp.drain(context)
+	}
+	return true
+}
+
+// refresh renders context outside the ticker, e.g. after a key press. Unlike
+// a tick it is never dropped: while a render is running it runs right after
+// it, and refreshes that pile up meanwhile collapse into one. A nil pool
+// renders inline.
+func (rp *renderPool) refresh(context string, fn func()) {
+	if rp == nil {
+		fn()
+		return
+	}
+	rp.mu.Lock()
+	if rp.busy[context] {
+		rp.pending[context] = fn
+		rp.mu.Unlock()
+		return
+	}
+	rp.busy[context] = true
+	rp.mu.Unlock()
+
+	rp.jobs <- func() {
+		fn()
+		rp.drain(context)
+	}
+}
+
+// drain runs the refreshes queued for context while it was rendering, then
+// marks it idle.
+func (rp *renderPool) drain(context string) {
+	for {
+		rp.mu.Lock()
+		fn, ok := rp.pending[context]
+		if !ok {
+			delete(rp.busy, context)
+			rp.mu.Unlock()
+			return
+		}
+		delete(rp.pending, context)
+		rp.mu.Unlock()
+		fn()
+	}
+}
+
+// beginTick starts a tick expected to finish within interval.
+func (rp *renderPool) beginTick(interval time.Duration) *renderTick {
+	return &renderTick{pool: rp, start: time.Now(), interval: interval}
+}
+
+// renderTick collects the renders of one tick so its duration, from the
+// ticker firing until the last render finished, can be recorded.
+type renderTick struct {
+	pool     *renderPool
+	start    time.Time
+	interval time.Duration
+	wg       sync.WaitGroup
+	renders  int
+}
+
+// render submits fn as part of the tick. A nil tick renders inline, for
+// callers outside the ticker.
+func (t *renderTick) render(context string, fn func()) {
+	if t == nil {
+		fn()
+		return
+	}
+	t.wg.Add(1)
+	if t.pool.submit(context, fn, t.wg.Done) {
+		t.renders++
+	} else {
+		t.wg.Done()
+	}
+}
+
+// end records the tick once all its renders have finished. It does not
+// block the ticker.
+func (t *renderTick) end() {
+	go func() {
+		t.wg.Wait()
+		t.pool.timing.record(time.Since(t.start), t.interval, t.renders)
+	}()
+}
+
+// renderTiming keeps per-tick render timing for the settings status and logs
+// when ticks start taking longer than the poll interval.
+type renderTiming struct {
+	mu       sync.Mutex
+	last     time.Duration
+	avg      time.Duration // exponential moving average over ticks
+	peak     time.Duration // slowest tick since peakFrom
+	peakFrom time.Time
+	renders  int
+	late     uint64 // ticks that took longer than the interval
+	skipped  uint64 // renders skipped because the previous one was running
+	behind   bool
+}
+
+// renderPeakWindow is how long the slowest tick is remembered.
+const renderPeakWindow = time.Minute
+
+func (rt *renderTiming) record(d, interval time.Duration, renders int) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	now := time.Now()
+	rt.last = d
+	if rt.avg == 0 {
+		rt.avg = d
+	} else {
+		rt.avg += (d - rt.avg) / 8
+	}
+	if now.Sub(rt.peakFrom) > renderPeakWindow {
+		rt.peak, rt.peakFrom = 0, now
+	}
+	rt.peak = max(rt.peak, d)
+	rt.renders = renders
+
+	late := interval > 0 && d > interval
+	if late {
+		rt.late++
+	}
+	if late != rt.behind {
+		rt.behind = late
+		if late {
+			log.Printf("Render tick took %v for %d tiles, longer than the %v poll interval\n", d.Round(time.Millisecond), renders, interval)
+		} else {
+			log.Printf("Render ticks back within the %v poll interval\n", interval)
+		}
+	}
+}
+
+func (rt *renderTiming) skip() {
+	rt.mu.Lock()
+	rt.skipped++
+	rt.mu.Unlock()
+}
+
+// renderTimingStatus is the render timing shown in the settings PI.
+type renderTimingStatus struct {
+	LastMs  int64  `json:"lastMs"`
+	AvgMs   int64  `json:"avgMs"`
+	PeakMs  int64  `json:"peakMs"`
+	Renders int    `json:"renders"`
+	Late    uint64 `json:"lateTicks"`
+	Skipped uint64 `json:"skippedRenders"`
+}
+
+func (rt *renderTiming) status() renderTimingStatus {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	return renderTimingStatus{
+		LastMs:  rt.last.Milliseconds(),
+		AvgMs:   rt.avg.Milliseconds(),
+		PeakMs:  rt.peak.Milliseconds(),
+		Renders: rt.renders,
+		Late:    rt.late,
+		Skipped: rt.skipped,
+	}
+}
@@ -0,0 +1,120 @@
+package lhmstreamdeckplugin
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRenderPoolSkipsBusyContext(t *testing.T) {
+	rp := newRenderPool(2)
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	tick := rp.beginTick(time.Second)
+	tick.render("slow", func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	ran := false
+	next := rp.beginTick(time.Second)
+	next.render("slow", func() { ran = true })
+	if next.renders != 0 {
+		t.Fatalf("renders = %d, want the busy context skipped", next.renders)
+	}
+	close(release)
+	tick.wg.Wait()
+	next.wg.Wait()
+	if ran {
+		t.Fatal("skipped render ran")
+	}
+	if got := rp.timing.status().Skipped; got != 1 {
+		t.Fatalf("Skipped = %d, want 1", got)
+	}
+
+	// Once the slow render finished the context renders again.
+	again := rp.beginTick(time.Second)
+	again.render("slow", func() { ran = true })
+	again.wg.Wait()
+	if !ran {
+		t.Fatal("context stayed busy after its render finished")
+	}
+}
+
+func TestRenderPoolRefreshRunsAfterBusyRender(t *testing.T) {
+	rp := newRenderPool(4)
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	var mu sync.Mutex
+	var order []string
+	record := func(s string) {
+		mu.Lock()
+		order = append(order, s)
+		mu.Unlock()
+	}
+
+	tick := rp.beginTick(time.Second)
+	tick.render("ctx", func() {
+		close(started)
+		<-release
+		record("tick")
+	})
+	<-started
+
+	// Refreshes that arrive mid-render collapse into the latest one.
+	done := make(chan struct{})
+	rp.refresh("ctx", func() { record("refresh 1") })
+	rp.refresh("ctx", func() {
+		record("refresh 2")
+		close(done)
+	})
+	close(release)
+	<-done
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(order) != 2 || order[0] != "tick" || order[1] != "refresh 2" {
+		t.Fatalf("order = %v, want [tick refresh 2]", order)
+	}
+}
+
+func TestRenderPoolRefreshWithoutPoolRendersInline(t *testing.T) {
+	var rp *renderPool
+	ran := false
+	rp.refresh("ctx", func() { ran = true })
+	if !ran {
+		t.Fatal("nil pool did not render inline")
+	}
+
+	var tick *renderTick
+	ran = false
+	tick.render("ctx", func() { ran = true })
+	if !ran {
+		t.Fatal("nil tick did not render inline")
+	}
+}
+
+func TestRenderTimingCountsLateTicks(t *testing.T) {
+	var rt renderTiming
+	rt.record(20*time.Millisecond, time.Second, 10)
+	rt.record(1500*time.Millisecond, time.Second, 10)
+	rt.record(1200*time.Millisecond, time.Second, 10)
+	rt.record(30*time.Millisecond, time.Second, 10)
+
+	st := rt.status()
+	if st.Late != 2 {
+		t.Fatalf("Late = %d, want 2", st.Late)
+	}
+	if st.LastMs != 30 || st.PeakMs != 1500 || st.Renders != 10 {
+		t.Fatalf("status = %+v", st)
+	}
+	if st.AvgMs <= 20 || st.AvgMs >= 1500 {
+		t.Fatalf("AvgMs = %d, want between the fastest and slowest tick", st.AvgMs)
+	}
+	if rt.behind {
+		t.Fatal("still behind after an on-time tick")
+	}
+}
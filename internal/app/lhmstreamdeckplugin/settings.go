@@ -17,7 +17,7 @@ func decodeActionSettings(raw *json.RawMessage) (actionSettings, bool, error) {
 		return settings, false, nil
 	}
 	if err := json.Unmarshal(*raw, &settings); err == nil {
-		return settings, false, nil
+		return settings, migrateSymmetricAutoScale(&settings.AutoScale, &settings.GraphScale), nil
 	}
 
 	var rawMap map[string]json.RawMessage
@@ -68,10 +68,27 @@ func decodeActionSettings(raw *json.RawMessage) (actionSettings, bool, error) {
 		settings.SnoozeDurations = normalized
 		migrated = true
 	}
+	if migrateSymmetricAutoScale(&settings.AutoScale, &settings.GraphScale) {
+		migrated = true
+	}
 
 	return settings, migrated, nil
 }
 
+// migrateSymmetricAutoScale turns the old "symmetric" auto scale into a
+// symmetric scale fitted to the visible samples, unless another scale was
+// already chosen. It reports whether the settings changed.
+func migrateSymmetricAutoScale(autoScale, graphScale *string) bool {
+	if *autoScale != "symmetric" {
+		return false
+	}
+	*autoScale = "window"
+	if *graphScale == "" {
+		*graphScale = "symmetric"
+	}
+	return true
+}
+
 func mustJSON(raw string) json.RawMessage {
 	return json.RawMessage(raw)
 }
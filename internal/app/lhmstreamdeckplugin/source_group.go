@@ -0,0 +1,196 @@
+package lhmstreamdeckplugin
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+
+	hwsensorsservice "github.com/moeilijk/lhm-streamdeck/pkg/service"
+)
+
+// sourceGroupService is the HardwareService of a failover group profile. Each
+// poll asks the members in priority order and serves from the first one with a
+// fresh poll time, so a failed primary hands over to the next member within
+// the same tick and takes over again as soon as it answers. Members keep their
+// own runtimes and circuit breakers: a member whose circuit is open is skipped
+// until its backoff has elapsed, which is also how a recovered primary is
+// noticed. Tiles look readings up by sensor and reading ID in whichever
+// member's snapshot is being served.
+type sourceGroupService struct {
+	p  *Plugin
+	rt *sourceRuntime
+
+	mu      sync.Mutex
+	serving string // member profile ID that answered the last poll
+}
+
+func newSourceGroupService(p *Plugin, rt *sourceRuntime) *sourceGroupService {
+	return &sourceGroupService{p: p, rt: rt}
+}
+
+// members returns the group's member profiles in priority order, skipping
+// deleted profiles and nested groups.
+func (g *sourceGroupService) members() []lhmSourceProfile {
+	g.rt.mu.RLock()
+	ids := slices.Clone(g.rt.profile.Members)
+	g.rt.mu.RUnlock()
+	out := make([]lhmSourceProfile, 0, len(ids))
+	for _, id := range ids {
+		if prof, ok := g.p.sourceProfileByID(id); ok && prof.Type != sourceTypeGroup {
+			out = append(out, prof)
+		}
+	}
+	return out
+}
+
+func (g *sourceGroupService) PollTime() (uint64, error) {
+	members := g.members()
+	if len(members) == 0 {
+		g.setServing("")
+		return 0, fmt.Errorf("source group has no members")
+	}
+	errs := make([]error, 0, len(members))
+	for _, m := range members {
+		pt, err := g.p.getCachedPollTimeForSource(m.ID)
+		if err == nil && g.p.pollTimeStale(pt) {
+			err = fmt.Errorf("stale poll time")
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", m.Name, err))
+			continue
+		}
+		g.setServing(m.ID)
+		return pt, nil
+	}
+	g.setServing("")
+	return 0, errors.Join(errs...)
+}
+
+// setServing switches the member tiles read from. Poll times of different
+// members are unrelated clocks, so the group's cached snapshot is dropped on
+// every switch rather than compared against the new member's poll time.
+func (g *sourceGroupService) setServing(id string) {
+	g.mu.Lock()
+	prev := g.serving
+	g.serving = id
+	g.mu.Unlock()
+	if prev == id {
+		return
+	}
+	g.p.mu.Lock()
+	g.rt.snapshot = nil
+	g.p.mu.Unlock()
+	if id != "" {
+		log.Printf("source group %s: serving from %s\n", g.rt.profile.ID, id)
+	}
+}
+
+func (g *sourceGroupService) servingID() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.serving
+}
+
+// servingHardware returns the hardware service of the serving member, polling
+// the group first when no member has been picked yet.
+func (g *sourceGroupService) servingHardware() (hwsensorsservice.HardwareService, error) {
+	id := g.servingID()
+	if id == "" {
+		if _, err := g.PollTime(); err != nil {
+			return nil, err
+		}
+		id = g.servingID()
+	}
+	rt := g.p.runtimeForSource(id)
+	rt.mu.RLock()
+	hw := rt.hw
+	rt.mu.RUnlock()
+	if hw == nil {
+		return nil, fmt.Errorf("LHM bridge not ready")
+	}
+	return hw, nil
+}
+
+func (g *sourceGroupService) Sensors() ([]hwsensorsservice.Sensor, error) {
+	hw, err := g.servingHardware()
+	if err != nil {
+		return nil, err
+	}
+	return hw.Sensors()
+}
+
+func (g *sourceGroupService) ReadingsForSensorID(id string) ([]hwsensorsservice.Reading, error) {
+	hw, err := g.servingHardware()
+	if err != nil {
+		return nil, err
+	}
+	return hw.ReadingsForSensorID(id)
+}
+
+// Snapshot returns the serving member's snapshot, fetched through its own
+// runtime so a member that also backs tiles directly is read once per tick.
+func (g *sourceGroupService) Snapshot(pollTime uint64) (*hwsensorsservice.Snapshot, error) {
+	id := g.servingID()
+	if id == "" {
+		return nil, fmt.Errorf("source group has no member available")
+	}
+	return g.p.snapshotForSource(id)
+}
+
+// servingMemberName returns the name of the member currently serving a group
+// profile, or "" when the profile is not a group or no member answers.
+func (p *Plugin) servingMemberName(profileID string) string {
+	rt := p.runtimeForSource(profileID)
+	rt.mu.RLock()
+	g, ok := rt.hw.(*sourceGroupService)
+	rt.mu.RUnlock()
+	if !ok {
+		return ""
+	}
+	id := g.servingID()
+	if id == "" {
+		return ""
+	}
+	prof, ok := p.sourceProfileByID(id)
+	if !ok {
+		return ""
+	}
+	if prof.Name == "" {
+		return prof.ID
+	}
+	return prof.Name
+}
+
+// sanitizeGroupMembers keeps the members of a group profile that can serve it:
+// existing, non-group profiles other than the group itself, each listed once.
+// Profiles that are not groups have no members.
+func sanitizeGroupMembers(profiles []lhmSourceProfile, sp lhmSourceProfile) []string {
+	if sp.Type != sourceTypeGroup {
+		return nil
+	}
+	out := make([]string, 0, len(sp.Members))
+	for _, id := range sp.Members {
+		if id == sp.ID || slices.Contains(out, id) {
+			continue
+		}
+		i := slices.IndexFunc(profiles, func(p lhmSourceProfile) bool { return p.ID == id })
+		if i < 0 || profiles[i].Type == sourceTypeGroup {
+			continue
+		}
+		out = append(out, id)
+	}
+	return out
+}
+
+// withoutGroupMember removes a deleted profile from every group listing it.
+// Members slices are replaced, not edited in place, since runtimes hold
+// copies of the profiles sharing them.
+func withoutGroupMember(profiles []lhmSourceProfile, id string) {
+	for i := range profiles {
+		if slices.Contains(profiles[i].Members, id) {
+			profiles[i].Members = slices.DeleteFunc(slices.Clone(profiles[i].Members), func(m string) bool { return m == id })
+		}
+	}
+}
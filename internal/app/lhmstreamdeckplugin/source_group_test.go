@@ -0,0 +1,137 @@
+package lhmstreamdeckplugin
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	hwsensorsservice "github.com/moeilijk/lhm-streamdeck/pkg/service"
+)
+
+func memberService(label string) *failingHardwareService {
+	return &failingHardwareService{stubHardwareService: stubHardwareService{
+		readingsBySensor: map[string][]hwsensorsservice.Reading{
+			"/cpu": {stubReading{id: 7, label: label, unit: "%"}},
+		},
+	}}
+}
+
+// newGroupTestPlugin wires a "box" group over a primary and a backup member.
+func newGroupTestPlugin(primary, backup hwsensorsservice.HardwareService) (*Plugin, map[string]*sourceRuntime) {
+	p := &Plugin{
+		sources: make(map[string]*sourceRuntime),
+		globalSettings: globalSettings{
+			SourceProfiles: []lhmSourceProfile{
+				{ID: "lhm", Name: "LHM", Host: "127.0.0.1", Port: 8085},
+				{ID: "companion", Name: "Companion", Host: "127.0.0.1", Port: 8086},
+				{ID: "box", Name: "Workstation", Type: sourceTypeGroup, Members: []string{"lhm", "companion"}},
+			},
+			DefaultSourceProfileID: "box",
+		},
+	}
+	rts := make(map[string]*sourceRuntime)
+	for _, prof := range p.globalSettings.SourceProfiles {
+		rts[prof.ID] = &sourceRuntime{profile: prof}
+		p.sources[prof.ID] = rts[prof.ID]
+	}
+	rts["lhm"].hw = primary
+	rts["companion"].hw = backup
+	rts["box"].hw = newSourceGroupService(p, rts["box"])
+	return p, rts
+}
+
+func groupTick(p *Plugin, rts map[string]*sourceRuntime) {
+	for _, rt := range rts {
+		nextTick(p, rt)
+	}
+}
+
+func TestSourceGroupFailsOverAndBack(t *testing.T) {
+	primary, backup := memberService("CPU via LHM"), memberService("CPU via companion")
+	p, rts := newGroupTestPlugin(primary, backup)
+
+	read := func() string {
+		t.Helper()
+		groupTick(p, rts)
+		r, _, err := p.getReadingForSource("box", "/cpu", 7)
+		if err != nil {
+			t.Fatalf("getReadingForSource: %v", err)
+		}
+		return r.Label()
+	}
+
+	if got := read(); got != "CPU via LHM" || p.servingMemberName("box") != "LHM" {
+		t.Fatalf("healthy group read %q from %q, want the primary", got, p.servingMemberName("box"))
+	}
+
+	primary.err = errors.New("connection refused")
+	if got := read(); got != "CPU via companion" || p.servingMemberName("box") != "Companion" {
+		t.Fatalf("after primary failure read %q from %q, want the backup", got, p.servingMemberName("box"))
+	}
+
+	// Once its circuit is open the failed primary is no longer asked every tick.
+	for i := 0; i < sourceOpenAfterFailures+3; i++ {
+		read()
+	}
+	if primary.polls > sourceOpenAfterFailures+1 {
+		t.Fatalf("open primary polled %d times", primary.polls)
+	}
+
+	// The primary's next probe finds it back and the group fails back.
+	primary.err = nil
+	p.mu.Lock()
+	rts["lhm"].health.nextRetry = rts["lhm"].health.nextRetry.Add(-sourceBackoffMax * 2)
+	p.mu.Unlock()
+	if got := read(); got != "CPU via LHM" || p.servingMemberName("box") != "LHM" {
+		t.Fatalf("after recovery read %q from %q, want the primary", got, p.servingMemberName("box"))
+	}
+}
+
+func TestSourceGroupReportsAllMembersFailing(t *testing.T) {
+	primary, backup := memberService("a"), memberService("b")
+	primary.err = hwsensorsservice.AuthError("authentication failed: status 401")
+	backup.err = errors.New("connection refused")
+	p, rts := newGroupTestPlugin(primary, backup)
+
+	groupTick(p, rts)
+	_, _, err := p.getReadingForSource("box", "/cpu", 7)
+	if err == nil {
+		t.Fatal("expected an error with every member down")
+	}
+	if !errors.Is(err, hwsensorsservice.ErrAuthFailed) {
+		t.Fatalf("err = %v, want the member's auth failure to survive", err)
+	}
+	if name := p.servingMemberName("box"); name != "" {
+		t.Fatalf("servingMemberName = %q with no member answering", name)
+	}
+}
+
+func TestSanitizeGroupMembers(t *testing.T) {
+	profiles := []lhmSourceProfile{
+		{ID: "a"}, {ID: "b", Type: sourceTypeHwmon}, {ID: "other", Type: sourceTypeGroup},
+	}
+	got := sanitizeGroupMembers(profiles, lhmSourceProfile{
+		ID: "g", Type: sourceTypeGroup, Members: []string{"b", "g", "missing", "other", "a", "b"},
+	})
+	if want := []string{"b", "a"}; !slices.Equal(got, want) {
+		t.Fatalf("sanitizeGroupMembers = %v, want %v", got, want)
+	}
+	if got := sanitizeGroupMembers(profiles, lhmSourceProfile{ID: "x", Members: []string{"a"}}); got != nil {
+		t.Fatalf("non-group profile kept members %v", got)
+	}
+}
+
+func TestDeletedProfileLeavesGroups(t *testing.T) {
+	members := []string{"a", "b"}
+	profiles := []lhmSourceProfile{{ID: "g", Type: sourceTypeGroup, Members: members}}
+	running := profiles[0]
+
+	withoutGroupMember(profiles, "a")
+	if !slices.Equal(profiles[0].Members, []string{"b"}) {
+		t.Fatalf("members = %v, want [b]", profiles[0].Members)
+	}
+	// The runtime's copy must still differ so the group is reconciled.
+	if sameSourceProfileEndpoint(running, profiles[0]) {
+		t.Fatal("group runtime would not notice the removed member")
+	}
+}
@@ -0,0 +1,211 @@
+package lhmstreamdeckplugin
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"time"
+
+	hwsensorsservice "github.com/moeilijk/lhm-streamdeck/pkg/service"
+)
+
+// sourceHealthState is the circuit breaker state of a source profile.
+type sourceHealthState int
+
+const (
+	sourceHealthy  sourceHealthState = iota // last fetch succeeded
+	sourceDegraded                          // recent fetches failed, still fetching every tick
+	sourceOpen                              // circuit open: fetches skipped until nextRetry
+)
+
+func (s sourceHealthState) String() string {
+	switch s {
+	case sourceDegraded:
+		return "degraded"
+	case sourceOpen:
+		return "open"
+	}
+	return "healthy"
+}
+
+const (
+	sourceOpenAfterFailures = 3
+	sourceBackoffBase       = 2 * time.Second
+	sourceBackoffMax        = 2 * time.Minute
+	sourceBackoffJitter     = 0.2 // ±20% so sources that died together do not retry in lockstep
+)
+
+// healthRand returns a value in [0, 1); tests replace it for deterministic jitter.
+var healthRand = rand.Float64
+
+// sourceHealth tracks consecutive fetch failures of a source. Once
+// sourceOpenAfterFailures fetches in a row have failed the circuit opens and
+// tiles stop fetching; one probe is let through per backoff period, and the
+// backoff doubles with every failed probe up to sourceBackoffMax.
+// Accessed under Plugin.mu.
+type sourceHealth struct {
+	state     sourceHealthState
+	failures  int
+	lastErr   error
+	nextRetry time.Time
+}
+
+// sourceBackoff returns the jittered delay before the next probe after n
+// consecutive failures.
+func sourceBackoff(n int) time.Duration {
+	d := sourceBackoffBase
+	for i := sourceOpenAfterFailures; i < n && d < sourceBackoffMax; i++ {
+		d *= 2
+	}
+	if d > sourceBackoffMax {
+		d = sourceBackoffMax
+	}
+	jitter := (healthRand()*2 - 1) * sourceBackoffJitter
+	return time.Duration(float64(d) * (1 + jitter))
+}
+
+// allow reports whether a fetch may be made at now. While the circuit is open
+// only one caller per backoff period gets through: the probe claims its slot
+// by pushing nextRetry out, so concurrent tiles keep skipping.
+func (h *sourceHealth) allow(now time.Time) bool {
+	if h.state != sourceOpen {
+		return true
+	}
+	if now.Before(h.nextRetry) {
+		return false
+	}
+	h.nextRetry = now.Add(sourceBackoff(h.failures))
+	return true
+}
+
+// record updates the state with the outcome of a fetch and reports whether
+// the state changed.
+func (h *sourceHealth) record(err error, now time.Time) bool {
+	prev := h.state
+	if err == nil {
+		*h = sourceHealth{}
+		return prev != sourceHealthy
+	}
+	h.failures++
+	h.lastErr = err
+	if h.failures < sourceOpenAfterFailures {
+		h.state = sourceDegraded
+	} else {
+		h.state = sourceOpen
+		h.nextRetry = now.Add(sourceBackoff(h.failures))
+	}
+	return prev != h.state
+}
+
+// err returns the error a tile skipping its fetch should surface.
+func (h *sourceHealth) err() error {
+	if h.state != sourceOpen {
+		return h.lastErr
+	}
+	return &sourceOpenError{last: h.lastErr, retry: h.nextRetry}
+}
+
+// sourceOpenError is returned instead of fetching while a source's circuit is
+// open. It unwraps to the failure that opened it, so auth failures are still
+// recognised as such.
+type sourceOpenError struct {
+	last  error
+	retry time.Time
+}
+
+func (e *sourceOpenError) Error() string {
+	return fmt.Sprintf("source unavailable, retrying at %s: %v", e.retry.Format(time.TimeOnly), e.last)
+}
+
+func (e *sourceOpenError) Unwrap() error { return e.last }
+
+// sourceHealthStatus is the health of a source as shown on tiles and in the
+// settings property inspector.
+type sourceHealthStatus struct {
+	State     string    `json:"state"`
+	LastError string    `json:"lastError,omitempty"`
+	NextRetry time.Time `json:"nextRetry,omitzero"`
+}
+
+// sourceHealthStatus returns the current health of a source profile.
+func (p *Plugin) sourceHealthStatus(profileID string) sourceHealthStatus {
+	rt := p.runtimeForSource(profileID)
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	st := sourceHealthStatus{State: rt.health.state.String()}
+	if rt.health.lastErr != nil {
+		st.LastError = rt.health.lastErr.Error()
+	}
+	if rt.health.state == sourceOpen {
+		st.NextRetry = rt.health.nextRetry
+	}
+	return st
+}
+
+// allowSourceFetch reports whether rt may be fetched from now, returning the
+// error to surface instead when its circuit is open.
+func (p *Plugin) allowSourceFetch(rt *sourceRuntime) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if rt.health.allow(time.Now()) {
+		return nil
+	}
+	return rt.health.err()
+}
+
+// sourceBackingOff reports whether rt's circuit is open and its next probe is
+// not yet due. Unlike allowSourceFetch it does not claim the probe.
+func (p *Plugin) sourceBackingOff(rt *sourceRuntime) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return rt.health.state == sourceOpen && time.Now().Before(rt.health.nextRetry)
+}
+
+// recordSourceFetch records the outcome of a fetch from rt. Settings tiles
+// show the source state, so they are redrawn whenever it changes.
+func (p *Plugin) recordSourceFetch(rt *sourceRuntime, err error) {
+	p.mu.Lock()
+	changed := rt.health.record(err, time.Now())
+	state := rt.health.state
+	p.mu.Unlock()
+	if changed {
+		if err != nil {
+			log.Printf("source %s: %s after %v\n", rt.profile.ID, state, err)
+		} else {
+			log.Printf("source %s: healthy again\n", rt.profile.ID)
+		}
+	}
+	// A failed probe leaves the circuit open but moves the retry time.
+	if (changed || state == sourceOpen) && p.sd != nil {
+		go p.updateAllSettingsTiles()
+	}
+}
+
+// settingsTileHealthLine returns the line the settings tile shows under the
+// poll interval for a source that is not healthy. The tile is only redrawn on
+// state changes and failed probes, so it shows the retry time rather than a
+// countdown.
+func (p *Plugin) settingsTileHealthLine(profileID string) string {
+	rt := p.runtimeForSource(profileID)
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	switch {
+	case rt.health.state == sourceHealthy:
+		return ""
+	case errors.Is(rt.health.lastErr, hwsensorsservice.ErrAuthFailed):
+		return "Auth failed"
+	case rt.health.state == sourceOpen:
+		return "Retry " + rt.health.nextRetry.Format(time.TimeOnly)
+	}
+	return "Degraded"
+}
+
+// retryIn formats the time left until t for a tile, e.g. "Retry in 12s".
+func retryIn(t time.Time) string {
+	d := time.Until(t).Round(time.Second)
+	if d < time.Second {
+		return "Retrying"
+	}
+	return fmt.Sprintf("Retry in %s", d)
+}
@@ -0,0 +1,269 @@
+package lhmstreamdeckplugin
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	hwsensorsservice "github.com/moeilijk/lhm-streamdeck/pkg/service"
+)
+
+func withoutJitter(t *testing.T) {
+	t.Helper()
+	orig := healthRand
+	healthRand = func() float64 { return 0.5 }
+	t.Cleanup(func() { healthRand = orig })
+}
+
+func TestSourceHealthOpensAfterRepeatedFailures(t *testing.T) {
+	withoutJitter(t)
+	now := time.Unix(1000, 0)
+	var h sourceHealth
+	down := errors.New("connection refused")
+
+	for i, want := range []sourceHealthState{sourceDegraded, sourceDegraded, sourceOpen} {
+		if !h.allow(now) {
+			t.Fatalf("fetch %d refused before the circuit opened", i)
+		}
+		h.record(down, now)
+		if h.state != want {
+			t.Fatalf("after %d failures state = %s, want %s", i+1, h.state, want)
+		}
+	}
+	if got := h.nextRetry.Sub(now); got != sourceBackoffBase {
+		t.Fatalf("first backoff = %v, want %v", got, sourceBackoffBase)
+	}
+
+	if h.allow(now.Add(time.Second)) {
+		t.Fatal("fetch allowed while the circuit is open")
+	}
+	probeAt := h.nextRetry
+	if !h.allow(probeAt) {
+		t.Fatal("probe refused once the backoff elapsed")
+	}
+	if h.allow(probeAt) {
+		t.Fatal("second caller got through while the probe is in flight")
+	}
+
+	if changed := h.record(nil, probeAt); !changed || h.state != sourceHealthy || h.failures != 0 || h.lastErr != nil {
+		t.Fatalf("success did not reset health: changed=%v %+v", changed, h)
+	}
+}
+
+func TestSourceBackoffDoublesUpToMax(t *testing.T) {
+	withoutJitter(t)
+	want := sourceBackoffBase
+	for n := sourceOpenAfterFailures; n < sourceOpenAfterFailures+12; n++ {
+		if got := sourceBackoff(n); got != want {
+			t.Fatalf("sourceBackoff(%d) = %v, want %v", n, got, want)
+		}
+		want *= 2
+		if want > sourceBackoffMax {
+			want = sourceBackoffMax
+		}
+	}
+
+	healthRand = func() float64 { return 0 }
+	if got, lo := sourceBackoff(sourceOpenAfterFailures), time.Duration(float64(sourceBackoffBase)*(1-sourceBackoffJitter)); got != lo {
+		t.Fatalf("lowest jitter = %v, want %v", got, lo)
+	}
+	healthRand = func() float64 { return 0.999 }
+	if got, hi := sourceBackoff(100), time.Duration(float64(sourceBackoffMax)*(1+sourceBackoffJitter)); got > hi || got < sourceBackoffMax {
+		t.Fatalf("highest jitter = %v, want within (%v, %v]", got, sourceBackoffMax, hi)
+	}
+}
+
+// failingHardwareService fails every PollTime with err and counts the calls.
+type failingHardwareService struct {
+	stubHardwareService
+	err   error
+	polls int
+}
+
+func (s *failingHardwareService) PollTime() (uint64, error) {
+	s.polls++
+	if s.err != nil {
+		return 0, s.err
+	}
+	return uint64(time.Now().UnixNano()), nil
+}
+
+func newHealthTestPlugin(hw hwsensorsservice.HardwareService) (*Plugin, *sourceRuntime) {
+	const profileID = "default"
+	p := &Plugin{
+		sources: make(map[string]*sourceRuntime),
+		globalSettings: globalSettings{
+			SourceProfiles:         []lhmSourceProfile{{ID: profileID, Name: "Default", Host: "127.0.0.1", Port: 8085}},
+			DefaultSourceProfileID: profileID,
+		},
+	}
+	rt := &sourceRuntime{profile: p.globalSettings.SourceProfiles[0], hw: hw}
+	p.sources[profileID] = rt
+	return p, rt
+}
+
+// nextTick expires the poll time cache as the next ticker cycle would.
+func nextTick(p *Plugin, rt *sourceRuntime) {
+	p.mu.Lock()
+	rt.cachedAt = time.Time{}
+	p.mu.Unlock()
+}
+
+func TestOpenCircuitSkipsFetches(t *testing.T) {
+	withoutJitter(t)
+	hw := &failingHardwareService{err: errors.New("connection refused")}
+	p, rt := newHealthTestPlugin(hw)
+
+	for i := 0; i < sourceOpenAfterFailures; i++ {
+		nextTick(p, rt)
+		if _, err := p.getCachedPollTimeForSource("default"); err == nil {
+			t.Fatal("expected PollTime error")
+		}
+	}
+	for i := 0; i < 10; i++ {
+		nextTick(p, rt)
+		_, err := p.getCachedPollTimeForSource("default")
+		var open *sourceOpenError
+		if !errors.As(err, &open) {
+			t.Fatalf("tick %d: err = %v, want sourceOpenError", i, err)
+		}
+	}
+	if hw.polls != sourceOpenAfterFailures {
+		t.Fatalf("PollTime called %d times, want %d before the circuit opened", hw.polls, sourceOpenAfterFailures)
+	}
+
+	st := p.sourceHealthStatus("default")
+	if st.State != "open" || st.LastError != "connection refused" || st.NextRetry.IsZero() {
+		t.Fatalf("sourceHealthStatus = %+v", st)
+	}
+	if _, err := p.getCachedPollTimeForSource("default"); !strings.HasPrefix(unavailableTileText(err), "Retry") {
+		t.Fatalf("tile text = %q, want a retry countdown", unavailableTileText(err))
+	}
+	if line := p.settingsTileHealthLine("default"); !strings.HasPrefix(line, "Retry ") {
+		t.Fatalf("settings tile line = %q", line)
+	}
+
+	// Once the backoff has elapsed a single probe goes through and closes the
+	// circuit when the source is back.
+	hw.err = nil
+	p.mu.Lock()
+	rt.health.nextRetry = time.Now().Add(-time.Millisecond)
+	p.mu.Unlock()
+	nextTick(p, rt)
+	if _, err := p.getCachedPollTimeForSource("default"); err != nil {
+		t.Fatalf("probe failed: %v", err)
+	}
+	if hw.polls != sourceOpenAfterFailures+1 {
+		t.Fatalf("PollTime called %d times, want one probe", hw.polls)
+	}
+	if st := p.sourceHealthStatus("default"); st.State != "healthy" || st.LastError != "" {
+		t.Fatalf("source not healthy after a good probe: %+v", st)
+	}
+	if line := p.settingsTileHealthLine("default"); line != "" {
+		t.Fatalf("settings tile line = %q for a healthy source", line)
+	}
+}
+
+// snapshotFailingHardwareService answers PollTime but fails every Snapshot
+// with err, and counts the Snapshot calls.
+type snapshotFailingHardwareService struct {
+	failingHardwareService
+	snapErr   error
+	snapshots int
+}
+
+func (s *snapshotFailingHardwareService) Snapshot(pollTime uint64) (*hwsensorsservice.Snapshot, error) {
+	s.snapshots++
+	if s.snapErr != nil {
+		return nil, s.snapErr
+	}
+	return &hwsensorsservice.Snapshot{PollTime: pollTime}, nil
+}
+
+func TestSnapshotFailuresOpenTheCircuit(t *testing.T) {
+	withoutJitter(t)
+	hw := &snapshotFailingHardwareService{snapErr: errors.New("bridge closed the connection")}
+	p, rt := newHealthTestPlugin(hw)
+
+	for i := 0; i < sourceOpenAfterFailures; i++ {
+		nextTick(p, rt)
+		if _, err := p.snapshotForSource("default"); err == nil {
+			t.Fatal("expected Snapshot error")
+		}
+		// Other tiles on the same tick get the error without fetching.
+		if _, err := p.snapshotForSource("default"); err == nil {
+			t.Fatal("expected the cached Snapshot error")
+		}
+	}
+	if hw.snapshots != sourceOpenAfterFailures {
+		t.Fatalf("Snapshot called %d times, want %d", hw.snapshots, sourceOpenAfterFailures)
+	}
+	st := p.sourceHealthStatus("default")
+	if st.State != "open" || st.LastError != "bridge closed the connection" {
+		t.Fatalf("sourceHealthStatus = %+v, want the Snapshot failure to open the circuit", st)
+	}
+	for i := 0; i < 5; i++ {
+		nextTick(p, rt)
+		_, err := p.snapshotForSource("default")
+		var open *sourceOpenError
+		if !errors.As(err, &open) {
+			t.Fatalf("tick %d: err = %v, want sourceOpenError", i, err)
+		}
+	}
+	if hw.snapshots != sourceOpenAfterFailures || hw.polls != sourceOpenAfterFailures {
+		t.Fatalf("open circuit fetched: %d Snapshot, %d PollTime calls", hw.snapshots, hw.polls)
+	}
+
+	// The probe closes the circuit only once Snapshot works again.
+	hw.snapErr = nil
+	p.mu.Lock()
+	rt.health.nextRetry = time.Now().Add(-time.Millisecond)
+	p.mu.Unlock()
+	nextTick(p, rt)
+	if _, err := p.snapshotForSource("default"); err != nil {
+		t.Fatalf("probe failed: %v", err)
+	}
+	if st := p.sourceHealthStatus("default"); st.State != "healthy" {
+		t.Fatalf("source not healthy after a good probe: %+v", st)
+	}
+}
+
+func TestOpenCircuitKeepsAuthFailure(t *testing.T) {
+	withoutJitter(t)
+	hw := &failingHardwareService{err: hwsensorsservice.AuthError("authentication failed: status 401")}
+	p, rt := newHealthTestPlugin(hw)
+
+	var err error
+	for i := 0; i <= sourceOpenAfterFailures; i++ {
+		nextTick(p, rt)
+		_, err = p.getCachedPollTimeForSource("default")
+	}
+	var open *sourceOpenError
+	if !errors.As(err, &open) || !errors.Is(err, hwsensorsservice.ErrAuthFailed) {
+		t.Fatalf("err = %v, want an open circuit wrapping the auth failure", err)
+	}
+	if got := unavailableTileText(err); got != "Auth failed" {
+		t.Fatalf("tile text = %q, want Auth failed", got)
+	}
+
+	// Within one tick, tiles after the first read the cached failure.
+	if _, err := p.getCachedPollTimeForSource("default"); !errors.Is(err, hwsensorsservice.ErrAuthFailed) {
+		t.Fatalf("cached err = %v, want the auth failure", err)
+	}
+}
+
+func TestEndpointChangeResetsHealth(t *testing.T) {
+	hw := &failingHardwareService{err: errors.New("connection refused")}
+	p, rt := newHealthTestPlugin(hw)
+	for i := 0; i < sourceOpenAfterFailures; i++ {
+		nextTick(p, rt)
+		_, _ = p.getCachedPollTimeForSource("default")
+	}
+
+	p.globalSettings.SourceProfiles[0].Host = "10.0.0.2"
+	p.reconcileSourceRuntime("default", rt)
+	if st := p.sourceHealthStatus("default"); st.State != "healthy" {
+		t.Fatalf("health after endpoint change = %+v, want healthy", st)
+	}
+}
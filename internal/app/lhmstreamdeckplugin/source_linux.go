@@ -7,31 +7,86 @@ import (
 	"log"
 	"net/http"
 	"os/exec"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
 
+	"github.com/moeilijk/lhm-streamdeck/internal/hwmon"
 	lhmplugin "github.com/moeilijk/lhm-streamdeck/internal/lhm/plugin"
 )
 
-// startLinuxSource wires rt.hw to lhm-companion over HTTP — the only sensor
-// source on Linux (#77): Windows = LHM (via lhm-bridge), Linux = lhm-companion.
-// No hwmon path and no fallbacks; an unreachable endpoint surfaces as the
-// explicit error state on the tiles.
+// startLinuxSource wires rt.hw to lhm-companion over HTTP (#77): Windows = LHM
+// (via lhm-bridge), Linux = lhm-companion. Profiles of type "hwmon" read
+// /sys/class/hwmon directly instead, so a machine without the companion still
+// has temperatures, fans, voltages, power and current. There are no implicit
+// fallbacks between the two; an unreachable endpoint surfaces as the explicit
+// error state on the tiles.
 //
 // For local profiles the bundled companion is supervised: a companion already
 // listening on the endpoint (e.g. a systemd service) is reused, otherwise the
 // bundled ./lhm-companion is spawned next to the plugin binary.
 func startLinuxSource(rt *sourceRuntime) error {
-	if isLocalHost(rt.profile.Host) {
+	switch rt.profile.Type {
+	case sourceTypeHwmon:
+		rt.hw = hwmon.NewService(hwmon.DefaultRoot)
+		return nil
+	case sourceTypeReplay:
+		hw, err := lhmplugin.NewHTTPService(lhmplugin.Endpoint{
+			Replay:      lhmplugin.ReplayOptions{File: rt.profile.ReplayFile, Speed: rt.profile.ReplaySpeed},
+			StatWindows: profileStatWindows(rt.profile),
+		})
+		if err != nil {
+			return err
+		}
+		rt.hw = hw
+		return nil
+	}
+	if servedByBundledCompanion(rt.profile) {
 		ensureLocalCompanion(normalizePort(rt.profile.Port))
 	}
-	rt.hw = lhmplugin.NewHTTPService(profileEndpoint(rt.profile))
+	hw, err := lhmplugin.NewHTTPService(profileHTTPEndpoint(rt.profile))
+	if err != nil {
+		return err
+	}
+	rt.hw = hw
 	return nil
 }
 
+// profileHTTPEndpoint translates a profile into the HTTP service's endpoint.
+func profileHTTPEndpoint(prof lhmSourceProfile) lhmplugin.Endpoint {
+	return lhmplugin.Endpoint{
+		URL:         profileEndpoint(prof),
+		Credentials: lhmplugin.Credentials{Username: prof.Username, Password: prof.Password, Token: prof.Token},
+		TLS:         lhmplugin.TLSOptions{CAFile: prof.CAFile, InsecureSkipVerify: prof.InsecureSkipVerify},
+		RecordDir:   prof.RecordDir,
+		StatWindows: profileStatWindows(prof),
+	}
+}
+
+// profileStatWindows parses the profile's stat windows the way the bridge
+// does with LHM_STAT_WINDOWS: invalid lists fall back to the defaults.
+func profileStatWindows(prof lhmSourceProfile) []time.Duration {
+	windows, err := lhmplugin.ParseStatWindows(prof.StatWindows)
+	if err != nil {
+		log.Printf("source %s: stat windows: %v, using defaults\n", prof.ID, err)
+	}
+	return windows
+}
+
+// servedByBundledCompanion reports whether the profile points at what the
+// bundled companion serves: plain http on localhost under /data.json. An https
+// or prefixed URL on localhost is a reverse proxy we must not compete with.
+func servedByBundledCompanion(prof lhmSourceProfile) bool {
+	if prof.Type != "" || !isLocalHost(prof.Host) || strings.EqualFold(prof.Scheme, "https") {
+		return false
+	}
+	path := strings.Trim(strings.TrimSpace(prof.Path), "/")
+	return path == "" || path == "data.json"
+}
+
 func isLocalHost(host string) bool {
-	switch host {
+	switch strings.TrimSuffix(strings.TrimPrefix(host, "["), "]") {
 	case "", "127.0.0.1", "localhost", "::1":
 		return true
 	}
@@ -138,7 +193,12 @@ func endpointReachable(port int) bool {
 		return false
 	}
 	_ = resp.Body.Close()
-	return resp.StatusCode == http.StatusOK
+	// A companion that rejects our unauthenticated probe is still running.
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusUnauthorized, http.StatusForbidden:
+		return true
+	}
+	return false
 }
 
 // spawnCompanion starts the bundled companion binary (working directory is the
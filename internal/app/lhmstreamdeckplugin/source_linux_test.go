@@ -2,10 +2,14 @@
 
 package lhmstreamdeckplugin
 
-import "testing"
+import (
+	"slices"
+	"testing"
+	"time"
+)
 
 func TestIsLocalHost(t *testing.T) {
-	local := []string{"", "127.0.0.1", "localhost", "::1"}
+	local := []string{"", "127.0.0.1", "localhost", "::1", "[::1]"}
 	for _, h := range local {
 		if !isLocalHost(h) {
 			t.Errorf("isLocalHost(%q) = false, want true", h)
@@ -19,6 +23,35 @@ func TestIsLocalHost(t *testing.T) {
 	}
 }
 
+func TestServedByBundledCompanion(t *testing.T) {
+	cases := []struct {
+		prof lhmSourceProfile
+		want bool
+	}{
+		{lhmSourceProfile{Host: "127.0.0.1", Port: 8085}, true},
+		{lhmSourceProfile{Host: "::1", Port: 8085, Scheme: "http", Path: "/data.json"}, true},
+		{lhmSourceProfile{Host: "localhost", Port: 443, Scheme: "https"}, false},
+		{lhmSourceProfile{Host: "127.0.0.1", Port: 8080, Path: "/lhm/data.json"}, false},
+		{lhmSourceProfile{Host: "192.168.1.10", Port: 8085}, false},
+		{lhmSourceProfile{Type: sourceTypeReplay, ReplayFile: "/tmp/incident.jsonl"}, false},
+	}
+	for _, tc := range cases {
+		if got := servedByBundledCompanion(tc.prof); got != tc.want {
+			t.Errorf("servedByBundledCompanion(%+v) = %v, want %v", tc.prof, got, tc.want)
+		}
+	}
+}
+
+func TestProfileHTTPEndpointStatWindows(t *testing.T) {
+	ep := profileHTTPEndpoint(lhmSourceProfile{Host: "127.0.0.1", Port: 8085, StatWindows: "30s, 5m"})
+	if want := []time.Duration{30 * time.Second, 5 * time.Minute}; !slices.Equal(ep.StatWindows, want) {
+		t.Fatalf("StatWindows = %v, want %v", ep.StatWindows, want)
+	}
+	if ep := profileHTTPEndpoint(lhmSourceProfile{StatWindows: "soon"}); ep.StatWindows != nil {
+		t.Fatalf("invalid stat windows = %v, want the defaults (nil)", ep.StatWindows)
+	}
+}
+
 func TestNormalizePort(t *testing.T) {
 	cases := map[int]int{0: 8085, -1: 8085, 70000: 8085, 8085: 8085, 9999: 9999}
 	for in, want := range cases {
@@ -0,0 +1,95 @@
+package lhmstreamdeckplugin
+
+import (
+	"fmt"
+
+	hwsensorsservice "github.com/moeilijk/lhm-streamdeck/pkg/service"
+)
+
+// readingRef identifies one reading of a source.
+type readingRef struct {
+	sensorUID string
+	readingID int32
+}
+
+// sourceView is one poll of a source as the renderers see it: the snapshot
+// plus its readings indexed by sensor and reading ID. It is built once per
+// snapshot and shared by every tile, composite slot, derived slot and dial
+// page of the source, so a lookup is a map access instead of a lock round
+// trip and a scan of the sensor's readings.
+type sourceView struct {
+	snap     *hwsensorsservice.Snapshot
+	readings map[readingRef]hwsensorsservice.Reading
+}
+
+func newSourceView(snap *hwsensorsservice.Snapshot) *sourceView {
+	n := 0
+	for _, rs := range snap.Readings {
+		n += len(rs)
+	}
+	v := &sourceView{snap: snap, readings: make(map[readingRef]hwsensorsservice.Reading, n)}
+	for suid, rs := range snap.Readings {
+		for _, r := range rs {
+			// Keep the first reading when a sensor repeats an ID, as the
+			// linear lookup this replaces did.
+			ref := readingRef{sensorUID: suid, readingID: r.ID()}
+			if _, dup := v.readings[ref]; !dup {
+				v.readings[ref] = r
+			}
+		}
+	}
+	return v
+}
+
+// reading returns a single reading of the view.
+func (v *sourceView) reading(suid string, rid int32) (hwsensorsservice.Reading, error) {
+	if r, ok := v.readings[readingRef{sensorUID: suid, readingID: rid}]; ok {
+		return r, nil
+	}
+	rs, err := v.snap.ReadingsForSensorID(suid)
+	if err != nil {
+		return nil, fmt.Errorf("getReading ReadingsBySensor failed: %v", err)
+	}
+	// Lazy snapshots of older bridges are not indexed; their readings are
+	// only fetched here, once per sensor and poll.
+	for _, r := range rs {
+		if r.ID() == rid {
+			return r, nil
+		}
+	}
+	return nil, fmt.Errorf("ReadingID does not exist: %s", suid)
+}
+
+// viewForSource returns the view of a profile's current snapshot, building it
+// the first time the snapshot is read.
+func (p *Plugin) viewForSource(profileID string) (*sourceView, error) {
+	// Renderers check the poll time first, which already reconciled the
+	// runtime with its profile this tick; only a new profile needs that here.
+	p.sourceMu.RLock()
+	rt := p.sources[profileID]
+	p.sourceMu.RUnlock()
+	if rt == nil {
+		rt = p.runtimeForSource(profileID)
+	}
+	snap, err := p.snapshotForRuntime(rt)
+	if err != nil {
+		return nil, fmt.Errorf("getReading Snapshot failed: %w", err)
+	}
+
+	p.mu.RLock()
+	v := rt.view
+	p.mu.RUnlock()
+	if v != nil && v.snap == snap {
+		return v, nil
+	}
+
+	v = newSourceView(snap)
+	p.mu.Lock()
+	if rt.view != nil && rt.view.snap == snap {
+		v = rt.view
+	} else {
+		rt.view = v
+	}
+	p.mu.Unlock()
+	return v, nil
+}
@@ -0,0 +1,274 @@
+package lhmstreamdeckplugin
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	hwsensorsservice "github.com/moeilijk/lhm-streamdeck/pkg/service"
+)
+
+func TestSourceViewIndexesReadings(t *testing.T) {
+	v := newSourceView(&hwsensorsservice.Snapshot{
+		Readings: map[string][]hwsensorsservice.Reading{
+			"/cpu": {
+				stubReading{id: 7, label: "CPU Total"},
+				stubReading{id: 7, label: "duplicate"},
+				stubReading{id: 8, label: "CPU Core #1"},
+			},
+		},
+	})
+	if r, err := v.reading("/cpu", 7); err != nil || r.Label() != "CPU Total" {
+		t.Fatalf("reading(/cpu, 7) = %v, %v", r, err)
+	}
+	if r, err := v.reading("/cpu", 8); err != nil || r.Label() != "CPU Core #1" {
+		t.Fatalf("reading(/cpu, 8) = %v, %v", r, err)
+	}
+	if _, err := v.reading("/cpu", 9); err == nil || !strings.Contains(err.Error(), "ReadingID does not exist") {
+		t.Fatalf("missing reading err = %v", err)
+	}
+	if _, err := v.reading("/gpu", 7); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("missing sensor err = %v", err)
+	}
+}
+
+func TestViewForSourceIsSharedWithinAPoll(t *testing.T) {
+	hw := &rpcCountingHardwareService{readings: benchReadingTable()}
+	p, rt := newHealthTestPlugin(hw)
+
+	first, err := p.viewForSource("default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _ := p.viewForSource("default")
+	if again != first {
+		t.Fatal("second lookup in the same poll built a new view")
+	}
+	if hw.snapshots.Load() != 1 {
+		t.Fatalf("Snapshot calls = %d, want 1", hw.snapshots.Load())
+	}
+
+	nextTick(p, rt)
+	next, _ := p.viewForSource("default")
+	if next == first || next.snap.PollTime == first.snap.PollTime {
+		t.Fatal("new poll kept the old view")
+	}
+}
+
+// legacyBridgeHardwareService answers Snapshot the way the gRPC client does
+// for a bridge without the Snapshot RPC.
+type legacyBridgeHardwareService struct {
+	rpcCountingHardwareService
+}
+
+func (s *legacyBridgeHardwareService) Snapshot(pollTime uint64) (*hwsensorsservice.Snapshot, error) {
+	s.snapshots.Add(1)
+	return hwsensorsservice.LazySnapshot(s, pollTime), nil
+}
+
+func TestLazySnapshotOnlyFetchesReadSensors(t *testing.T) {
+	hw := &legacyBridgeHardwareService{rpcCountingHardwareService{readings: benchReadingTable()}}
+	p, _ := newHealthTestPlugin(hw)
+
+	for i := 0; i < 5; i++ {
+		for _, rid := range []int32{3, 4} {
+			r, _, err := p.getReadingForSource("default", "/sensor/2", rid)
+			if err != nil || r.ID() != rid {
+				t.Fatalf("getReadingForSource(/sensor/2, %d) = %v, %v", rid, r, err)
+			}
+		}
+	}
+	if _, _, err := p.getReadingForSource("default", "/sensor/2", 99); err == nil {
+		t.Fatal("expected an error for a missing reading")
+	}
+	// One PollTime and one ReadingsForSensorID for the only sensor in use.
+	if got := hw.rpcs.Load(); got != 2 {
+		t.Fatalf("RPCs = %d, want 2", got)
+	}
+}
+
+// rpcCountingHardwareService serves a fixed reading table under a new poll
+// time on every PollTime call and counts the calls that would be RPCs.
+type rpcCountingHardwareService struct {
+	readings  map[string][]hwsensorsservice.Reading
+	pollTime  atomic.Uint64
+	rpcs      atomic.Int64
+	snapshots atomic.Int64
+}
+
+func (s *rpcCountingHardwareService) PollTime() (uint64, error) {
+	s.rpcs.Add(1)
+	return s.pollTime.Add(1), nil
+}
+
+func (s *rpcCountingHardwareService) Sensors() ([]hwsensorsservice.Sensor, error) {
+	s.rpcs.Add(1)
+	return nil, nil
+}
+
+func (s *rpcCountingHardwareService) ReadingsForSensorID(id string) ([]hwsensorsservice.Reading, error) {
+	s.rpcs.Add(1)
+	rs, ok := s.readings[id]
+	if !ok {
+		return nil, fmt.Errorf("sensor %s not found", id)
+	}
+	return append([]hwsensorsservice.Reading(nil), rs...), nil
+}
+
+func (s *rpcCountingHardwareService) Snapshot(pollTime uint64) (*hwsensorsservice.Snapshot, error) {
+	s.rpcs.Add(1)
+	s.snapshots.Add(1)
+	return &hwsensorsservice.Snapshot{PollTime: pollTime, Readings: s.readings}, nil
+}
+
+const (
+	benchSensors        = 10
+	benchReadingsPerSen = 25
+	benchTiles          = 30
+	benchComposites     = 4
+	benchCompositeSlots = 4
+	benchDials          = 2
+	benchDialPages      = 4
+)
+
+func benchReadingTable() map[string][]hwsensorsservice.Reading {
+	table := make(map[string][]hwsensorsservice.Reading, benchSensors)
+	for s := 0; s < benchSensors; s++ {
+		rs := make([]hwsensorsservice.Reading, benchReadingsPerSen)
+		for r := range rs {
+			rs[r] = stubReading{id: int32(r), label: fmt.Sprintf("Reading %d", r), unit: "°C"}
+		}
+		table[fmt.Sprintf("/sensor/%d", s)] = rs
+	}
+	return table
+}
+
+// benchDeck is what one tick renders: the reading refs of 30 tiles, 4
+// composites with 4 slots and 2 dials with 4 pages.
+type benchDeck struct {
+	tiles      []readingRef
+	composites [][]readingRef
+	dials      [][]readingRef
+}
+
+func newBenchDeck() benchDeck {
+	n := 0
+	next := func() readingRef {
+		ref := readingRef{
+			sensorUID: fmt.Sprintf("/sensor/%d", n%benchSensors),
+			readingID: int32((n * 7) % benchReadingsPerSen),
+		}
+		n++
+		return ref
+	}
+	group := func(count, size int) [][]readingRef {
+		out := make([][]readingRef, count)
+		for i := range out {
+			for j := 0; j < size; j++ {
+				out[i] = append(out[i], next())
+			}
+		}
+		return out
+	}
+	var d benchDeck
+	for i := 0; i < benchTiles; i++ {
+		d.tiles = append(d.tiles, next())
+	}
+	d.composites = group(benchComposites, benchCompositeSlots)
+	d.dials = group(benchDials, benchDialPages)
+	return d
+}
+
+func (d benchDeck) lookups() []readingRef {
+	refs := append([]readingRef(nil), d.tiles...)
+	for _, c := range d.composites {
+		refs = append(refs, c...)
+	}
+	for _, dial := range d.dials {
+		refs = append(refs, dial...)
+	}
+	return refs
+}
+
+func scanReadings(rs []hwsensorsservice.Reading, id int32) hwsensorsservice.Reading {
+	for _, r := range rs {
+		if r.ID() == id {
+			return r
+		}
+	}
+	return nil
+}
+
+// BenchmarkTickDeck renders the readings of one tick of 30 tiles, 4
+// composites and 2 dials. ReadingsForSensorID is the path from before
+// snapshots (an RPC per lookup), Snapshot the per-lookup snapshot fetch and
+// scan that followed, and SourceView the shared index every renderer now
+// reads from.
+func BenchmarkTickDeck(b *testing.B) {
+	deck := newBenchDeck()
+	lookups := deck.lookups()
+	groups := append(deck.composites, deck.dials...)
+
+	run := func(b *testing.B, tick func(p *Plugin, hw *rpcCountingHardwareService)) {
+		hw := &rpcCountingHardwareService{readings: benchReadingTable()}
+		p, rt := newHealthTestPlugin(hw)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			nextTick(p, rt)
+			tick(p, hw)
+		}
+		b.ReportMetric(float64(hw.rpcs.Load())/float64(b.N), "rpcs/op")
+	}
+
+	b.Run("ReadingsForSensorID", func(b *testing.B) {
+		run(b, func(p *Plugin, hw *rpcCountingHardwareService) {
+			for _, ref := range lookups {
+				if _, err := p.getCachedPollTimeForSource("default"); err != nil {
+					b.Fatal(err)
+				}
+				rs, err := hw.ReadingsForSensorID(ref.sensorUID)
+				if err != nil || scanReadings(rs, ref.readingID) == nil {
+					b.Fatalf("%v missing: %v", ref, err)
+				}
+			}
+		})
+	})
+
+	b.Run("Snapshot", func(b *testing.B) {
+		run(b, func(p *Plugin, hw *rpcCountingHardwareService) {
+			for _, ref := range lookups {
+				snap, err := p.snapshotForSource("default")
+				if err != nil {
+					b.Fatal(err)
+				}
+				rs, err := snap.ReadingsForSensorID(ref.sensorUID)
+				if err != nil || scanReadings(rs, ref.readingID) == nil {
+					b.Fatalf("%v missing: %v", ref, err)
+				}
+			}
+		})
+	})
+
+	b.Run("SourceView", func(b *testing.B) {
+		run(b, func(p *Plugin, hw *rpcCountingHardwareService) {
+			for _, ref := range deck.tiles {
+				if _, _, err := p.getReadingForSource("default", ref.sensorUID, ref.readingID); err != nil {
+					b.Fatal(err)
+				}
+			}
+			for _, slots := range groups {
+				v, err := p.viewForSource("default")
+				if err != nil {
+					b.Fatal(err)
+				}
+				for _, ref := range slots {
+					if _, err := v.reading(ref.sensorUID, ref.readingID); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+	})
+}
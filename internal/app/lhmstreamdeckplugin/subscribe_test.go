@@ -0,0 +1,239 @@
+package lhmstreamdeckplugin
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	hwsensorsservice "github.com/moeilijk/lhm-streamdeck/pkg/service"
+)
+
+// pushOnlyHardwareService fails every polling call so tests notice when the
+// plugin bypasses a pushed snapshot.
+type pushOnlyHardwareService struct {
+	ch chan *hwsensorsservice.Snapshot
+}
+
+func (s *pushOnlyHardwareService) PollTime() (uint64, error) {
+	return 0, errors.New("PollTime must not be called while pushing")
+}
+
+func (s *pushOnlyHardwareService) Sensors() ([]hwsensorsservice.Sensor, error) {
+	return nil, errors.New("Sensors must not be called while pushing")
+}
+
+func (s *pushOnlyHardwareService) ReadingsForSensorID(id string) ([]hwsensorsservice.Reading, error) {
+	return nil, errors.New("ReadingsForSensorID must not be called while pushing")
+}
+
+func (s *pushOnlyHardwareService) Snapshot(pollTime uint64) (*hwsensorsservice.Snapshot, error) {
+	return nil, errors.New("Snapshot must not be called while pushing")
+}
+
+func (s *pushOnlyHardwareService) Subscribe(ctx context.Context, interval time.Duration) (<-chan *hwsensorsservice.Snapshot, error) {
+	return s.ch, nil
+}
+
+func waitForSnapshot(t *testing.T, p *Plugin, rt *sourceRuntime, pollTime uint64) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if snap := p.pushedSnapshot(rt); snap != nil && snap.PollTime == pollTime {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("snapshot with poll time %d was not stored", pollTime)
+}
+
+func TestPushedSnapshotReplacesPolling(t *testing.T) {
+	const profileID = "default"
+	hw := &pushOnlyHardwareService{ch: make(chan *hwsensorsservice.Snapshot, 1)}
+	p := &Plugin{
+		sources: make(map[string]*sourceRuntime),
+		globalSettings: globalSettings{
+			SourceProfiles:         []lhmSourceProfile{{ID: profileID, Name: "Default", Host: "127.0.0.1", Port: 8085}},
+			DefaultSourceProfileID: profileID,
+		},
+	}
+	rt := &sourceRuntime{profile: p.globalSettings.SourceProfiles[0], hw: hw}
+	p.sources[profileID] = rt
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		p.consumeSnapshots(ctx, rt, rt.profile.ID, hw, time.Second)
+		close(done)
+	}()
+
+	hw.ch <- &hwsensorsservice.Snapshot{
+		PollTime: 1234,
+		Sensors:  []hwsensorsservice.Sensor{},
+		Readings: map[string][]hwsensorsservice.Reading{
+			"/cpu": {stubReading{id: 7, label: "CPU Total", unit: "%"}},
+		},
+	}
+	waitForSnapshot(t, p, rt, 1234)
+
+	pt, err := p.getCachedPollTimeForSource(profileID)
+	if err != nil || pt != 1234 {
+		t.Fatalf("getCachedPollTimeForSource = %d, %v; want 1234", pt, err)
+	}
+	r, _, err := p.getReadingForSource(profileID, "/cpu", 7)
+	if err != nil || r.Label() != "CPU Total" {
+		t.Fatalf("getReadingForSource = %v, %v", r, err)
+	}
+	if _, err := p.sensorsWithTimeoutForSource(profileID, time.Second); err != nil {
+		t.Fatalf("sensorsWithTimeoutForSource: %v", err)
+	}
+
+	hw.ch <- &hwsensorsservice.Snapshot{PollTime: 1235, Err: errors.New("LHM unreachable")}
+	waitForSnapshot(t, p, rt, 1235)
+	if _, err := p.getCachedPollTimeForSource(profileID); err == nil || err.Error() != "LHM unreachable" {
+		t.Fatalf("expected pushed error, got %v", err)
+	}
+
+	// A closed stream drops the snapshot so the runtime polls again.
+	close(hw.ch)
+	deadline := time.Now().Add(2 * time.Second)
+	for p.pushedSnapshot(rt) != nil {
+		if time.Now().After(deadline) {
+			t.Fatalf("snapshot kept after the stream ended: %+v", p.pushedSnapshot(rt))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	<-done
+}
+
+// flakyStreamService hands out a new stream on every Subscribe call.
+type flakyStreamService struct {
+	pushOnlyHardwareService
+	streams chan chan *hwsensorsservice.Snapshot
+}
+
+func (s *flakyStreamService) Subscribe(ctx context.Context, interval time.Duration) (<-chan *hwsensorsservice.Snapshot, error) {
+	select {
+	case ch := <-s.streams:
+		return ch, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestEndedStreamIsResubscribed(t *testing.T) {
+	defer func(d time.Duration) { subscribeRetryBase = d }(subscribeRetryBase)
+	subscribeRetryBase = time.Millisecond
+
+	hw := &flakyStreamService{streams: make(chan chan *hwsensorsservice.Snapshot, 2)}
+	p := &Plugin{sources: make(map[string]*sourceRuntime)}
+	rt := &sourceRuntime{hw: hw}
+
+	first := make(chan *hwsensorsservice.Snapshot, 1)
+	first <- &hwsensorsservice.Snapshot{PollTime: 1}
+	close(first)
+	second := make(chan *hwsensorsservice.Snapshot, 1)
+	second <- &hwsensorsservice.Snapshot{PollTime: 2}
+	hw.streams <- first
+	hw.streams <- second
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		p.consumeSnapshots(ctx, rt, "default", hw, time.Second)
+		close(done)
+	}()
+	waitForSnapshot(t, p, rt, 2)
+	cancel()
+	close(second)
+	<-done
+}
+
+func TestSubscribeRetryDelayIsCapped(t *testing.T) {
+	if d := subscribeRetryDelay(1); d != subscribeRetryBase {
+		t.Fatalf("first retry delay = %v, want %v", d, subscribeRetryBase)
+	}
+	if d := subscribeRetryDelay(2); d != 2*subscribeRetryBase {
+		t.Fatalf("second retry delay = %v, want %v", d, 2*subscribeRetryBase)
+	}
+	if d := subscribeRetryDelay(100); d != subscribeRetryMax {
+		t.Fatalf("retry delay after 100 attempts = %v, want %v", d, subscribeRetryMax)
+	}
+}
+
+func TestCancelledSubscriptionDoesNotStoreSnapshots(t *testing.T) {
+	hw := &pushOnlyHardwareService{ch: make(chan *hwsensorsservice.Snapshot, 1)}
+	p := &Plugin{sources: make(map[string]*sourceRuntime)}
+	rt := &sourceRuntime{hw: hw}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	hw.ch <- &hwsensorsservice.Snapshot{PollTime: 1}
+	close(hw.ch)
+	p.consumeSnapshots(ctx, rt, rt.profile.ID, hw, time.Second)
+
+	if snap := p.pushedSnapshot(rt); snap != nil {
+		t.Fatalf("cancelled subscription stored a snapshot: %+v", snap)
+	}
+}
+
+// countingHardwareService serves one sensor and counts Snapshot calls.
+type countingHardwareService struct {
+	stubHardwareService
+	pollTime  uint64
+	snapshots int
+}
+
+func (s *countingHardwareService) PollTime() (uint64, error) {
+	return s.pollTime, nil
+}
+
+func (s *countingHardwareService) ReadingsForSensorID(id string) ([]hwsensorsservice.Reading, error) {
+	return nil, errors.New("ReadingsForSensorID must not be called per tile")
+}
+
+func (s *countingHardwareService) Snapshot(pollTime uint64) (*hwsensorsservice.Snapshot, error) {
+	s.snapshots++
+	return s.stubHardwareService.Snapshot(pollTime)
+}
+
+func TestPolledSnapshotFetchedOncePerPollTime(t *testing.T) {
+	const profileID = "default"
+	hw := &countingHardwareService{
+		stubHardwareService: stubHardwareService{readingsBySensor: map[string][]hwsensorsservice.Reading{
+			"/cpu": {stubReading{id: 7, label: "CPU Total", unit: "%"}},
+		}},
+		pollTime: 100,
+	}
+	p := &Plugin{
+		sources: make(map[string]*sourceRuntime),
+		globalSettings: globalSettings{
+			SourceProfiles:         []lhmSourceProfile{{ID: profileID, Name: "Default", Host: "127.0.0.1", Port: 8085}},
+			DefaultSourceProfileID: profileID,
+		},
+	}
+	rt := &sourceRuntime{profile: p.globalSettings.SourceProfiles[0], hw: hw}
+	p.sources[profileID] = rt
+
+	tick := func() {
+		for i := 0; i < 50; i++ {
+			if _, _, err := p.getReadingForSource(profileID, "/cpu", 7); err != nil {
+				t.Fatalf("getReadingForSource: %v", err)
+			}
+		}
+	}
+	tick()
+	if hw.snapshots != 1 {
+		t.Fatalf("Snapshot called %d times for one tick, want 1", hw.snapshots)
+	}
+
+	hw.pollTime = 200
+	p.mu.Lock()
+	invalidatePollCacheForRuntime(rt)
+	p.mu.Unlock()
+	tick()
+	if hw.snapshots != 2 {
+		t.Fatalf("Snapshot called %d times after a new poll, want 2", hw.snapshots)
+	}
+}
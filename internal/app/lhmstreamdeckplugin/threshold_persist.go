@@ -0,0 +1,240 @@
+package lhmstreamdeckplugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	thresholdStateFile    = "threshold-state.json"
+	thresholdStateVersion = 1
+
+	// parkedThresholdMaxAge is how long the alarm state of a context that is
+	// not on screen is kept. It covers pages nobody visits for a while; past
+	// it the tile has most likely been deleted.
+	parkedThresholdMaxAge = 7 * 24 * time.Hour
+)
+
+// savedThresholdContext is the threshold state of one context (a tile, a
+// composite slot or a dial page) that outlives its willDisappear. Saved is
+// when it was parked; it is zero in the file for contexts that were still on
+// screen, so the file only changes when the state does.
+type savedThresholdContext struct {
+	States map[string]thresholdRuntimeState `json:"states,omitempty"`
+	Snooze *thresholdSnoozeState            `json:"snooze,omitempty"`
+	Saved  time.Time                        `json:"saved,omitzero"`
+}
+
+type thresholdStateContent struct {
+	Version  int                               `json:"version"`
+	Contexts map[string]*savedThresholdContext `json:"contexts"`
+}
+
+// stateFile is a file in the plugin directory that is only rewritten when
+// its content changes.
+type stateFile struct {
+	mu   sync.Mutex
+	path string
+	last []byte
+}
+
+func (f *stateFile) write(data []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.path == "" || bytes.Equal(data, f.last) {
+		return nil
+	}
+	if err := writeFileAtomic(f.path, data); err != nil {
+		return err
+	}
+	f.last = data
+	return nil
+}
+
+// worthKeeping reports whether state says more than a fresh state would. A
+// pending dwell is not kept: the value may have dropped while the plugin was
+// not watching, so the dwell starts over.
+func (s *thresholdRuntimeState) worthKeeping() bool {
+	return s.Active || s.Latched || s.SuppressedUntilClear || !s.CooldownUntil.IsZero()
+}
+
+// captureThresholdStateLocked copies the live threshold state of context, or
+// returns nil if there is nothing to keep. Requires p.mu.
+func (p *Plugin) captureThresholdStateLocked(context string, now time.Time) *savedThresholdContext {
+	saved := &savedThresholdContext{Saved: now}
+	for id, st := range p.thresholdStates[context] {
+		if st == nil || !st.worthKeeping() {
+			continue
+		}
+		if saved.States == nil {
+			saved.States = make(map[string]thresholdRuntimeState)
+		}
+		kept := *st
+		kept.PendingSince = time.Time{}
+		saved.States[id] = kept
+	}
+	if sn := p.thresholdSnoozes[context]; sn != nil && !sn.expired(now) {
+		kept := *sn
+		saved.Snooze = &kept
+	}
+	if saved.States == nil && saved.Snooze == nil {
+		return nil
+	}
+	return saved
+}
+
+// parkThresholdRuntimeState moves the threshold state of a context that
+// disappears out of the live maps, to be restored when it appears again.
+func (p *Plugin) parkThresholdRuntimeState(context string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	saved := p.captureThresholdStateLocked(context, time.Now())
+	delete(p.thresholdStates, context)
+	delete(p.thresholdSnoozes, context)
+	delete(p.thresholdDirty, context)
+	if saved == nil {
+		delete(p.parkedThresholds, context)
+		return
+	}
+	if p.parkedThresholds == nil {
+		p.parkedThresholds = make(map[string]*savedThresholdContext)
+	}
+	p.parkedThresholds[context] = saved
+}
+
+// restoreThresholdRuntimeState brings back the parked threshold state of a
+// context that appears. Live state, if any, wins; a snooze that ran out
+// while the context was away is dropped.
+func (p *Plugin) restoreThresholdRuntimeState(context string, now time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	saved := p.parkedThresholds[context]
+	if saved == nil {
+		return
+	}
+	delete(p.parkedThresholds, context)
+	if len(saved.States) > 0 {
+		if p.thresholdStates == nil {
+			p.thresholdStates = make(map[string]map[string]*thresholdRuntimeState)
+		}
+		states := p.thresholdStates[context]
+		if states == nil {
+			states = make(map[string]*thresholdRuntimeState, len(saved.States))
+			p.thresholdStates[context] = states
+		}
+		for id, st := range saved.States {
+			if _, live := states[id]; !live {
+				states[id] = &st
+			}
+		}
+	}
+	if sn := saved.Snooze; sn != nil && !sn.expired(now) {
+		if p.thresholdSnoozes == nil {
+			p.thresholdSnoozes = make(map[string]*thresholdSnoozeState)
+		}
+		if _, live := p.thresholdSnoozes[context]; !live {
+			p.thresholdSnoozes[context] = sn
+		}
+	}
+	if p.thresholdDirty == nil {
+		p.thresholdDirty = make(map[string]bool)
+	}
+	p.thresholdDirty[context] = true
+}
+
+// thresholdStateSnapshot collects the live and parked threshold state worth
+// saving.
+func (p *Plugin) thresholdStateSnapshot(now time.Time) thresholdStateContent {
+	content := thresholdStateContent{Version: thresholdStateVersion, Contexts: make(map[string]*savedThresholdContext)}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	for ctx, saved := range p.parkedThresholds {
+		if now.Sub(saved.Saved) > parkedThresholdMaxAge {
+			continue
+		}
+		if saved.Snooze != nil && saved.Snooze.expired(now) {
+			if len(saved.States) == 0 {
+				continue
+			}
+			kept := *saved
+			kept.Snooze = nil
+			saved = &kept
+		}
+		content.Contexts[ctx] = saved
+	}
+	live := func(ctx string) {
+		if saved := p.captureThresholdStateLocked(ctx, now); saved != nil {
+			saved.Saved = time.Time{}
+			content.Contexts[ctx] = saved
+		}
+	}
+	for ctx := range p.thresholdStates {
+		live(ctx)
+	}
+	for ctx := range p.thresholdSnoozes {
+		live(ctx)
+	}
+	return content
+}
+
+// saveThresholdState writes the threshold state file.
+func (p *Plugin) saveThresholdState() {
+	data, err := json.Marshal(p.thresholdStateSnapshot(time.Now()))
+	if err == nil {
+		err = p.thresholdFile.write(data)
+	}
+	if err != nil {
+		log.Printf("threshold state save: %v\n", err)
+	}
+}
+
+// loadThresholdState parks the threshold state saved by the previous run, to
+// be restored as the contexts appear.
+func (p *Plugin) loadThresholdState(now time.Time) error {
+	path := p.thresholdFile.path
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var content thresholdStateContent
+	if err := json.Unmarshal(data, &content); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if content.Version != thresholdStateVersion {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.parkedThresholds == nil {
+		p.parkedThresholds = make(map[string]*savedThresholdContext)
+	}
+	for ctx, saved := range content.Contexts {
+		if saved == nil {
+			continue
+		}
+		if saved.Saved.IsZero() {
+			saved.Saved = now
+		}
+		if now.Sub(saved.Saved) > parkedThresholdMaxAge {
+			continue
+		}
+		if saved.Snooze != nil && saved.Snooze.expired(now) {
+			saved.Snooze = nil
+		}
+		if len(saved.States) == 0 && saved.Snooze == nil {
+			continue
+		}
+		p.parkedThresholds[ctx] = saved
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package lhmstreamdeckplugin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestThresholdStateSurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), thresholdStateFile)
+	now := time.Now()
+	page := dialPageContext("dial", 1)
+
+	old := &Plugin{thresholdDirty: make(map[string]bool)}
+	old.thresholdFile.path = path
+	old.thresholdStates = map[string]map[string]*thresholdRuntimeState{
+		"tile": {"crit": {Active: true, Latched: true, LatchedValue: 97, LatchedDisplayText: "97 °C"}},
+		page:   {"warn": {PendingSince: now}},
+	}
+	old.setThresholdSnooze(page, time.Hour, now.Add(-10*time.Minute))
+	old.setThresholdSnooze("short", 5*time.Minute, now.Add(-time.Minute))
+	old.saveThresholdState()
+
+	// The plugin is down long enough for the short snooze to run out.
+	later := now.Add(5 * time.Minute)
+	p := &Plugin{thresholdDirty: make(map[string]bool)}
+	p.thresholdFile.path = path
+	if err := p.loadThresholdState(later); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	for _, ctx := range []string{"tile", page, "short"} {
+		p.restoreThresholdRuntimeState(ctx, later)
+	}
+
+	st := p.thresholdStates["tile"]["crit"]
+	if st == nil || !st.Latched || st.LatchedDisplayText != "97 °C" {
+		t.Fatalf("latched alarm not restored: %+v", st)
+	}
+	if !p.thresholdDirty["tile"] {
+		t.Fatal("restored tile not marked for a redraw")
+	}
+	if _, ok := p.thresholdStates[page]["warn"]; ok {
+		t.Fatal("pending dwell restored; it should start over")
+	}
+	snooze, ok := p.currentThresholdSnooze(page, later)
+	if !ok || !snooze.Until.Equal(now.Add(50*time.Minute)) {
+		t.Fatalf("dial page snooze = %+v, %v; want the original deadline", snooze, ok)
+	}
+	if _, ok := p.currentThresholdSnooze("short", later); ok {
+		t.Fatal("snooze that expired while the plugin was down was restored")
+	}
+}
+
+func TestParkedThresholdStateRestoresOnAppear(t *testing.T) {
+	p := &Plugin{thresholdDirty: make(map[string]bool)}
+	now := time.Now()
+	p.thresholdStates = map[string]map[string]*thresholdRuntimeState{
+		"ctx": {"crit": {Active: true, Latched: true}},
+	}
+	p.setThresholdSnooze("ctx", 0, now)
+
+	p.parkThresholdRuntimeState("ctx")
+	if len(p.thresholdStates) != 0 || len(p.thresholdSnoozes) != 0 {
+		t.Fatal("parked state still live")
+	}
+
+	// State the tile built up since it appeared again wins over parked state.
+	p.thresholdStates["ctx"] = map[string]*thresholdRuntimeState{"crit": {Active: true}}
+	p.restoreThresholdRuntimeState("ctx", now)
+	if p.thresholdStates["ctx"]["crit"].Latched {
+		t.Fatal("parked state overwrote live state")
+	}
+	if _, ok := p.currentThresholdSnooze("ctx", now.Add(24*time.Hour)); !ok {
+		t.Fatal("indefinite snooze not restored")
+	}
+	if len(p.parkedThresholds) != 0 {
+		t.Fatal("restored state still parked")
+	}
+}
+
+func TestThresholdStateFileOnlyRewrittenOnChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), thresholdStateFile)
+	p := &Plugin{thresholdDirty: make(map[string]bool)}
+	p.thresholdFile.path = path
+	p.thresholdStates = map[string]map[string]*thresholdRuntimeState{
+		"ctx": {"crit": {Active: true, Latched: true}},
+	}
+	p.saveThresholdState()
+	if err := os.Remove(path); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+
+	p.saveThresholdState()
+	if _, err := os.Stat(path); err == nil {
+		t.Fatal("unchanged state was written again")
+	}
+
+	p.thresholdStates["ctx"]["crit"].Latched = false
+	p.saveThresholdState()
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("changed state was not written: %v", err)
+	}
+}
@@ -6,23 +6,32 @@ import (
 	"time"
 )
 
+// thresholdRuntimeState is the alarm state of one threshold on one context.
+// It is saved to the threshold state file, so times are wall-clock times.
 type thresholdRuntimeState struct {
-	PendingSince         time.Time
-	CooldownUntil        time.Time
-	Active               bool
-	Latched              bool
-	SuppressedUntilClear bool
-	SnapshotPending      bool
-	LatchedValue         float64
-	LatchedGraphValue    float64
-	LatchedDisplayText   string
-	LatchedAlertText     string
+	PendingSince         time.Time `json:"pendingSince,omitzero"`
+	CooldownUntil        time.Time `json:"cooldownUntil,omitzero"`
+	Active               bool      `json:"active,omitempty"`
+	Latched              bool      `json:"latched,omitempty"`
+	SuppressedUntilClear bool      `json:"suppressedUntilClear,omitempty"`
+	SnapshotPending      bool      `json:"snapshotPending,omitempty"`
+	LatchedValue         float64   `json:"latchedValue,omitempty"`
+	LatchedGraphValue    float64   `json:"latchedGraphValue,omitempty"`
+	LatchedDisplayText   string    `json:"latchedDisplayText,omitempty"`
+	LatchedAlertText     string    `json:"latchedAlertText,omitempty"`
 }
 
+// thresholdSnoozeState is a snooze of a context's alarms; a zero Duration
+// snoozes until the alarm clears.
 type thresholdSnoozeState struct {
-	Duration time.Duration
-	SetAt    time.Time
-	Until    time.Time
+	Duration time.Duration `json:"duration"`
+	SetAt    time.Time     `json:"setAt"`
+	Until    time.Time     `json:"until,omitzero"`
+}
+
+// expired reports whether a timed snooze has run out at now.
+func (s *thresholdSnoozeState) expired(now time.Time) bool {
+	return s.Duration > 0 && !s.Until.IsZero() && !now.Before(s.Until)
 }
 
 var thresholdSnoozeDurationOrder = []int{
@@ -70,14 +79,6 @@ func (p *Plugin) consumeThresholdDirty(context string) bool {
 	return dirty
 }
 
-func (p *Plugin) clearThresholdRuntimeState(context string) {
-	p.mu.Lock()
-	defer p.mu.Unlock()
-	delete(p.thresholdStates, context)
-	delete(p.thresholdSnoozes, context)
-	delete(p.thresholdDirty, context)
-}
-
 func (p *Plugin) resetThresholdRuntimeState(context, thresholdID string) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -152,7 +153,7 @@ func (p *Plugin) currentThresholdSnoozeState(context string, now time.Time) (thr
 	if state == nil {
 		return thresholdSnoozeState{}, false, false
 	}
-	if state.Duration > 0 && !state.Until.IsZero() && !now.Before(state.Until) {
+	if state.expired(now) {
 		delete(p.thresholdSnoozes, context)
 		p.thresholdDirty[context] = true
 		return thresholdSnoozeState{}, false, true
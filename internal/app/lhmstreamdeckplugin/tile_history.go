@@ -0,0 +1,344 @@
+package lhmstreamdeckplugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/moeilijk/lhm-streamdeck/pkg/graph"
+)
+
+const (
+	// tileHistoryFile is the state file in the plugin directory (the
+	// working directory Stream Deck starts the plugin in).
+	tileHistoryFile    = "tile-history.json"
+	tileHistoryVersion = 1
+
+	// historySamples is how many samples are kept per series at least: the
+	// minimum history a graph holds, which covers the widest (dial) graph.
+	// Time-window graphs keep their whole window, up to graph.MaxHistory.
+	historySamples = 256
+
+	defaultHistoryMaxAge = 15 * time.Minute
+)
+
+// historySample is a graph sample as stored in the state file.
+type historySample struct {
+	T int64   `json:"t"` // unix milliseconds
+	V float64 `json:"v"`
+}
+
+// tileHistoryEntry is what a graph showed when last updated: its samples,
+// the EMA state behind them and the title and value text drawn over them.
+type tileHistoryEntry struct {
+	Key      string          `json:"key"`
+	Updated  time.Time       `json:"updated"`
+	Samples  []historySample `json:"samples,omitempty"`
+	Smoothed *float64        `json:"smoothed,omitempty"`
+	Title    string          `json:"title,omitempty"`
+	Text     string          `json:"text,omitempty"`
+}
+
+type tileHistoryState struct {
+	Version int                          `json:"version"`
+	Entries map[string]*tileHistoryEntry `json:"entries"`
+}
+
+// tileHistory keeps the last values of every graph so a tile that comes back
+// after a page switch or a plugin restart shows where it left off instead of
+// an empty graph. Entries are keyed by context ("|slot" suffixed for
+// composite slots and dial pages) and hold a hash of the settings the series
+// was drawn with; an entry whose hash no longer matches, or that was not
+// updated within maxAge, is discarded. The zero value records nothing.
+type tileHistory struct {
+	mu         sync.Mutex
+	path       string
+	maxAge     time.Duration // <= 0 disables the history
+	ageUnknown bool          // the age setting has not arrived: nothing expires yet
+	entries    map[string]*tileHistoryEntry
+	dirty      bool
+}
+
+// historyKey hashes the settings a series depends on.
+func historyKey(parts ...string) string {
+	h := fnv.New64a()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+func formatAlpha(alpha float64) string {
+	return strconv.FormatFloat(alpha, 'g', -1, 64)
+}
+
+// readingHistoryKey identifies the series of a reading tile or dial page.
+func readingHistoryKey(s *actionSettings) string {
+	parts := []string{"reading", s.SourceProfileID, s.SensorUID, strconv.Itoa(int(s.ReadingID)),
+		s.Divisor, s.DisplayUnit, s.GraphUnit, formatAlpha(s.SmoothingAlpha)}
+	// Only appended when set, so histories saved before it existed still match.
+	if s.ValueSource != "" {
+		parts = append(parts, s.ValueSource)
+	}
+	return historyKey(parts...)
+}
+
+// compositeHistoryKey identifies the series of one composite slot.
+func compositeHistoryKey(s *compositeActionSettings, slot int) string {
+	sl := &s.Slots[slot]
+	return historyKey("composite", s.SourceProfileID, sl.SensorUID, strconv.Itoa(int(sl.ReadingID)),
+		sl.Divisor, sl.DisplayUnit, sl.GraphUnit, formatAlpha(s.SmoothingAlpha))
+}
+
+// derivedHistoryKey identifies the series of a derived tile.
+func derivedHistoryKey(s *derivedActionSettings) string {
+	parts := []string{"derived", s.SourceProfileID, s.Formula, s.Divisor, s.DisplayUnit, s.GraphUnit, formatAlpha(s.SmoothingAlpha)}
+	for i := 0; i < s.SlotCount && i < len(s.Slots); i++ {
+		sl := &s.Slots[i]
+		parts = append(parts, sl.SensorUID, strconv.Itoa(int(sl.ReadingID)), sl.Divisor, sl.GraphUnit)
+	}
+	return historyKey(parts...)
+}
+
+// record stores an update of the series of context. sample is the value the
+// graph was updated with, nil when it was left alone; smoothed is the EMA
+// state, nil without smoothing; window is the graph's time window, whose
+// samples are kept so its buckets come back whole.
+func (h *tileHistory) record(context, key string, now time.Time, window time.Duration, sample, smoothed *float64, title, text string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.maxAge <= 0 {
+		return
+	}
+	if h.entries == nil {
+		h.entries = make(map[string]*tileHistoryEntry)
+	}
+	e := h.entries[context]
+	if e == nil || e.Key != key {
+		e = &tileHistoryEntry{Key: key}
+		h.entries[context] = e
+	}
+	if sample != nil {
+		e.Samples = append(e.Samples, historySample{T: now.UnixMilli(), V: *sample})
+		e.Samples = trimHistory(e.Samples, now, window)
+	}
+	e.Smoothed = nil
+	if smoothed != nil {
+		v := *smoothed
+		e.Smoothed = &v
+	}
+	e.Title = title
+	e.Text = text
+	e.Updated = now
+	h.dirty = true
+}
+
+// trimHistory drops the samples older than window from the front of a
+// series, keeping at least historySamples and at most graph.MaxHistory.
+func trimHistory(samples []historySample, now time.Time, window time.Duration) []historySample {
+	n := len(samples)
+	drop := max(n-graph.MaxHistory, 0)
+	cutoff := now.Add(-window).UnixMilli()
+	for drop < n-historySamples && samples[drop].T < cutoff {
+		drop++
+	}
+	return samples[drop:]
+}
+
+// restore returns a copy of the entry of context if it was recorded with key
+// and is recent enough.
+func (h *tileHistory) restore(context, key string, now time.Time) (tileHistoryEntry, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	e := h.entries[context]
+	if e == nil {
+		return tileHistoryEntry{}, false
+	}
+	if e.Key != key || h.expiredLocked(e, now) {
+		delete(h.entries, context)
+		h.dirty = true
+		return tileHistoryEntry{}, false
+	}
+	out := *e
+	out.Samples = append([]historySample(nil), e.Samples...)
+	return out, true
+}
+
+func (h *tileHistory) expiredLocked(e *tileHistoryEntry, now time.Time) bool {
+	if h.maxAge <= 0 {
+		return true
+	}
+	return !h.ageUnknown && now.Sub(e.Updated) > h.maxAge
+}
+
+func (h *tileHistory) pruneLocked(now time.Time) {
+	for ctx, e := range h.entries {
+		if h.expiredLocked(e, now) {
+			delete(h.entries, ctx)
+			h.dirty = true
+		}
+	}
+}
+
+// setMaxAge changes how long entries are kept and drops the entries older
+// than that. Disabling the history drops every entry and removes the state
+// file.
+func (h *tileHistory) setMaxAge(d time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.maxAge = d
+	h.ageUnknown = false
+	if d > 0 {
+		h.pruneLocked(time.Now())
+		return
+	}
+	h.entries = nil
+	h.dirty = false
+	if h.path != "" {
+		if err := os.Remove(h.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			log.Printf("tile history remove: %v\n", err)
+		}
+	}
+}
+
+// load reads the state file, keeping the entries that have not expired. While
+// the age is unknown every entry is kept; setMaxAge prunes them.
+func (h *tileHistory) load(now time.Time) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.path == "" || h.maxAge <= 0 {
+		return nil
+	}
+	data, err := os.ReadFile(h.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var st tileHistoryState
+	if err := json.Unmarshal(data, &st); err != nil {
+		return fmt.Errorf("%s: %w", h.path, err)
+	}
+	if st.Version != tileHistoryVersion {
+		return nil
+	}
+	h.entries = st.Entries
+	h.pruneLocked(now)
+	h.dirty = false
+	return nil
+}
+
+// save writes the entries that have not expired to the state file if
+// anything changed since the last save.
+func (h *tileHistory) save(now time.Time) error {
+	h.mu.Lock()
+	if h.path == "" || h.maxAge <= 0 {
+		h.mu.Unlock()
+		return nil
+	}
+	h.pruneLocked(now)
+	if !h.dirty {
+		h.mu.Unlock()
+		return nil
+	}
+	data, err := json.Marshal(tileHistoryState{Version: tileHistoryVersion, Entries: h.entries})
+	h.dirty = false
+	path := h.path
+	h.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
+
+// writeFileAtomic replaces path with data, so a crash mid-write leaves the
+// previous file intact.
+func writeFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// historyMaxAge returns how long graph history is kept: HistoryMaxAgeMin
+// minutes, the default when unset, and 0 when it is turned off.
+func historyMaxAge(gs *globalSettings) time.Duration {
+	switch {
+	case gs.HistoryMaxAgeMin < 0:
+		return 0
+	case gs.HistoryMaxAgeMin == 0:
+		return defaultHistoryMaxAge
+	}
+	return time.Duration(gs.HistoryMaxAgeMin) * time.Minute
+}
+
+// applyHistoryMaxAge sets the history age from the global settings.
+func (p *Plugin) applyHistoryMaxAge() {
+	p.mu.RLock()
+	d := historyMaxAge(&p.globalSettings)
+	p.mu.RUnlock()
+	p.history.setMaxAge(d)
+}
+
+// setHistoryMaxAge stores how many minutes of graph history are kept; a
+// negative value turns the history off.
+func (p *Plugin) setHistoryMaxAge(minutes int) error {
+	if minutes < -1 {
+		return fmt.Errorf("invalid history age %d", minutes)
+	}
+	p.mu.Lock()
+	p.globalSettings.HistoryMaxAgeMin = minutes
+	gs := p.globalSettings
+	p.mu.Unlock()
+
+	if err := p.sd.SetGlobalSettings(gs); err != nil {
+		log.Printf("setHistoryMaxAge SetGlobalSettings: %v\n", err)
+	}
+	p.applyHistoryMaxAge()
+	log.Printf("Graph history age set to %v\n", historyMaxAge(&gs))
+	return nil
+}
+
+func (p *Plugin) saveHistory() {
+	if err := p.history.save(time.Now()); err != nil {
+		log.Printf("tile history save: %v\n", err)
+	}
+}
+
+// restoreGraphHistory replays the saved samples of context into g, which must
+// not be shared with a renderer yet, and returns the saved entry.
+func (p *Plugin) restoreGraphHistory(context, key string, g *graph.Graph) (tileHistoryEntry, bool) {
+	e, ok := p.history.restore(context, key, time.Now())
+	if !ok || g == nil {
+		return e, ok
+	}
+	for _, s := range e.Samples {
+		g.UpdateAt(s.V, time.UnixMilli(s.T))
+	}
+	return e, true
+}
@@ -0,0 +1,184 @@
+package lhmstreamdeckplugin
+
+import (
+	"errors"
+	"image/color"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/moeilijk/lhm-streamdeck/pkg/graph"
+)
+
+func TestTileHistoryKeepsRecentSamples(t *testing.T) {
+	h := tileHistory{maxAge: time.Minute}
+	start := time.Unix(1700000000, 0)
+	ema := 41.5
+	for i := 0; i < historySamples+50; i++ {
+		v := float64(i)
+		h.record("ctx", "key", start.Add(time.Duration(i)*time.Millisecond), 0, &v, &ema, "CPU", "42 °C")
+	}
+	h.record("ctx", "key", start.Add(time.Second), 0, nil, nil, "CPU", "43 °C")
+
+	e, ok := h.restore("ctx", "key", start.Add(30*time.Second))
+	if !ok {
+		t.Fatal("recent entry was not restored")
+	}
+	if len(e.Samples) != historySamples {
+		t.Fatalf("len(Samples) = %d, want %d", len(e.Samples), historySamples)
+	}
+	if first, last := e.Samples[0].V, e.Samples[len(e.Samples)-1].V; first != 50 || last != historySamples+49 {
+		t.Fatalf("samples span %v..%v, want the latest %d", first, last, historySamples)
+	}
+	if e.Text != "43 °C" || e.Smoothed != nil {
+		t.Fatalf("entry = text %q smoothed %v, want the last update", e.Text, e.Smoothed)
+	}
+
+	if _, ok := h.restore("ctx", "other", start.Add(30*time.Second)); ok {
+		t.Fatal("entry restored for different settings")
+	}
+	if _, ok := h.restore("ctx", "key", start.Add(30*time.Second)); ok {
+		t.Fatal("entry with stale settings was kept")
+	}
+
+	h.record("ctx", "key", start, 0, nil, nil, "", "1")
+	if _, ok := h.restore("ctx", "key", start.Add(2*time.Minute)); ok {
+		t.Fatal("expired entry was restored")
+	}
+}
+
+func TestTileHistoryKeepsTheTimeWindow(t *testing.T) {
+	h := tileHistory{maxAge: time.Hour}
+	start := time.Unix(1700000000, 0)
+	window := 2 * time.Hour
+	n := 3 * 3600 // three hours at one sample a second
+	for i := 0; i < n; i++ {
+		v := float64(i)
+		h.record("ctx", "key", start.Add(time.Duration(i)*time.Second), window, &v, nil, "", "")
+	}
+	now := start.Add(time.Duration(n-1) * time.Second)
+	e, ok := h.restore("ctx", "key", now)
+	if !ok {
+		t.Fatal("entry was not restored")
+	}
+	if want := int(window/time.Second) + 1; len(e.Samples) != want {
+		t.Fatalf("len(Samples) = %d, want the %d of the window", len(e.Samples), want)
+	}
+
+	g := graph.NewGraph(72, 72, 0, 100, &color.RGBA{0, 81, 40, 255}, &color.RGBA{0, 0, 0, 255}, &color.RGBA{0, 158, 0, 255})
+	g.SetTimeWindow(window)
+	for _, s := range e.Samples {
+		g.UpdateAt(s.V, time.UnixMilli(s.T))
+	}
+	if got := len(g.Samples()); got != len(e.Samples) {
+		t.Fatalf("graph kept %d restored samples, want %d", got, len(e.Samples))
+	}
+	if first := g.Samples()[0].Time; now.Sub(first) != window {
+		t.Fatalf("restored graph spans %v, want %v", now.Sub(first), window)
+	}
+}
+
+func TestTileHistorySurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), tileHistoryFile)
+	now := time.Now()
+	old := tileHistory{path: path, maxAge: time.Hour}
+	v := 12.5
+	old.record("fresh", "key", now.Add(-time.Minute), 0, &v, nil, "", "12.5 W")
+	old.record("stale", "key", now.Add(-2*time.Hour), 0, &v, nil, "", "12.5 W")
+	if err := old.save(now.Add(-time.Minute)); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	h := tileHistory{path: path, maxAge: time.Hour}
+	if err := h.load(now); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	e, ok := h.restore("fresh", "key", now)
+	if !ok || len(e.Samples) != 1 || e.Samples[0].V != 12.5 || e.Text != "12.5 W" {
+		t.Fatalf("restored %+v, %v", e, ok)
+	}
+	if len(h.entries) != 1 {
+		t.Fatalf("%d entries after load, want the expired one dropped", len(h.entries))
+	}
+
+	h.setMaxAge(0)
+	if _, err := os.Stat(path); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("state file kept after turning history off: %v", err)
+	}
+	h.record("fresh", "key", now, 0, &v, nil, "", "")
+	if len(h.entries) != 0 {
+		t.Fatal("history recorded while off")
+	}
+}
+
+func TestTileHistoryPrunesOnceTheAgeIsKnown(t *testing.T) {
+	path := filepath.Join(t.TempDir(), tileHistoryFile)
+	now := time.Now()
+	old := tileHistory{path: path, maxAge: time.Hour}
+	v := 1.0
+	old.record("recent", "key", now.Add(-time.Minute), 0, &v, nil, "", "")
+	old.record("older", "key", now.Add(-30*time.Minute), 0, &v, nil, "", "")
+	old.record("oldest", "key", now.Add(-50*time.Minute), 0, &v, nil, "", "")
+	if err := old.save(now); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	// Loaded at startup with the default age before the settings arrive.
+	h := tileHistory{path: path, maxAge: defaultHistoryMaxAge, ageUnknown: true}
+	if err := h.load(now); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(h.entries) != 3 {
+		t.Fatalf("%d entries after load, want all 3 kept until the age is known", len(h.entries))
+	}
+
+	h.setMaxAge(45 * time.Minute)
+	if _, ok := h.entries["oldest"]; ok {
+		t.Fatal("entry older than the configured age was kept")
+	}
+	if _, ok := h.restore("older", "key", now); !ok {
+		t.Fatal("entry within the configured age was pruned")
+	}
+}
+
+func TestRestoreDialHistoryRefillsPages(t *testing.T) {
+	p := &Plugin{}
+	p.history.maxAge = time.Minute
+	settings := dialActionSettings{
+		SourceProfileID: "remote",
+		Pages: []actionSettings{
+			{SensorUID: "cpu", ReadingID: 1, IsValid: true, SmoothingAlpha: 0.5},
+			{SensorUID: "gpu", ReadingID: 2, IsValid: true},
+		},
+	}
+
+	// Recorded the way updateDialPage sees the page: with the dial's source.
+	page := settings.Pages[0]
+	page.SourceProfileID = "remote"
+	now := time.Now()
+	ema := 55.0
+	for _, v := range []float64{50, 60} {
+		p.history.record(dialPageContext("dial", 0), readingHistoryKey(&page), now, 0, &v, &ema, "CPU Package", "55 °C")
+	}
+
+	state := initDialState(&settings)
+	smoothed := p.restoreDialHistory("dial", &settings, state)
+
+	if n := len(state.graphs[0].Samples()); n != 2 {
+		t.Fatalf("page 0 has %d samples, want 2", n)
+	}
+	if got := state.graphs[0].LabelText(1); got != "55 °C" {
+		t.Fatalf("page 0 value = %q, want the saved text", got)
+	}
+	if got := state.graphs[0].LabelText(0); got != "CPU Package" {
+		t.Fatalf("page 0 title = %q, want the saved title", got)
+	}
+	if got, ok := smoothed[dialPageContext("dial", 0)]; !ok || got != 55 {
+		t.Fatalf("smoothed = %v, want page 0 at 55", smoothed)
+	}
+	if n := len(state.graphs[1].Samples()); n != 0 {
+		t.Fatalf("page 1 has %d samples, want none", n)
+	}
+}
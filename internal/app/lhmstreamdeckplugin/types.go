@@ -4,10 +4,56 @@ package lhmstreamdeckplugin
 type lhmSourceProfile struct {
 	ID   string `json:"id"`
 	Name string `json:"name"`
-	Host string `json:"host"`
+	Type string `json:"type,omitempty"` // "" = LHM/lhm-companion over HTTP, "hwmon" = local Linux sysfs, "group" = failover group, "replay" = recording
+	Host string `json:"host"` // name, IPv4 or IPv6 literal (brackets optional)
 	Port int    `json:"port"`
+
+	// Scheme is "http" (default) or "https"; Path defaults to /data.json, for
+	// companions served under a reverse-proxy prefix.
+	Scheme string `json:"scheme,omitempty"`
+	Path   string `json:"path,omitempty"`
+	// TLS options for https endpoints: a PEM bundle trusted on top of the
+	// system roots, or no verification at all.
+	CAFile             string `json:"caFile,omitempty"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty"`
+
+	// Credentials for LHM's remote web server or lhm-companion. Token is sent
+	// as a bearer token, otherwise Username/Password as HTTP Basic auth.
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Token    string `json:"token,omitempty"`
+
+	// Members lists the profile IDs of a failover group in priority order.
+	Members []string `json:"members,omitempty"`
+
+	// RecordDir receives a JSONL recording of every data.json fetched from an
+	// HTTP source. ReplayFile is the recording a replay profile serves, at
+	// ReplaySpeed times real time (0 = real time).
+	RecordDir   string  `json:"recordDir,omitempty"`
+	ReplayFile  string  `json:"replayFile,omitempty"`
+	ReplaySpeed float64 `json:"replaySpeed,omitempty"`
+
+	// StatWindows lists the rolling windows readings of an HTTP or replay
+	// source carry min/max/mean for, e.g. "1m,5m,15m"; "" = 1m, 5m and 15m.
+	StatWindows string `json:"statWindows,omitempty"`
 }
 
+// credentialMask replaces stored secrets in profiles sent to the property
+// inspector. Saving a profile with the mask keeps the stored secret.
+const credentialMask = "********"
+
+// sourceTypeHwmon selects the built-in /sys/class/hwmon reader instead of an
+// HTTP endpoint. Host and Port are ignored for such profiles.
+const sourceTypeHwmon = "hwmon"
+
+// sourceTypeGroup makes a profile a failover group over other profiles. Tiles
+// reference the group; the endpoint fields are ignored.
+const sourceTypeGroup = "group"
+
+// sourceTypeReplay serves a recording made with RecordDir instead of a live
+// endpoint, for reproducing tile behaviour offline.
+const sourceTypeReplay = "replay"
+
 // globalSettings represents plugin-wide settings (not per-action)
 type globalSettings struct {
 	PollInterval           int                `json:"pollInterval"`                     // milliseconds: 250..10000 (matches LHM Update Interval options)
@@ -15,6 +61,10 @@ type globalSettings struct {
 	DefaultSourceProfileID string             `json:"defaultSourceProfileId,omitempty"` // ID of the default source profile
 	FavoriteReadings       []favoriteReading  `json:"favoriteReadings,omitempty"`       // shared favorites for all tiles
 	GlobalThresholds       []Threshold        `json:"globalThresholds,omitempty"`       // shared threshold library
+	TemperatureUnit        string             `json:"temperatureUnit,omitempty"`        // "°F" or "K" for all temperature tiles; "" = °C
+	PNGCompression         map[string]string  `json:"pngCompression,omitempty"`         // image compression per transport: "none", "fast", "default" or "best"
+	HistoryMaxAgeMin       int                `json:"historyMaxAgeMin,omitempty"`       // minutes of graph history kept across restarts; 0 = 15, -1 = off
+	Rendering              string             `json:"rendering,omitempty"`              // "smooth" = anti-aliased, keys at 144x144; "" = hard-edged 72x72 keys
 
 	// Legacy fields — kept for migration only, omitempty so they are dropped after migration
 	LhmHost string `json:"lhmHost,omitempty"`
@@ -50,7 +100,8 @@ type Threshold struct {
 	ForegroundColor string  `json:"foregroundColor"`       // Graph foreground color
 	HighlightColor  string  `json:"highlightColor"`        // Graph highlight color
 	ValueTextColor  string  `json:"valueTextColor"`        // Value text color
-	ReadingType     string  `json:"readingType,omitempty"` // globals only: "Temp","Volt","Fan","Current","Power","Clock","Usage","Other" or "" = all
+	ReadingType     string  `json:"readingType,omitempty"` // globals only: a ReadingType name ("Temp", "Throughput", ...) or "" = all
+	Unit            string  `json:"unit,omitempty"`        // temperature unit Value and Hysteresis are in; "" = °C
 }
 
 type actionSettings struct {
@@ -64,23 +115,40 @@ type actionSettings struct {
 	ShowTitleInGraph         *bool   `json:"showTitleInGraph"`
 	Min                      int     `json:"min"`
 	Max                      int     `json:"max"`
+	AutoScale                string  `json:"autoScale,omitempty"`   // "window" or "start"; "" = Min/Max
+	GraphScale               string  `json:"graphScale,omitempty"`  // "log" or "symmetric"; "" = linear
+	LogFloor                 float64 `json:"logFloor,omitempty"`    // lowest value a log scale shows; 0 = 1
+	GraphWindow              string  `json:"graphWindow,omitempty"` // time span of the x-axis ("5m"); "" = one column per sample
 	Format                   string  `json:"format"`
 	Divisor                  string  `json:"divisor"`
-	GraphUnit                string  `json:"graphUnit"` // B, KB, MB, GB, TB - normalizes graph values to this unit
+	GraphUnit                string  `json:"graphUnit"`             // B, KB, MB, GB, TB - normalizes graph values to this unit
+	DisplayUnit              string  `json:"displayUnit,omitempty"` // unit ID, e.g. "°F", "GHz", "Mbit/s"; wins over GraphUnit
+	ValueSource              string  `json:"valueSource,omitempty"` // rolling statistic to show, e.g. "avg5m", "max1m"; "" = current value. Reading tiles only
 	IsValid                  bool    `json:"isValid"`
 	TitleColor               string  `json:"titleColor"`
 	ForegroundColor          string  `json:"foregroundColor"`
 	BackgroundColor          string  `json:"backgroundColor"`
 	HighlightColor           string  `json:"highlightColor"`
 	ValueTextColor           string  `json:"valueTextColor"`
+	GraphGradient            string  `json:"graphGradient,omitempty"`  // "40:#00ff00, 95:#ff0000"; "" = ForegroundColor
 	GraphMode                string  `json:"graphMode"`                // "both" (default), "graph", "text"
+	GraphStyle               string  `json:"graphStyle,omitempty"`     // "line", "step", "bars", "dots"; "" = area
 	GraphHeightPct           int     `json:"graphHeightPct"`           // 10–100; 0 = 100
 	GraphLineThickness       int     `json:"graphLineThickness"`       // 1–4; 0 = 1
 	TextStroke               bool    `json:"textStroke"`               // outline around labels
+	ShowThresholdLines       bool    `json:"showThresholdLines"`       // draw enabled thresholds over the graph
 	TextStrokeColor          string  `json:"textStrokeColor"`          // hex; empty = use background color
 	UpdateIntervalOverrideMs int     `json:"updateIntervalOverrideMs"` // 0 = follow global
 	SmoothingAlpha           float64 `json:"smoothingAlpha"`           // 0.1–1.0; 0 = treat as 1.0 (no smoothing)
 	InErrorState             bool    `json:"inErrorState"`
+	AuthFailed               bool    `json:"authFailed,omitempty"` // InErrorState was caused by rejected credentials
+
+	// Dial pages only: a second reading of the same sensor drawn downward
+	// from a centre line under the page's own, e.g. upload under download.
+	MirrorReadingID       int32  `json:"mirrorReadingId,string,omitempty"`
+	MirrorReadingLabel    string `json:"mirrorReadingLabel,omitempty"`
+	MirrorForegroundColor string `json:"mirrorForegroundColor,omitempty"`
+	MirrorHighlightColor  string `json:"mirrorHighlightColor,omitempty"`
 
 	// Dynamic threshold system
 	Thresholds          []Threshold `json:"thresholds"`
@@ -164,6 +232,7 @@ type evSendCatalogPayloadCatalog struct {
 	Readings       []*evSendReadingsPayloadReading `json:"readings"`
 	Favorites      []favoriteReading               `json:"favorites,omitempty"`
 	SourceProfiles []lhmSourceProfile              `json:"sourceProfiles,omitempty"`
+	StatWindows    []string                        `json:"statWindows,omitempty"` // windows the tile's source tracks, e.g. "5m"; none = no Value options
 }
 
 type evSendCatalogPayload struct {
@@ -185,15 +254,22 @@ type compositeSlotSettings struct {
 	HighlightColor     string  `json:"highlightColor"`
 	ValueTextColor     string  `json:"valueTextColor"`
 	TitleColor         string  `json:"titleColor"`
+	GraphGradient      string  `json:"graphGradient,omitempty"`
+	GraphStyle         string  `json:"graphStyle,omitempty"`
 	FillAlpha          int     `json:"fillAlpha"`
 	Min                int     `json:"min"`
 	Max                int     `json:"max"`
+	AutoScale          string  `json:"autoScale,omitempty"`
+	GraphScale         string  `json:"graphScale,omitempty"`
+	LogFloor           float64 `json:"logFloor,omitempty"`
 	Format             string  `json:"format"`
 	Divisor            string  `json:"divisor"`
 	GraphUnit          string  `json:"graphUnit"`
+	DisplayUnit        string  `json:"displayUnit,omitempty"`
 	GraphHeightPct     int     `json:"graphHeightPct"`
 	GraphLineThickness int     `json:"graphLineThickness"`
 	TextStroke         bool    `json:"textStroke"`
+	ShowThresholdLines bool    `json:"showThresholdLines"`
 	TextStrokeColor    string  `json:"textStrokeColor"`
 
 	Thresholds          []Threshold `json:"thresholds,omitempty"`
@@ -205,9 +281,11 @@ type compositeActionSettings struct {
 	SourceProfileID          string                   `json:"sourceProfileId,omitempty"`
 	SlotCount                int                      `json:"slotCount"`
 	Mode                     string                   `json:"mode"`
+	Layout                   string                   `json:"layout,omitempty"` // "mirrored" = slot 1 above slot 2, back to back; "" = overlaid
 	Slots                    [4]compositeSlotSettings `json:"slots"`
 	UpdateIntervalOverrideMs int                      `json:"updateIntervalOverrideMs"` // 0 = follow global
 	SmoothingAlpha           float64                  `json:"smoothingAlpha"`           // 0.1–1.0; 0 = 1.0 (no smoothing)
+	GraphWindow              string                   `json:"graphWindow,omitempty"`    // applies to every slot graph
 }
 
 type derivedSlotSettings struct {
@@ -232,17 +310,25 @@ type derivedActionSettings struct {
 	ShowTitleInGraph         *bool       `json:"showTitleInGraph"`
 	Min                      int         `json:"min"`
 	Max                      int         `json:"max"`
+	AutoScale                string      `json:"autoScale,omitempty"`
+	GraphScale               string      `json:"graphScale,omitempty"`
+	LogFloor                 float64     `json:"logFloor,omitempty"`
+	GraphWindow              string      `json:"graphWindow,omitempty"`
 	Format                   string      `json:"format"`
 	Divisor                  string      `json:"divisor"`
 	GraphUnit                string      `json:"graphUnit"`
+	DisplayUnit              string      `json:"displayUnit,omitempty"` // applied to each slot before the formula
 	TitleColor               string      `json:"titleColor"`
 	ForegroundColor          string      `json:"foregroundColor"`
 	BackgroundColor          string      `json:"backgroundColor"`
 	HighlightColor           string      `json:"highlightColor"`
 	ValueTextColor           string      `json:"valueTextColor"`
+	GraphGradient            string      `json:"graphGradient,omitempty"`
+	GraphStyle               string      `json:"graphStyle,omitempty"`
 	GraphHeightPct           int         `json:"graphHeightPct"`
 	GraphLineThickness       int         `json:"graphLineThickness"`
 	TextStroke               bool        `json:"textStroke"`
+	ShowThresholdLines       bool        `json:"showThresholdLines"`
 	TextStrokeColor          string      `json:"textStrokeColor"`
 	UpdateIntervalOverrideMs int         `json:"updateIntervalOverrideMs"` // 0 = follow global
 	SmoothingAlpha           float64     `json:"smoothingAlpha"`           // 0.1–1.0; 0 = 1.0 (no smoothing)
@@ -0,0 +1,200 @@
+package lhmstreamdeckplugin
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strings"
+
+	hwsensorsservice "github.com/moeilijk/lhm-streamdeck/pkg/service"
+
+	"github.com/moeilijk/lhm-streamdeck/pkg/units"
+)
+
+// tileUnit converts a reading's values into the unit a tile shows them in.
+type tileUnit struct {
+	from, to units.Unit
+	symbol   string
+}
+
+// resolveTileUnit picks the unit a tile shows a reading in. An explicit
+// displayUnit wins when the reading's unit converts to it. Otherwise the
+// older graphUnit setting (B, KB, MB, GB, TB) still rescales throughput
+// readings, shown as e.g. "MB/s", and any other reading keeps its own unit.
+func resolveTileUnit(readingUnit, displayUnit, graphUnit string) tileUnit {
+	from := units.Parse(readingUnit)
+	if to, ok := units.Lookup(displayUnit); ok && units.Convertible(from, to) {
+		return tileUnit{from: from, to: to, symbol: to.Symbol}
+	}
+	if graphUnit != "" && strings.Contains(readingUnit, "/s") {
+		to, _ := units.Lookup(strings.ToUpper(graphUnit) + "/s")
+		return tileUnit{from: from, to: to, symbol: graphUnit + "/s"}
+	}
+	return tileUnit{from: from, to: from, symbol: readingUnit}
+}
+
+// convert returns v in the tile's unit; values that cannot be converted are
+// passed through.
+func (u tileUnit) convert(v float64) float64 {
+	out, _ := units.Convert(v, u.from, u.to)
+	return out
+}
+
+// thresholdToGraph maps a threshold value into the unit the graph is plotted
+// in. Temperature thresholds are already compared in the tile's unit; other
+// thresholds are in the reading's own unit.
+func (u tileUnit) thresholdToGraph() func(float64) float64 {
+	if u.isTemperature() {
+		return nil
+	}
+	return u.convert
+}
+
+// graphUnitValue rescales a data size or rate to the byte prefix graphUnit
+// (B, KB, MB, GB, TB), keeping sizes sizes and rates rates. Other values are
+// returned unchanged.
+func graphUnitValue(v float64, sourceUnit, graphUnit string) float64 {
+	from := units.Parse(sourceUnit)
+	id := strings.ToUpper(graphUnit)
+	if from.Dim == units.DataRate {
+		id += "/s"
+	}
+	to, ok := units.Lookup(id)
+	if !ok {
+		return v
+	}
+	out, _ := units.Convert(v, from, to)
+	return out
+}
+
+// isTemperature reports whether the tile shows a temperature reading in a
+// temperature unit; only then are its thresholds converted.
+func (u tileUnit) isTemperature() bool {
+	return u.from.Dim == units.Temperature && u.to.Dim == units.Temperature
+}
+
+// effectiveDisplayUnit returns the display unit a tile uses for a reading.
+// A temperature reading follows the plugin-wide temperatureUnit unless the
+// tile picked a temperature unit of its own; other readings keep the tile's
+// displayUnit.
+func effectiveDisplayUnit(readingUnit, displayUnit, temperatureUnit string) string {
+	if units.Parse(readingUnit).Dim != units.Temperature {
+		return displayUnit
+	}
+	if u, ok := units.Lookup(displayUnit); ok && u.Dim == units.Temperature {
+		return displayUnit
+	}
+	return temperatureUnit
+}
+
+// lookupTemperatureUnit resolves a temperature unit ID; "" is °C. Anything
+// else falls back to °C and reports false.
+func lookupTemperatureUnit(id string) (units.Unit, bool) {
+	celsius, _ := units.Lookup("°C")
+	if id == "" {
+		return celsius, true
+	}
+	if u, ok := units.Lookup(id); ok && u.Dim == units.Temperature {
+		return u, true
+	}
+	return celsius, false
+}
+
+// temperatureUnit returns the plugin-wide temperature unit, "" for °C.
+func (p *Plugin) temperatureUnit() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.globalSettings.TemperatureUnit
+}
+
+// thresholdUnit returns the unit to stamp on a threshold created on a tile
+// with displayUnit: the temperature unit the tile shows, "" for °C.
+func thresholdUnit(displayUnit, temperatureUnit string) string {
+	unit := effectiveDisplayUnit("°C", displayUnit, temperatureUnit)
+	if unit == "°C" {
+		return ""
+	}
+	return unit
+}
+
+// convertThreshold moves a threshold's Value and Hysteresis from the unit it
+// was entered in into to and stamps it, reporting whether anything changed.
+// Thresholds on a temperature difference (delta) are converted as intervals.
+func convertThreshold(t *Threshold, to units.Unit, delta bool) bool {
+	from, _ := lookupTemperatureUnit(t.Unit)
+	if from.ID == to.ID {
+		return false
+	}
+	zero, _ := units.Convert(0, from, to)
+	value, _ := units.Convert(t.Value, from, to)
+	if delta {
+		value -= zero
+	}
+	hysteresis, _ := units.Convert(t.Hysteresis, from, to)
+	t.Value = roundThresholdValue(value)
+	t.Hysteresis = roundThresholdValue(hysteresis - zero)
+	t.Unit = to.ID
+	if to.ID == "°C" {
+		t.Unit = ""
+	}
+	return true
+}
+
+// roundThresholdValue drops the float noise a unit round trip leaves behind.
+func roundThresholdValue(v float64) float64 {
+	return math.Round(v*1e6) / 1e6
+}
+
+// convertThresholds returns ts with every threshold converted into the
+// temperature unit to, and whether any of them changed. ts itself is never
+// modified; callers store the copy to migrate saved settings.
+func convertThresholds(ts []Threshold, to units.Unit, delta bool) ([]Threshold, bool) {
+	var out []Threshold
+	for i := range ts {
+		t := ts[i]
+		if !convertThreshold(&t, to, delta) {
+			continue
+		}
+		if out == nil {
+			out = make([]Threshold, len(ts))
+			copy(out, ts)
+		}
+		out[i] = t
+	}
+	if out == nil {
+		return ts, false
+	}
+	return out, true
+}
+
+// setTemperatureUnit changes the plugin-wide temperature unit and moves the
+// global thresholds scoped to temperatures into it. Tile thresholds follow on
+// their next render.
+func (p *Plugin) setTemperatureUnit(unit string) error {
+	to, ok := lookupTemperatureUnit(unit)
+	if !ok {
+		return fmt.Errorf("unknown temperature unit %q", unit)
+	}
+	if to.ID == "°C" {
+		unit = ""
+	}
+
+	tempType := hwsensorsservice.ReadingTypeTemp.String()
+	p.mu.Lock()
+	p.globalSettings.TemperatureUnit = unit
+	for i := range p.globalSettings.GlobalThresholds {
+		if t := &p.globalSettings.GlobalThresholds[i]; t.ReadingType == tempType {
+			convertThreshold(t, to, false)
+		}
+	}
+	gs := p.globalSettings
+	p.mu.Unlock()
+
+	if err := p.sd.SetGlobalSettings(gs); err != nil {
+		log.Printf("setTemperatureUnit SetGlobalSettings: %v\n", err)
+	}
+	p.markGlobalThresholdDirty("")
+	p.broadcastGlobalThresholds()
+	log.Printf("Temperature unit changed to %s\n", to.Symbol)
+	return nil
+}
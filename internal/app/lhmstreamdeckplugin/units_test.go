@@ -0,0 +1,141 @@
+package lhmstreamdeckplugin
+
+import (
+	"math"
+	"testing"
+
+	"github.com/moeilijk/lhm-streamdeck/pkg/units"
+)
+
+// GraphUnit settings saved before display units existed must convert exactly
+// as they used to: binary prefixes, throughput readings only on single tiles.
+func TestResolveTileUnitKeepsGraphUnitBehavior(t *testing.T) {
+	for _, tc := range []struct {
+		readingUnit, displayUnit, graphUnit string
+		v, want                             float64
+		symbol                              string
+	}{
+		{"KB/s", "", "MB", 2048, 2, "MB/s"},
+		{"MB/s", "", "KB", 1.5, 1536, "KB/s"},
+		{"GB/s", "", "B", 1, 1 << 30, "B/s"},
+		{"B/s", "", "KB", 512, 0.5, "KB/s"},
+		{"GB", "", "MB", 3, 3, "GB"}, // not a rate: GraphUnit ignored
+		{"°C", "", "", 45, 45, "°C"},
+		{"°C", "°F", "", 45, 113, "°F"},
+		{"KB/s", "Mbit/s", "MB", 1000, 8.192, "Mbit/s"}, // DisplayUnit wins
+		{"RPM", "°F", "", 1200, 1200, "RPM"},            // incompatible: ignored
+	} {
+		u := resolveTileUnit(tc.readingUnit, tc.displayUnit, tc.graphUnit)
+		if got := u.convert(tc.v); math.Abs(got-tc.want) > 1e-9 || u.symbol != tc.symbol {
+			t.Errorf("%v %s shown as %q/%q = %v %s, want %v %s",
+				tc.v, tc.readingUnit, tc.displayUnit, tc.graphUnit, got, u.symbol, tc.want, tc.symbol)
+		}
+	}
+}
+
+func TestGraphUnitValueKeepsSizesAndRates(t *testing.T) {
+	if got := graphUnitValue(1, "GB", "MB"); got != 1024 {
+		t.Fatalf("1 GB in MB = %v", got)
+	}
+	if got := graphUnitValue(1, "GB/s", "MB"); got != 1024 {
+		t.Fatalf("1 GB/s in MB/s = %v", got)
+	}
+	if got := graphUnitValue(55, "°C", "MB"); got != 55 {
+		t.Fatalf("non-data value changed to %v", got)
+	}
+}
+
+func TestEffectiveDisplayUnitFollowsTemperaturePreference(t *testing.T) {
+	for _, tc := range []struct {
+		readingUnit, displayUnit, pref, want string
+	}{
+		{"°C", "", "°F", "°F"},
+		{"°C", "K", "°F", "K"}, // tile override wins
+		{"°C", "", "", ""},
+		{"°C", "MB", "K", "K"}, // not a temperature unit: ignored
+		{"W", "kW", "°F", "kW"},
+		{"MB/s", "", "°F", ""},
+	} {
+		if got := effectiveDisplayUnit(tc.readingUnit, tc.displayUnit, tc.pref); got != tc.want {
+			t.Errorf("effectiveDisplayUnit(%q, %q, %q) = %q, want %q",
+				tc.readingUnit, tc.displayUnit, tc.pref, got, tc.want)
+		}
+	}
+}
+
+func TestConvertThresholdsMigratesCelsius(t *testing.T) {
+	fahrenheit, _ := units.Lookup("°F")
+	saved := []Threshold{
+		{ID: "a", Value: 80, Hysteresis: 2},
+		{ID: "b", Value: 176, Hysteresis: 3.6, Unit: "°F"},
+	}
+
+	got, changed := convertThresholds(saved, fahrenheit, false)
+	if !changed {
+		t.Fatal("°C threshold not migrated")
+	}
+	if got[0].Value != 176 || got[0].Hysteresis != 3.6 || got[0].Unit != "°F" {
+		t.Fatalf("migrated = %+v, want 176 °F with hysteresis 3.6", got[0])
+	}
+	if got[1] != saved[1] {
+		t.Fatalf("already stamped threshold changed: %+v", got[1])
+	}
+	if saved[0].Value != 80 || saved[0].Unit != "" {
+		t.Fatalf("input modified: %+v", saved[0])
+	}
+
+	if _, changed := convertThresholds(got, fahrenheit, false); changed {
+		t.Fatal("second migration changed thresholds")
+	}
+
+	celsius, _ := units.Lookup("°C")
+	back, _ := convertThresholds(got, celsius, false)
+	if back[0].Value != 80 || back[0].Unit != "" {
+		t.Fatalf("back to °C = %+v", back[0])
+	}
+}
+
+func TestConvertThresholdsDeltaIsAnInterval(t *testing.T) {
+	fahrenheit, _ := units.Lookup("°F")
+	kelvin, _ := units.Lookup("K")
+	got, _ := convertThresholds([]Threshold{{Value: 10}}, fahrenheit, true)
+	if got[0].Value != 18 {
+		t.Fatalf("10 °C difference in °F = %v, want 18", got[0].Value)
+	}
+	got, _ = convertThresholds([]Threshold{{Value: 10}}, kelvin, true)
+	if got[0].Value != 10 {
+		t.Fatalf("10 °C difference in K = %v, want 10", got[0].Value)
+	}
+}
+
+func TestThresholdUnitStampsNewThresholds(t *testing.T) {
+	if got := thresholdUnit("", "°F"); got != "°F" {
+		t.Fatalf("preference: %q", got)
+	}
+	if got := thresholdUnit("K", "°F"); got != "K" {
+		t.Fatalf("tile override: %q", got)
+	}
+	if got := thresholdUnit("", ""); got != "" {
+		t.Fatalf("default: %q", got)
+	}
+}
+
+func TestDivisorAppliesBeforeOffsetConversion(t *testing.T) {
+	p := &Plugin{
+		divisorCache:   make(map[string]divisorCacheEntry),
+		smoothedValues: make(map[string]float64),
+		globalSettings: globalSettings{TemperatureUnit: "°F"},
+	}
+	page := &actionSettings{Divisor: "10"}
+	dv, err := p.dialReadingValue("ctx", page, stubReading{unit: "°C", value: 500})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 500 / 10 = 50 °C = 122 °F; converting first would plot 93.2.
+	if math.Abs(dv.graph-122) > 1e-9 || dv.display != dv.graph {
+		t.Fatalf("graph %v, display %v; want both 122", dv.graph, dv.display)
+	}
+	if got := dv.unit.convert(dv.raw); got != dv.graph {
+		t.Fatalf("thresholds compare %v, graph plots %v", got, dv.graph)
+	}
+}
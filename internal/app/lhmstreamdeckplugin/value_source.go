@@ -0,0 +1,131 @@
+package lhmstreamdeckplugin
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	lhmplugin "github.com/moeilijk/lhm-streamdeck/internal/lhm/plugin"
+	hwsensorsservice "github.com/moeilijk/lhm-streamdeck/pkg/service"
+)
+
+// parseValueSource splits a valueSource setting such as "avg5m" into the
+// statistic ("avg", "min" or "max") and its window. ok is false for "" (the
+// current value) and anything unparseable.
+func parseValueSource(source string) (stat string, window time.Duration, ok bool) {
+	if len(source) < 4 {
+		return "", 0, false
+	}
+	stat = strings.ToLower(source[:3])
+	if stat != "avg" && stat != "min" && stat != "max" {
+		return "", 0, false
+	}
+	window, err := time.ParseDuration(source[3:])
+	if err != nil || window <= 0 {
+		return "", 0, false
+	}
+	return stat, window, true
+}
+
+// readingValue returns the value a tile shows for r: the current value, or
+// for a valueSource such as "avg5m" the source's rolling mean, minimum or
+// maximum over that window. A window without samples yet falls back to the
+// current value. tracked is false when the source does not track the window
+// at all (hwmon, older bridges, a window missing from the profile's stat
+// windows); the tile then says so instead of showing the current value.
+func readingValue(r hwsensorsservice.Reading, source string) (v float64, tracked bool) {
+	stat, window, ok := parseValueSource(source)
+	if !ok {
+		return r.Value(), true
+	}
+	st, ok := hwsensorsservice.ReadingStats(r, window)
+	if !ok {
+		return r.Value(), false
+	}
+	if st.Count == 0 {
+		return r.Value(), true
+	}
+	switch stat {
+	case "min":
+		return st.Min, true
+	case "max":
+		return st.Max, true
+	}
+	return st.Mean, true
+}
+
+// untrackedWindowText is the tile text for a valueSource whose window the
+// source does not track, e.g. "5m untracked".
+func untrackedWindowText(source string) string {
+	_, window, _ := parseValueSource(source)
+	return statWindowToken(window) + " untracked"
+}
+
+// statWindowToken formats a stat window the way valueSource settings spell
+// it: "30s", "5m", "1h".
+func statWindowToken(d time.Duration) string {
+	switch {
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d%time.Minute == 0:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	case d%time.Second == 0:
+		return fmt.Sprintf("%ds", d/time.Second)
+	}
+	return d.String()
+}
+
+// profileTrackedWindows returns the stat windows readings of prof carry, as
+// the LHM service resolves them: HTTP and replay sources track their
+// configured windows or the defaults, hwmon sources none. A group offers the
+// windows every stat-tracking member shares, so a choice keeps working after
+// a failover between them. byID looks up group members.
+func profileTrackedWindows(prof lhmSourceProfile, byID func(string) (lhmSourceProfile, bool)) []time.Duration {
+	switch prof.Type {
+	case "", sourceTypeReplay:
+		windows, err := lhmplugin.ParseStatWindows(prof.StatWindows)
+		if err != nil || len(windows) == 0 {
+			return lhmplugin.DefaultStatWindows
+		}
+		return windows
+	case sourceTypeGroup:
+		var shared []time.Duration
+		seen := false
+		for _, id := range prof.Members {
+			m, ok := byID(id)
+			if !ok || m.Type == sourceTypeGroup {
+				continue
+			}
+			windows := profileTrackedWindows(m, byID)
+			if len(windows) == 0 {
+				continue
+			}
+			if !seen {
+				shared, seen = slices.Clone(windows), true
+				continue
+			}
+			shared = slices.DeleteFunc(shared, func(d time.Duration) bool {
+				return !slices.Contains(windows, d)
+			})
+		}
+		return shared
+	}
+	return nil
+}
+
+// statWindowTokens lists the stat windows a tile on profileID can show, for
+// the property inspector's Value options. Unknown profiles are the legacy
+// single HTTP source with the default windows.
+func (p *Plugin) statWindowTokens(profileID string) []string {
+	prof, ok := p.sourceProfileByID(profileID)
+	if !ok {
+		prof = lhmSourceProfile{}
+	}
+	windows := profileTrackedWindows(prof, p.sourceProfileByID)
+	tokens := make([]string, len(windows))
+	for i, d := range windows {
+		tokens[i] = statWindowToken(d)
+	}
+	return tokens
+}
@@ -0,0 +1,90 @@
+package lhmstreamdeckplugin
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	hwsensorsservice "github.com/moeilijk/lhm-streamdeck/pkg/service"
+)
+
+// statsStubReading is a reading with rolling statistics.
+type statsStubReading struct {
+	stubReading
+	stats []hwsensorsservice.WindowStats
+}
+
+func (r statsStubReading) Stats() []hwsensorsservice.WindowStats { return r.stats }
+
+func TestReadingValueResolvesValueSource(t *testing.T) {
+	r := statsStubReading{
+		stubReading: stubReading{id: 1, label: "CPU Package", value: 95},
+		stats: []hwsensorsservice.WindowStats{
+			{Window: time.Minute, Min: 40, Max: 95, Mean: 60, Count: 60},
+			{Window: 5 * time.Minute, Min: 30, Max: 110, Mean: 55, Count: 300},
+			{Window: 15 * time.Minute}, // no samples yet
+		},
+	}
+	for _, tc := range []struct {
+		source  string
+		want    float64
+		tracked bool
+	}{
+		{"", 95, true},
+		{"avg1m", 60, true},
+		{"avg5m", 55, true},
+		{"min5m", 30, true},
+		{"max5m", 110, true},
+		{"avg15m", 95, true}, // empty window
+		{"avg30m", 95, false},
+		{"median5m", 95, true},
+		{"avgsoon", 95, true},
+	} {
+		got, tracked := readingValue(r, tc.source)
+		if got != tc.want || tracked != tc.tracked {
+			t.Errorf("readingValue(%q) = %v, %v, want %v, %v", tc.source, got, tracked, tc.want, tc.tracked)
+		}
+	}
+	if _, tracked := readingValue(stubReading{value: 7}, "avg5m"); tracked {
+		t.Error("reading without stats reports the window as tracked")
+	}
+	if got := untrackedWindowText("max90s"); got != "90s untracked" {
+		t.Errorf("untrackedWindowText = %q", got)
+	}
+}
+
+func TestProfileTrackedWindows(t *testing.T) {
+	profiles := map[string]lhmSourceProfile{
+		"lhm":    {ID: "lhm", StatWindows: "30s,2m,1h"},
+		"backup": {ID: "backup", StatWindows: "2m,5m,1h"},
+		"hwmon":  {ID: "hwmon", Type: sourceTypeHwmon},
+	}
+	byID := func(id string) (lhmSourceProfile, bool) {
+		prof, ok := profiles[id]
+		return prof, ok
+	}
+	tokens := func(windows []time.Duration) string {
+		out := make([]string, len(windows))
+		for i, d := range windows {
+			out[i] = statWindowToken(d)
+		}
+		return strings.Join(out, ",")
+	}
+	for _, tc := range []struct {
+		name string
+		prof lhmSourceProfile
+		want string
+	}{
+		{"configured", profiles["lhm"], "30s,2m,1h"},
+		{"default", lhmSourceProfile{}, "1m,5m,15m"},
+		{"invalid falls back", lhmSourceProfile{StatWindows: "soon"}, "1m,5m,15m"},
+		{"replay", lhmSourceProfile{Type: sourceTypeReplay, StatWindows: "10s"}, "10s"},
+		{"hwmon", profiles["hwmon"], ""},
+		{"group shares", lhmSourceProfile{Type: sourceTypeGroup, Members: []string{"lhm", "hwmon", "backup"}}, "2m,1h"},
+		{"hwmon group", lhmSourceProfile{Type: sourceTypeGroup, Members: []string{"hwmon"}}, ""},
+	} {
+		if got := tokens(profileTrackedWindows(tc.prof, byID)); got != tc.want {
+			t.Errorf("%s: windows = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,440 @@
+// Package hwmon reads hardware sensors straight from the Linux hwmon sysfs
+// interface and exposes them through the same HardwareService model that the
+// Libre Hardware Monitor bridge uses.
+package hwmon
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	hwsensorsservice "github.com/moeilijk/lhm-streamdeck/pkg/service"
+)
+
+// DefaultRoot is the sysfs class directory holding one hwmonN entry per chip.
+const DefaultRoot = "/sys/class/hwmon"
+
+// attrPattern matches the channel attributes we expose, e.g. temp1_input.
+var attrPattern = regexp.MustCompile(`^(temp|fan|in|power|curr)(\d+)_(input|average)$`)
+
+// channelKind describes how one hwmon attribute family maps onto a reading.
+type channelKind struct {
+	prefix string
+	typ    string
+	unit   string
+	label  string
+	scale  float64 // sysfs integer units per displayed unit
+	rt     hwsensorsservice.ReadingType
+}
+
+// kinds is ordered the way readings are listed for a chip.
+var kinds = []channelKind{
+	{prefix: "temp", typ: "Temperature", unit: "°C", label: "Temp", scale: 1000, rt: hwsensorsservice.ReadingTypeTemp},
+	{prefix: "fan", typ: "Fan", unit: "RPM", label: "Fan", scale: 1, rt: hwsensorsservice.ReadingTypeFan},
+	{prefix: "in", typ: "Voltage", unit: "V", label: "Voltage", scale: 1000, rt: hwsensorsservice.ReadingTypeVolt},
+	{prefix: "power", typ: "Power", unit: "W", label: "Power", scale: 1000000, rt: hwsensorsservice.ReadingTypePower},
+	{prefix: "curr", typ: "Current", unit: "A", label: "Current", scale: 1000, rt: hwsensorsservice.ReadingTypeCurrent},
+}
+
+func kindIndex(prefix string) int {
+	for i, k := range kinds {
+		if k.prefix == prefix {
+			return i
+		}
+	}
+	return len(kinds)
+}
+
+type reading struct {
+	id      int32
+	channel string // sysfs channel, e.g. temp1
+	label   string
+	unit    string
+	typ     string
+	typeI   hwsensorsservice.ReadingType
+	value   float64
+	min     float64
+	max     float64
+	average float64
+}
+
+func (r *reading) ID() int32                { return r.id }
+func (r *reading) TypeI() int32             { return int32(r.typeI) }
+func (r *reading) Type() string             { return r.typ }
+func (r *reading) Label() string            { return r.label }
+func (r *reading) Unit() string             { return r.unit }
+func (r *reading) Value() float64           { return r.value }
+func (r *reading) ValueNormalized() float64 { return r.value }
+func (r *reading) ValueMin() float64        { return r.min }
+func (r *reading) ValueMax() float64        { return r.max }
+func (r *reading) ValueAvg() float64        { return r.average }
+
+type sensor struct {
+	id   string
+	name string
+}
+
+func (s *sensor) ID() string   { return s.id }
+func (s *sensor) Name() string { return s.name }
+
+// extremes tracks the session min/max of one reading, like LHM does.
+type extremes struct {
+	min float64
+	max float64
+}
+
+// Service scans a hwmon sysfs tree and caches the latest snapshot.
+type Service struct {
+	root string
+
+	mu          sync.RWMutex
+	fetchMu     sync.Mutex
+	pollTime    uint64
+	sensors     map[string]*sensor
+	sensorOrder []string
+	readings    map[string][]*reading
+	seen        map[string]extremes
+	ready       bool
+	snap        *hwsensorsservice.Snapshot
+}
+
+// NewService creates a Service reading from root, which is DefaultRoot in
+// production and a fake directory tree in tests.
+func NewService(root string) *Service {
+	if root == "" {
+		root = DefaultRoot
+	}
+	return &Service{
+		root: root,
+		seen: make(map[string]extremes),
+	}
+}
+
+// Recv rescans the sysfs tree and replaces the cached snapshot.
+func (s *Service) Recv() error {
+	chips, err := scanChips(s.root)
+	if err != nil {
+		return err
+	}
+	if len(chips) == 0 {
+		return fmt.Errorf("no hwmon sensors found in %s", s.root)
+	}
+
+	sensors := make(map[string]*sensor, len(chips))
+	order := make([]string, 0, len(chips))
+	readings := make(map[string][]*reading, len(chips))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, c := range chips {
+		sensors[c.id] = &sensor{id: c.id, name: c.name}
+		order = append(order, c.id)
+		for _, r := range c.readings {
+			key := c.id + "|" + strconv.Itoa(int(r.id))
+			ext, ok := s.seen[key]
+			if !ok {
+				ext = extremes{min: r.value, max: r.value}
+			}
+			if r.value < ext.min {
+				ext.min = r.value
+			}
+			if r.value > ext.max {
+				ext.max = r.value
+			}
+			s.seen[key] = ext
+			r.min = ext.min
+			r.max = ext.max
+		}
+		readings[c.id] = c.readings
+	}
+	s.pollTime = uint64(time.Now().UnixNano())
+	s.sensors = sensors
+	s.sensorOrder = order
+	s.readings = readings
+	s.ready = true
+	s.snap = buildSnapshot(s.pollTime, order, sensors, readings)
+	return nil
+}
+
+// buildSnapshot freezes one scan into the Snapshot handed out to callers.
+func buildSnapshot(pollTime uint64, order []string, sensors map[string]*sensor, readings map[string][]*reading) *hwsensorsservice.Snapshot {
+	snap := &hwsensorsservice.Snapshot{
+		PollTime: pollTime,
+		Sensors:  make([]hwsensorsservice.Sensor, 0, len(order)),
+		Readings: make(map[string][]hwsensorsservice.Reading, len(order)),
+	}
+	for _, id := range order {
+		snap.Sensors = append(snap.Sensors, sensors[id])
+		rs := make([]hwsensorsservice.Reading, 0, len(readings[id]))
+		for _, r := range readings[id] {
+			rs = append(rs, r)
+		}
+		snap.Readings[id] = rs
+	}
+	return snap
+}
+
+// refresh serializes scans so concurrent callers do not all walk sysfs.
+func (s *Service) refresh() error {
+	s.fetchMu.Lock()
+	defer s.fetchMu.Unlock()
+	return s.Recv()
+}
+
+// ensureReady loads an initial snapshot when no cache exists yet.
+func (s *Service) ensureReady() error {
+	s.mu.RLock()
+	ready := s.ready
+	s.mu.RUnlock()
+	if ready {
+		return nil
+	}
+	return s.refresh()
+}
+
+// PollTime rescans sysfs and returns the time of the new snapshot.
+func (s *Service) PollTime() (uint64, error) {
+	if err := s.refresh(); err != nil {
+		return 0, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.pollTime, nil
+}
+
+// Sensors returns one sensor per hwmon chip.
+func (s *Service) Sensors() ([]hwsensorsservice.Sensor, error) {
+	if err := s.ensureReady(); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]hwsensorsservice.Sensor, 0, len(s.sensorOrder))
+	for _, id := range s.sensorOrder {
+		out = append(out, s.sensors[id])
+	}
+	return out, nil
+}
+
+// ReadingsForSensorID returns the readings of a single hwmon chip.
+func (s *Service) ReadingsForSensorID(id string) ([]hwsensorsservice.Reading, error) {
+	if err := s.ensureReady(); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rs, ok := s.readings[id]
+	if !ok {
+		return nil, fmt.Errorf("sensor %s not found", id)
+	}
+	out := make([]hwsensorsservice.Reading, 0, len(rs))
+	for _, r := range rs {
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+// Snapshot returns every chip with its readings, rescanning first when the
+// cached scan is older than pollTime.
+func (s *Service) Snapshot(pollTime uint64) (*hwsensorsservice.Snapshot, error) {
+	if err := s.ensureReady(); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	snap := s.snap
+	s.mu.RUnlock()
+	if snap.PollTime >= pollTime {
+		return snap, nil
+	}
+	if err := s.refresh(); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.snap, nil
+}
+
+type chip struct {
+	id       string
+	name     string
+	readings []*reading
+}
+
+// scanChips reads every hwmonN entry below root in numeric order.
+func scanChips(root string) ([]*chip, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("read hwmon root: %w", err)
+	}
+	dirs := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), "hwmon") {
+			dirs = append(dirs, e.Name())
+		}
+	}
+	sort.Slice(dirs, func(i, j int) bool {
+		ni, _ := strconv.Atoi(strings.TrimPrefix(dirs[i], "hwmon"))
+		nj, _ := strconv.Atoi(strings.TrimPrefix(dirs[j], "hwmon"))
+		return ni < nj
+	})
+
+	chips := make([]*chip, 0, len(dirs))
+	usedIDs := make(map[string]bool)
+	usedNames := make(map[string]bool)
+	for _, d := range dirs {
+		dir := filepath.Join(root, d)
+		if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+			continue
+		}
+		c := scanChip(dir, d)
+		if c == nil {
+			continue
+		}
+		if usedIDs[c.id] {
+			c.id += "/" + d
+		}
+		if usedNames[c.name] {
+			c.name += " (" + deviceKey(dir, d) + ")"
+		}
+		usedIDs[c.id] = true
+		usedNames[c.name] = true
+		for _, r := range c.readings {
+			r.id = makeReadingID(c.id, r.channel)
+		}
+		chips = append(chips, c)
+	}
+	return chips, nil
+}
+
+// scanChip collects the channels of one chip. Older drivers keep their
+// attributes under device/ instead of the hwmon directory itself.
+func scanChip(dir, base string) *chip {
+	name := readString(filepath.Join(dir, "name"))
+	if name == "" {
+		name = readString(filepath.Join(dir, "device", "name"))
+	}
+	if name == "" {
+		name = base
+	}
+
+	attrDir := dir
+	rs := scanChannels(attrDir)
+	if len(rs) == 0 {
+		attrDir = filepath.Join(dir, "device")
+		rs = scanChannels(attrDir)
+	}
+	if len(rs) == 0 {
+		return nil
+	}
+	return &chip{
+		id:       "/hwmon/" + name + "/" + deviceKey(dir, base),
+		name:     name,
+		readings: rs,
+	}
+}
+
+// deviceKey identifies the physical device behind a chip. hwmonN numbers are
+// assigned in probe order and can change between boots, the device path
+// (e.g. a PCI address) does not.
+func deviceKey(dir, base string) string {
+	if target, err := filepath.EvalSymlinks(filepath.Join(dir, "device")); err == nil {
+		return filepath.Base(target)
+	}
+	return base
+}
+
+type channelRef struct {
+	kind  int
+	index int
+	attr  string
+}
+
+func scanChannels(dir string) []*reading {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	chans := make(map[string]channelRef)
+	for _, e := range entries {
+		m := attrPattern.FindStringSubmatch(e.Name())
+		if m == nil {
+			continue
+		}
+		ch := m[1] + m[2]
+		// power*_input wins over power*_average when a driver offers both
+		if prev, ok := chans[ch]; ok && strings.HasSuffix(prev.attr, "_input") {
+			continue
+		}
+		idx, _ := strconv.Atoi(m[2])
+		chans[ch] = channelRef{kind: kindIndex(m[1]), index: idx, attr: e.Name()}
+	}
+
+	refs := make([]channelRef, 0, len(chans))
+	for _, ref := range chans {
+		refs = append(refs, ref)
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].kind != refs[j].kind {
+			return refs[i].kind < refs[j].kind
+		}
+		return refs[i].index < refs[j].index
+	})
+
+	out := make([]*reading, 0, len(refs))
+	for _, ref := range refs {
+		k := kinds[ref.kind]
+		raw, err := readInt(filepath.Join(dir, ref.attr))
+		if err != nil {
+			// Drivers return EIO/ENODATA for channels that are wired but idle.
+			continue
+		}
+		ch := k.prefix + strconv.Itoa(ref.index)
+		label := readString(filepath.Join(dir, ch+"_label"))
+		if label == "" {
+			label = k.label + " " + strconv.Itoa(ref.index)
+		}
+		val := float64(raw) / k.scale
+		out = append(out, &reading{
+			channel: ch,
+			label:   label,
+			unit:    k.unit,
+			typ:     k.typ,
+			typeI:   k.rt,
+			value:   val,
+			average: val,
+		})
+	}
+	return out
+}
+
+func readString(path string) string {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(b))
+}
+
+func readInt(path string) (int64, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(b)), 10, 64)
+}
+
+// makeReadingID hashes the chip id and channel into a stable reading id, the
+// same scheme the LHM service uses for its SensorId paths.
+func makeReadingID(sensorID, channel string) int32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(sensorID))
+	_, _ = h.Write([]byte(channel))
+	return int32(h.Sum32() & 0x7fffffff)
+}
@@ -0,0 +1,265 @@
+package hwmon
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	hwsensorsservice "github.com/moeilijk/lhm-streamdeck/pkg/service"
+)
+
+func writeAttr(t *testing.T, path, value string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir %s: %v", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, []byte(value+"\n"), 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+// fakeChip creates root/<dir> with the given attributes and, when device is
+// set, a device symlink to root/devices/<device> like sysfs does.
+func fakeChip(t *testing.T, root, dir, device string, attrs map[string]string) {
+	t.Helper()
+	chipDir := filepath.Join(root, dir)
+	if err := os.MkdirAll(chipDir, 0o755); err != nil {
+		t.Fatalf("mkdir %s: %v", chipDir, err)
+	}
+	for name, value := range attrs {
+		writeAttr(t, filepath.Join(chipDir, name), value)
+	}
+	if device != "" {
+		target := filepath.Join(root, "devices", device)
+		if err := os.MkdirAll(target, 0o755); err != nil {
+			t.Fatalf("mkdir %s: %v", target, err)
+		}
+		if err := os.Symlink(target, filepath.Join(chipDir, "device")); err != nil {
+			t.Fatalf("symlink device: %v", err)
+		}
+	}
+}
+
+func readingsByLabel(t *testing.T, s *Service, sensorID string) map[string]hwsensorsservice.Reading {
+	t.Helper()
+	rs, err := s.ReadingsForSensorID(sensorID)
+	if err != nil {
+		t.Fatalf("ReadingsForSensorID(%s): %v", sensorID, err)
+	}
+	out := make(map[string]hwsensorsservice.Reading, len(rs))
+	for _, r := range rs {
+		out[r.Label()] = r
+	}
+	return out
+}
+
+func TestServiceScalesChannels(t *testing.T) {
+	root := t.TempDir()
+	fakeChip(t, root, "hwmon0", "0000:00:18.3", map[string]string{
+		"name":           "k10temp",
+		"temp1_input":    "45250",
+		"temp1_label":    "Tctl",
+		"temp3_input":    "38000",
+		"fan2_input":     "1200",
+		"in0_input":      "1215",
+		"power1_input":   "65000000",
+		"curr1_input":    "2500",
+		"temp1_max":      "95000",
+		"uevent":         "",
+		"power2_average": "12500000",
+	})
+
+	s := NewService(root)
+	sensors, err := s.Sensors()
+	if err != nil {
+		t.Fatalf("Sensors: %v", err)
+	}
+	if len(sensors) != 1 {
+		t.Fatalf("expected 1 sensor, got %d", len(sensors))
+	}
+	if got, want := sensors[0].ID(), "/hwmon/k10temp/0000:00:18.3"; got != want {
+		t.Fatalf("sensor id = %q, want %q", got, want)
+	}
+	if got := sensors[0].Name(); got != "k10temp" {
+		t.Fatalf("sensor name = %q, want k10temp", got)
+	}
+
+	byLabel := readingsByLabel(t, s, sensors[0].ID())
+	cases := []struct {
+		label string
+		value float64
+		unit  string
+		typ   hwsensorsservice.ReadingType
+	}{
+		{"Tctl", 45.25, "°C", hwsensorsservice.ReadingTypeTemp},
+		{"Temp 3", 38, "°C", hwsensorsservice.ReadingTypeTemp},
+		{"Fan 2", 1200, "RPM", hwsensorsservice.ReadingTypeFan},
+		{"Voltage 0", 1.215, "V", hwsensorsservice.ReadingTypeVolt},
+		{"Power 1", 65, "W", hwsensorsservice.ReadingTypePower},
+		{"Power 2", 12.5, "W", hwsensorsservice.ReadingTypePower},
+		{"Current 1", 2.5, "A", hwsensorsservice.ReadingTypeCurrent},
+	}
+	if len(byLabel) != len(cases) {
+		t.Fatalf("expected %d readings, got %d: %v", len(cases), len(byLabel), byLabel)
+	}
+	for _, tc := range cases {
+		r, ok := byLabel[tc.label]
+		if !ok {
+			t.Fatalf("missing reading %q", tc.label)
+		}
+		if r.Value() != tc.value || r.Unit() != tc.unit || hwsensorsservice.ReadingType(r.TypeI()) != tc.typ {
+			t.Fatalf("%s: got value=%v unit=%q type=%v, want %v %q %v",
+				tc.label, r.Value(), r.Unit(), hwsensorsservice.ReadingType(r.TypeI()), tc.value, tc.unit, tc.typ)
+		}
+	}
+}
+
+func TestServiceIDsSurviveRenumbering(t *testing.T) {
+	first := t.TempDir()
+	fakeChip(t, first, "hwmon0", "0000:00:18.3", map[string]string{"name": "k10temp", "temp1_input": "40000"})
+	fakeChip(t, first, "hwmon1", "nvme0", map[string]string{"name": "nvme", "temp1_input": "35000"})
+
+	second := t.TempDir()
+	fakeChip(t, second, "hwmon4", "nvme0", map[string]string{"name": "nvme", "temp1_input": "36000"})
+	fakeChip(t, second, "hwmon7", "0000:00:18.3", map[string]string{"name": "k10temp", "temp1_input": "41000"})
+
+	ids := func(root string) map[string]int32 {
+		s := NewService(root)
+		sensors, err := s.Sensors()
+		if err != nil {
+			t.Fatalf("Sensors: %v", err)
+		}
+		out := make(map[string]int32)
+		for _, sn := range sensors {
+			rs, err := s.ReadingsForSensorID(sn.ID())
+			if err != nil {
+				t.Fatalf("ReadingsForSensorID: %v", err)
+			}
+			out[sn.ID()] = rs[0].ID()
+		}
+		return out
+	}
+
+	a, b := ids(first), ids(second)
+	if len(a) != 2 {
+		t.Fatalf("expected 2 sensors, got %v", a)
+	}
+	for sid, rid := range a {
+		if b[sid] != rid {
+			t.Fatalf("sensor %s: reading id %d after renumbering, want %d", sid, b[sid], rid)
+		}
+	}
+}
+
+func TestServiceDisambiguatesDuplicateChips(t *testing.T) {
+	root := t.TempDir()
+	fakeChip(t, root, "hwmon0", "", map[string]string{"name": "coretemp", "temp1_input": "40000"})
+	fakeChip(t, root, "hwmon1", "", map[string]string{"name": "coretemp", "temp1_input": "42000"})
+
+	sensors, err := NewService(root).Sensors()
+	if err != nil {
+		t.Fatalf("Sensors: %v", err)
+	}
+	if len(sensors) != 2 {
+		t.Fatalf("expected 2 sensors, got %d", len(sensors))
+	}
+	if sensors[0].ID() == sensors[1].ID() {
+		t.Fatalf("duplicate sensor ids: %s", sensors[0].ID())
+	}
+	if sensors[0].Name() == sensors[1].Name() {
+		t.Fatalf("duplicate sensor names: %s", sensors[0].Name())
+	}
+}
+
+func TestServiceLegacyDeviceAttributes(t *testing.T) {
+	root := t.TempDir()
+	fakeChip(t, root, "hwmon0", "it87.656", nil)
+	writeAttr(t, filepath.Join(root, "devices", "it87.656", "name"), "it8686")
+	writeAttr(t, filepath.Join(root, "devices", "it87.656", "fan1_input"), "900")
+
+	s := NewService(root)
+	sensors, err := s.Sensors()
+	if err != nil {
+		t.Fatalf("Sensors: %v", err)
+	}
+	if len(sensors) != 1 || sensors[0].Name() != "it8686" {
+		t.Fatalf("unexpected sensors: %+v", sensors)
+	}
+	if r := readingsByLabel(t, s, sensors[0].ID())["Fan 1"]; r == nil || r.Value() != 900 {
+		t.Fatalf("expected Fan 1 = 900, got %v", r)
+	}
+}
+
+func TestServiceTracksSessionExtremes(t *testing.T) {
+	root := t.TempDir()
+	fakeChip(t, root, "hwmon0", "", map[string]string{"name": "acpitz", "temp1_input": "50000"})
+	s := NewService(root)
+
+	for _, v := range []string{"50000", "62000", "47000", "55000"} {
+		writeAttr(t, filepath.Join(root, "hwmon0", "temp1_input"), v)
+		if _, err := s.PollTime(); err != nil {
+			t.Fatalf("PollTime: %v", err)
+		}
+	}
+	sensors, _ := s.Sensors()
+	r := readingsByLabel(t, s, sensors[0].ID())["Temp 1"]
+	if r.Value() != 55 || r.ValueMin() != 47 || r.ValueMax() != 62 {
+		t.Fatalf("got value=%v min=%v max=%v, want 55/47/62", r.Value(), r.ValueMin(), r.ValueMax())
+	}
+}
+
+func TestServiceSkipsUnreadableChannels(t *testing.T) {
+	root := t.TempDir()
+	fakeChip(t, root, "hwmon0", "", map[string]string{
+		"name":        "nct6798",
+		"fan1_input":  "1100",
+		"fan2_input":  "",
+		"temp7_input": "garbage",
+	})
+	s := NewService(root)
+	sensors, err := s.Sensors()
+	if err != nil {
+		t.Fatalf("Sensors: %v", err)
+	}
+	rs, _ := s.ReadingsForSensorID(sensors[0].ID())
+	if len(rs) != 1 || rs[0].Label() != "Fan 1" {
+		t.Fatalf("expected only Fan 1, got %d readings", len(rs))
+	}
+}
+
+func TestServiceEmptyRoot(t *testing.T) {
+	s := NewService(t.TempDir())
+	if _, err := s.PollTime(); err == nil {
+		t.Fatal("expected error for a tree without hwmon chips")
+	}
+	if _, err := NewService(filepath.Join(t.TempDir(), "missing")).Sensors(); err == nil {
+		t.Fatal("expected error for a missing root")
+	}
+}
+
+func TestServiceSnapshotMatchesReadings(t *testing.T) {
+	root := t.TempDir()
+	fakeChip(t, root, "hwmon0", "", map[string]string{"name": "acpitz", "temp1_input": "50000"})
+	s := NewService(root)
+
+	pollTime, err := s.PollTime()
+	if err != nil {
+		t.Fatalf("PollTime: %v", err)
+	}
+	snap, err := s.Snapshot(pollTime)
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	if snap.PollTime != pollTime || len(snap.Sensors) != 1 {
+		t.Fatalf("got poll time %d with %d sensors", snap.PollTime, len(snap.Sensors))
+	}
+	rs, err := snap.ReadingsForSensorID(snap.Sensors[0].ID())
+	if err != nil || len(rs) != 1 || rs[0].Value() != 50 {
+		t.Fatalf("snapshot readings = %v, %v", rs, err)
+	}
+
+	writeAttr(t, filepath.Join(root, "hwmon0", "temp1_input"), "51000")
+	if again, _ := s.Snapshot(pollTime); again != snap {
+		t.Fatal("expected the cached snapshot for an unchanged poll time")
+	}
+}
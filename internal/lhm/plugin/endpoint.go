@@ -0,0 +1,114 @@
+package plugin
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Endpoint describes how to reach an LHM-compatible data.json endpoint.
+type Endpoint struct {
+	URL         string // full URL, e.g. https://[fd00::2]:8443/lhm/data.json
+	Credentials Credentials
+	TLS         TLSOptions
+
+	// RecordDir, when set, receives a JSONL recording of every fetched
+	// data.json. Replay.File, when set, is served instead of polling URL.
+	RecordDir string
+	Replay    ReplayOptions
+
+	// StatWindows are the rolling windows readings carry statistics for;
+	// empty means DefaultStatWindows.
+	StatWindows []time.Duration
+}
+
+// Credentials authenticate requests against LHM's remote web server or
+// lhm-companion. A Token is sent as a bearer token and wins over
+// Username/Password, which are sent as HTTP Basic auth.
+type Credentials struct {
+	Username string
+	Password string
+	Token    string
+}
+
+// TLSOptions configure https endpoints, typically a companion behind a
+// reverse proxy with a private CA. They have no effect on plain http.
+type TLSOptions struct {
+	CAFile             string // PEM bundle trusted in addition to the system roots
+	InsecureSkipVerify bool
+}
+
+// EndpointFromEnv reads the endpoint the plugin hands to the bridge process:
+// LHM_ENDPOINT, LHM_USERNAME, LHM_PASSWORD, LHM_TOKEN, LHM_CA_FILE,
+// LHM_TLS_SKIP_VERIFY, LHM_RECORD_DIR, LHM_REPLAY_FILE, LHM_REPLAY_SPEED and
+// LHM_STAT_WINDOWS.
+func EndpointFromEnv() Endpoint {
+	skip, _ := strconv.ParseBool(os.Getenv("LHM_TLS_SKIP_VERIFY"))
+	speed, _ := strconv.ParseFloat(os.Getenv("LHM_REPLAY_SPEED"), 64)
+	windows, err := ParseStatWindows(os.Getenv("LHM_STAT_WINDOWS"))
+	if err != nil {
+		log.Printf("lhm: LHM_STAT_WINDOWS: %v, using defaults", err)
+	}
+	return Endpoint{
+		URL: os.Getenv("LHM_ENDPOINT"),
+		Credentials: Credentials{
+			Username: os.Getenv("LHM_USERNAME"),
+			Password: os.Getenv("LHM_PASSWORD"),
+			Token:    os.Getenv("LHM_TOKEN"),
+		},
+		TLS: TLSOptions{
+			CAFile:             os.Getenv("LHM_CA_FILE"),
+			InsecureSkipVerify: skip,
+		},
+		RecordDir: os.Getenv("LHM_RECORD_DIR"),
+		Replay: ReplayOptions{
+			File:  os.Getenv("LHM_REPLAY_FILE"),
+			Speed: speed,
+		},
+		StatWindows: windows,
+	}
+}
+
+// apply adds the Authorization header, if any, to req.
+func (c Credentials) apply(req *http.Request) {
+	switch {
+	case c.Token != "":
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	case c.Username != "" || c.Password != "":
+		req.SetBasicAuth(c.Username, c.Password)
+	}
+}
+
+// newHTTPClient builds the client used to poll an endpoint. The default
+// transport is kept unless TLS options ask for something else.
+func newHTTPClient(opts TLSOptions) (*http.Client, error) {
+	client := &http.Client{Timeout: 2 * time.Second}
+	if opts.CAFile == "" && !opts.InsecureSkipVerify {
+		return client, nil
+	}
+
+	cfg := &tls.Config{InsecureSkipVerify: opts.InsecureSkipVerify}
+	if opts.CAFile != "" {
+		pem, err := os.ReadFile(opts.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("read CA bundle: no certificates in %s", opts.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = cfg
+	client.Transport = transport
+	return client, nil
+}
@@ -0,0 +1,81 @@
+package plugin
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestHTTPServiceTLSOptions(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "..", "example.json"))
+	if err != nil {
+		t.Fatalf("read example.json: %v", err)
+	}
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/lhm/data.json" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(data)
+	}))
+	defer srv.Close()
+	url := srv.URL + "/lhm/data.json"
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	block := &pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}
+	if err := os.WriteFile(caFile, pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatalf("write CA bundle: %v", err)
+	}
+
+	if _, err := newTestHTTPService(t, Endpoint{URL: url}).PollTime(); err == nil {
+		t.Fatal("expected an untrusted certificate to be rejected")
+	}
+	if _, err := newTestHTTPService(t, Endpoint{URL: url, TLS: TLSOptions{CAFile: caFile}}).PollTime(); err != nil {
+		t.Fatalf("PollTime with CA bundle: %v", err)
+	}
+	if _, err := newTestHTTPService(t, Endpoint{URL: url, TLS: TLSOptions{InsecureSkipVerify: true}}).PollTime(); err != nil {
+		t.Fatalf("PollTime with skip-verify: %v", err)
+	}
+}
+
+func TestNewHTTPServiceRejectsBadCABundle(t *testing.T) {
+	empty := filepath.Join(t.TempDir(), "empty.pem")
+	if err := os.WriteFile(empty, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	for _, path := range []string{empty, filepath.Join(t.TempDir(), "missing.pem")} {
+		if _, err := NewHTTPService(Endpoint{URL: "https://127.0.0.1/data.json", TLS: TLSOptions{CAFile: path}}); err == nil {
+			t.Fatalf("expected error for CA bundle %s", path)
+		}
+	}
+}
+
+func TestEndpointFromEnv(t *testing.T) {
+	t.Setenv("LHM_ENDPOINT", "https://[fd00::2]:8443/lhm/data.json")
+	t.Setenv("LHM_TOKEN", "s3cret")
+	t.Setenv("LHM_CA_FILE", "/etc/ssl/lhm.pem")
+	t.Setenv("LHM_TLS_SKIP_VERIFY", "true")
+	t.Setenv("LHM_RECORD_DIR", "/var/log/lhm")
+	t.Setenv("LHM_REPLAY_FILE", "/tmp/incident.jsonl")
+	t.Setenv("LHM_REPLAY_SPEED", "4")
+	t.Setenv("LHM_STAT_WINDOWS", "30s, 10m")
+
+	ep := EndpointFromEnv()
+	if ep.URL != "https://[fd00::2]:8443/lhm/data.json" {
+		t.Fatalf("URL = %q, want the full LHM_ENDPOINT unchanged", ep.URL)
+	}
+	if ep.Credentials.Token != "s3cret" || ep.TLS.CAFile != "/etc/ssl/lhm.pem" || !ep.TLS.InsecureSkipVerify {
+		t.Fatalf("unexpected endpoint: %+v", ep)
+	}
+	if ep.RecordDir != "/var/log/lhm" || ep.Replay.File != "/tmp/incident.jsonl" || ep.Replay.Speed != 4 {
+		t.Fatalf("unexpected recording options: %+v", ep)
+	}
+	if want := []time.Duration{30 * time.Second, 10 * time.Minute}; !slices.Equal(ep.StatWindows, want) {
+		t.Fatalf("StatWindows = %v, want %v", ep.StatWindows, want)
+	}
+}
@@ -1,6 +1,9 @@
 package plugin
 
 import (
+	"context"
+	"time"
+
 	hwsensorsservice "github.com/moeilijk/lhm-streamdeck/pkg/service"
 )
 
@@ -39,3 +42,18 @@ func (p *Plugin) ReadingsForSensorID(id string) ([]hwsensorsservice.Reading, err
 	}
 	return out, nil
 }
+
+// Snapshot returns all sensors and readings from one poll.
+func (p *Plugin) Snapshot(pollTime uint64) (*hwsensorsservice.Snapshot, error) {
+	return p.Service.Snapshot(pollTime)
+}
+
+// Subscribe pushes a snapshot each time the service polls LHM.
+func (p *Plugin) Subscribe(ctx context.Context, interval time.Duration) (<-chan *hwsensorsservice.Snapshot, error) {
+	return p.Service.Subscribe(ctx, interval)
+}
+
+// Close releases the service, finishing any recording in progress.
+func (p *Plugin) Close() error {
+	return p.Service.Close()
+}
@@ -0,0 +1,170 @@
+package plugin
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// recordedFrame is one line of a recording: a data.json document and the
+// time it was fetched.
+type recordedFrame struct {
+	Time time.Time       `json:"t"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Recordings are rotated once a file reaches recordMaxFileBytes, and only the
+// newest recordMaxFiles files of a recorder are kept, so a forgotten recording
+// stays under 128 MiB however long the bridge runs.
+const (
+	recordMaxFileBytes = 32 << 20
+	recordMaxFiles     = 4
+)
+
+// Recorder appends every data.json the service fetches to a JSONL file, one
+// timestamped frame per line. Lines are written unbuffered so a recording is
+// complete up to the last poll even when the bridge is killed.
+type Recorder struct {
+	mu    sync.Mutex
+	dir   string
+	f     *os.File
+	path  string
+	size  int64
+	files []string // recordings written by this recorder, oldest first
+
+	maxFileBytes int64
+	maxFiles     int
+}
+
+// NewRecorder creates dir if needed and starts a new recording in it, named
+// after the current time, e.g. lhm-20240131-154500.jsonl.
+func NewRecorder(dir string) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create recording dir: %w", err)
+	}
+	r := &Recorder{dir: dir, maxFileBytes: recordMaxFileBytes, maxFiles: recordMaxFiles}
+	if err := r.openNext(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// openNext starts a new recording file. A second rotation within the same
+// second gets a numbered name instead of appending to the previous file.
+func (r *Recorder) openNext() error {
+	stamp := "lhm-" + time.Now().Format("20060102-150405")
+	for n := 1; ; n++ {
+		name := stamp + ".jsonl"
+		if n > 1 {
+			name = fmt.Sprintf("%s-%d.jsonl", stamp, n)
+		}
+		path := filepath.Join(r.dir, name)
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL|os.O_APPEND, 0o644)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("create recording: %w", err)
+		}
+		r.f, r.path, r.size = f, path, 0
+		r.files = append(r.files, path)
+		return nil
+	}
+}
+
+// rotate closes the current file, starts the next one and removes the oldest
+// recordings beyond maxFiles. Caller must hold r.mu.
+func (r *Recorder) rotate() error {
+	if err := r.f.Close(); err != nil {
+		return err
+	}
+	if err := r.openNext(); err != nil {
+		return err
+	}
+	for len(r.files) > r.maxFiles {
+		if err := os.Remove(r.files[0]); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			log.Printf("lhm: remove old recording: %v", err)
+		}
+		r.files = r.files[1:]
+	}
+	return nil
+}
+
+// Path returns the file the recorder currently writes to.
+func (r *Recorder) Path() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.path
+}
+
+// Write appends one frame. raw is compacted so the frame fits on one line.
+func (r *Recorder) Write(at time.Time, raw []byte) error {
+	var data bytes.Buffer
+	if err := json.Compact(&data, raw); err != nil {
+		return fmt.Errorf("record frame: %w", err)
+	}
+	line, err := json.Marshal(recordedFrame{Time: at, Data: data.Bytes()})
+	if err != nil {
+		return fmt.Errorf("record frame: %w", err)
+	}
+	line = append(line, '\n')
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.size > 0 && r.size+int64(len(line)) > r.maxFileBytes {
+		if err := r.rotate(); err != nil {
+			return fmt.Errorf("rotate recording: %w", err)
+		}
+	}
+	n, err := r.f.Write(line)
+	r.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("record frame: %w", err)
+	}
+	return nil
+}
+
+// Close closes the recording file.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.f.Close()
+}
+
+// readRecording loads all frames of a recording, oldest first.
+func readRecording(path string) ([]recordedFrame, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open recording: %w", err)
+	}
+	defer f.Close()
+
+	var frames []recordedFrame
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for line := 1; sc.Scan(); line++ {
+		if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+			continue
+		}
+		var fr recordedFrame
+		if err := json.Unmarshal(sc.Bytes(), &fr); err != nil {
+			return nil, fmt.Errorf("recording %s line %d: %w", filepath.Base(path), line, err)
+		}
+		frames = append(frames, fr)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read recording: %w", err)
+	}
+	if len(frames) == 0 {
+		return nil, fmt.Errorf("recording %s has no frames", filepath.Base(path))
+	}
+	return frames, nil
+}
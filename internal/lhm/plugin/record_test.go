@@ -0,0 +1,194 @@
+package plugin
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// cpuLoadDoc is a minimal data.json with a single CPU Total reading.
+func cpuLoadDoc(value float64) string {
+	return fmt.Sprintf(`{"Text":"Sensor","Children":[{"Text":"PC","Children":[{"Text":"AMD Ryzen","Children":[
+  {"Text":"Load","Children":[{"Text":"CPU Total","Value":"%.1f %%","SensorId":"/amdcpu/0/load/0","Type":"Load"}]}]}]}]}`, value)
+}
+
+func cpuTotal(t *testing.T, p *Plugin) float64 {
+	t.Helper()
+	rs, err := p.ReadingsForSensorID("/amdcpu/0")
+	if err != nil || len(rs) != 1 {
+		t.Fatalf("ReadingsForSensorID = %v, %v", rs, err)
+	}
+	return rs[0].Value()
+}
+
+func TestRecorderWritesEveryFetch(t *testing.T) {
+	var n atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(cpuLoadDoc(float64(10 * n.Add(1)))))
+	}))
+	defer srv.Close()
+
+	dir := filepath.Join(t.TempDir(), "recordings")
+	p := newTestHTTPService(t, Endpoint{URL: srv.URL, RecordDir: dir})
+	defer p.Service.rec.Close()
+	for i := 0; i < 3; i++ {
+		if _, err := p.PollTime(); err != nil {
+			t.Fatalf("PollTime: %v", err)
+		}
+	}
+
+	path := p.Service.rec.Path()
+	if filepath.Dir(path) != dir || !strings.HasPrefix(filepath.Base(path), "lhm-") || filepath.Ext(path) != ".jsonl" {
+		t.Fatalf("recording path = %s", path)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read recording: %v", err)
+	}
+	if lines := strings.Count(string(raw), "\n"); lines != 3 {
+		t.Fatalf("recording has %d lines, want one per fetch:\n%s", lines, raw)
+	}
+	frames, err := readRecording(path)
+	if err != nil {
+		t.Fatalf("readRecording: %v", err)
+	}
+	for i, fr := range frames {
+		if fr.Time.IsZero() || (i > 0 && fr.Time.Before(frames[i-1].Time)) {
+			t.Fatalf("frame %d has time %v", i, fr.Time)
+		}
+		if want := fmt.Sprintf(`"Value":"%.1f %%"`, float64(10*(i+1))); !strings.Contains(string(fr.Data), want) {
+			t.Fatalf("frame %d = %s, want %s", i, fr.Data, want)
+		}
+	}
+}
+
+func TestRecorderRotatesAndKeepsNewestFiles(t *testing.T) {
+	dir := t.TempDir()
+	keep := filepath.Join(dir, "incident.jsonl") // not ours, must survive
+	if err := os.WriteFile(keep, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rec, err := NewRecorder(dir)
+	if err != nil {
+		t.Fatalf("NewRecorder: %v", err)
+	}
+	defer rec.Close()
+	rec.maxFileBytes = 1 // every frame starts a new file
+	rec.maxFiles = 2
+
+	for i := 0; i < 5; i++ {
+		if err := rec.Write(time.Now(), []byte(cpuLoadDoc(float64(i)))); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	recordings, _ := filepath.Glob(filepath.Join(dir, "lhm-*.jsonl"))
+	if len(recordings) != 2 {
+		t.Fatalf("kept %d recordings, want 2: %v", len(recordings), recordings)
+	}
+	frames, err := readRecording(rec.Path())
+	if err != nil || len(frames) != 1 || !strings.Contains(string(frames[0].Data), `"Value":"4.0 %"`) {
+		t.Fatalf("current recording = %v, %v; want the last frame only", frames, err)
+	}
+	if _, err := os.Stat(keep); err != nil {
+		t.Fatalf("unrelated file removed: %v", err)
+	}
+}
+
+func TestServiceCloseFinishesRecording(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(cpuLoadDoc(10)))
+	}))
+	defer srv.Close()
+
+	p := newTestHTTPService(t, Endpoint{URL: srv.URL, RecordDir: t.TempDir()})
+	if _, err := p.PollTime(); err != nil {
+		t.Fatalf("PollTime: %v", err)
+	}
+	if err := p.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := p.Service.rec.Write(time.Now(), []byte(cpuLoadDoc(20))); err == nil {
+		t.Fatal("recording still writable after Close")
+	}
+}
+
+// writeRecording writes frames one second apart with the given CPU loads.
+func writeRecording(t *testing.T, loads ...float64) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "incident.jsonl")
+	rec := &Recorder{path: path, maxFileBytes: recordMaxFileBytes, maxFiles: recordMaxFiles}
+	var err error
+	if rec.f, err = os.Create(path); err != nil {
+		t.Fatal(err)
+	}
+	t0 := time.Date(2024, 7, 1, 14, 0, 0, 0, time.UTC)
+	for i, v := range loads {
+		if err := rec.Write(t0.Add(time.Duration(i)*time.Second), []byte(cpuLoadDoc(v))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReplayServesFramesAtSpeed(t *testing.T) {
+	p, err := NewReplayService(ReplayOptions{File: writeRecording(t, 10, 20, 30), Speed: 2})
+	if err != nil {
+		t.Fatalf("NewReplayService: %v", err)
+	}
+	src := p.Service.src.(*replaySource)
+	start := src.start
+	var now time.Time
+	src.now = func() time.Time { return now }
+
+	// Frames are 1s apart in the recording, 500ms apart at 2x; one pass lasts
+	// the recorded 2s plus one average gap.
+	for _, tc := range []struct {
+		elapsed time.Duration
+		want    float64
+		due     time.Duration
+	}{
+		{0, 10, 0},
+		{400 * time.Millisecond, 10, 0},
+		{500 * time.Millisecond, 20, 500 * time.Millisecond},
+		{1200 * time.Millisecond, 30, time.Second},
+		{1500 * time.Millisecond, 10, 1500 * time.Millisecond}, // looped
+		{2100 * time.Millisecond, 20, 2 * time.Second},
+	} {
+		now = start.Add(tc.elapsed)
+		pt, err := p.PollTime()
+		if err != nil {
+			t.Fatalf("PollTime at %v: %v", tc.elapsed, err)
+		}
+		if got := cpuTotal(t, p); got != tc.want {
+			t.Fatalf("at %v CPU Total = %v, want %v", tc.elapsed, got, tc.want)
+		}
+		if want := uint64(start.Add(tc.due).UnixNano()); pt != want {
+			t.Fatalf("at %v poll time = %v, want the frame's due time %v", tc.elapsed, time.Unix(0, int64(pt)), time.Unix(0, int64(want)))
+		}
+	}
+}
+
+func TestReplayRejectsBadRecordings(t *testing.T) {
+	empty := filepath.Join(t.TempDir(), "empty.jsonl")
+	if err := os.WriteFile(empty, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	broken := filepath.Join(t.TempDir(), "broken.jsonl")
+	if err := os.WriteFile(broken, []byte("{\"t\":\"2024-07-01T14:00:00Z\",\"data\":{}}\nnot json\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{empty, broken, filepath.Join(t.TempDir(), "missing.jsonl")} {
+		if _, err := NewReplayService(ReplayOptions{File: path}); err == nil {
+			t.Fatalf("NewReplayService(%s) succeeded", filepath.Base(path))
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package plugin
+
+import (
+	"sort"
+	"time"
+)
+
+// ReplayOptions make a service serve a recording instead of polling an
+// endpoint.
+type ReplayOptions struct {
+	File  string  // JSONL recording written by a Recorder
+	Speed float64 // playback speed, 1 = real time; <= 0 means 1
+}
+
+// replaySource plays a recording back against the wall clock, looping at the
+// end. Each poll gets the frame due at that moment, reported with the wall
+// time it became due, so tiles see a new poll time exactly when the recorded
+// data changed and the gaps of the recording are kept (scaled by speed).
+type replaySource struct {
+	frames  []recordedFrame
+	offsets []time.Duration // recorded time of each frame since the first
+	loop    time.Duration   // recorded length of one pass
+	speed   float64
+	start   time.Time
+	now     func() time.Time
+}
+
+func newReplaySource(opts ReplayOptions) (*replaySource, error) {
+	frames, err := readRecording(opts.File)
+	if err != nil {
+		return nil, err
+	}
+	speed := opts.Speed
+	if speed <= 0 {
+		speed = 1
+	}
+	offsets := make([]time.Duration, len(frames))
+	for i, fr := range frames {
+		offsets[i] = fr.Time.Sub(frames[0].Time)
+		if i > 0 && offsets[i] < offsets[i-1] {
+			offsets[i] = offsets[i-1] // clock stepped back while recording
+		}
+	}
+	// One pass lasts until the last frame plus an average gap, so the first
+	// frame does not immediately replace the last one when looping.
+	loop := time.Second
+	if n := len(frames); n > 1 && offsets[n-1] > 0 {
+		loop = offsets[n-1] + offsets[n-1]/time.Duration(n-1)
+	}
+	return &replaySource{
+		frames:  frames,
+		offsets: offsets,
+		loop:    loop,
+		speed:   speed,
+		start:   time.Now(),
+		now:     time.Now,
+	}, nil
+}
+
+func (r *replaySource) fetch() (fetched, error) {
+	elapsed := time.Duration(float64(r.now().Sub(r.start)) * r.speed)
+	pass := elapsed / r.loop
+	pos := elapsed % r.loop
+	i := sort.Search(len(r.offsets), func(i int) bool { return r.offsets[i] > pos }) - 1
+	if i < 0 {
+		i = 0
+	}
+	due := pass*r.loop + r.offsets[i]
+	return fetched{
+		raw: r.frames[i].Data,
+		at:  r.start.Add(time.Duration(float64(due) / r.speed)),
+	}, nil
+}
+
+// NewReplayService creates a Plugin serving a recording at the given speed.
+func NewReplayService(opts ReplayOptions) (*Plugin, error) {
+	s, err := newService(Endpoint{Replay: opts})
+	if err != nil {
+		return nil, err
+	}
+	return &Plugin{Service: s}, nil
+}
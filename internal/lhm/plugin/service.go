@@ -1,17 +1,20 @@
 package plugin
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"hash/fnv"
+	"io"
+	"log"
 	"net/http"
-	"os"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	hwsensorsservice "github.com/moeilijk/lhm-streamdeck/pkg/service"
+	"github.com/moeilijk/lhm-streamdeck/pkg/units"
 )
 
 const (
@@ -41,7 +44,8 @@ type reading struct {
 	normalizedValue float64
 	min             float64
 	max             float64
-	average         float64
+	average         float64 // mean over the shortest stat window
+	stats           []hwsensorsservice.WindowStats
 }
 
 func (r *reading) ID() int32                { return r.id }
@@ -55,6 +59,9 @@ func (r *reading) ValueMin() float64        { return r.min }
 func (r *reading) ValueMax() float64        { return r.max }
 func (r *reading) ValueAvg() float64        { return r.average }
 
+// Stats returns the rolling statistics per window, shortest first.
+func (r *reading) Stats() []hwsensorsservice.WindowStats { return r.stats }
+
 type sensor struct {
 	id   string
 	name string
@@ -65,8 +72,9 @@ func (s *sensor) Name() string { return s.name }
 
 // Service polls Libre Hardware Monitor and provides cached sensor data.
 type Service struct {
-	url    string
-	client *http.Client
+	src   dataSource
+	rec   *Recorder // nil unless the endpoint asks for a recording
+	stats *statsTracker
 
 	mu          sync.RWMutex
 	fetchMu     sync.Mutex
@@ -75,60 +83,146 @@ type Service struct {
 	sensorOrder []string
 	readings    map[string][]*reading
 	ready       bool
+	snap        *hwsensorsservice.Snapshot // built on first use after each poll
+
+	subs hwsensorsservice.Broadcaster
 }
 
-// StartService initializes the Libre Hardware Monitor bridge.
-func StartService() *Service {
-	url := os.Getenv("LHM_ENDPOINT")
-	if url == "" {
-		url = defaultEndpoint
-	}
+// StartService initializes the Libre Hardware Monitor bridge from the
+// LHM_* environment set by the plugin.
+func StartService() (*Service, error) {
+	return newService(EndpointFromEnv())
+}
 
-	return &Service{
-		url:    url,
-		client: &http.Client{Timeout: 2 * time.Second},
+// NewHTTPService creates a Plugin that polls the given LHM/companion HTTP
+// endpoint, or replays ep.Replay.File when set. Used on Linux for remote and
+// replay source profiles so no bridge subprocess is needed.
+func NewHTTPService(ep Endpoint) (*Plugin, error) {
+	s, err := newService(ep)
+	if err != nil {
+		return nil, err
 	}
+	return &Plugin{Service: s}, nil
 }
 
-// NewHTTPService creates a Plugin that polls the given LHM/companion HTTP endpoint.
-// Used on Linux for remote source profiles so no bridge subprocess is needed.
-func NewHTTPService(url string) *Plugin {
-	return &Plugin{Service: &Service{
-		url:    url,
-		client: &http.Client{Timeout: 2 * time.Second},
-	}}
+func newService(ep Endpoint) (*Service, error) {
+	if ep.Replay.File != "" {
+		src, err := newReplaySource(ep.Replay)
+		if err != nil {
+			return nil, err
+		}
+		return &Service{src: src, stats: newStatsTracker(ep.StatWindows)}, nil
+	}
+
+	if ep.URL == "" {
+		ep.URL = defaultEndpoint
+	}
+	client, err := newHTTPClient(ep.TLS)
+	if err != nil {
+		return nil, err
+	}
+	s := &Service{
+		src:   &httpSource{url: ep.URL, creds: ep.Credentials, client: client},
+		stats: newStatsTracker(ep.StatWindows),
+	}
+	if ep.RecordDir != "" {
+		if s.rec, err = NewRecorder(ep.RecordDir); err != nil {
+			return nil, err
+		}
+		log.Printf("lhm: recording %s to %s", ep.URL, s.rec.Path())
+	}
+	return s, nil
 }
 
-// Recv pulls the latest snapshot from Libre Hardware Monitor.
+// Recv pulls the latest snapshot from Libre Hardware Monitor and pushes it to
+// any subscribers.
 func (s *Service) Recv() error {
-	resp, err := s.client.Get(s.url)
-	if err != nil {
-		return fmt.Errorf("request LHM data: %w", err)
+	err := s.recv()
+	if s.subs.Len() > 0 {
+		if err != nil {
+			s.subs.Publish(&hwsensorsservice.Snapshot{Err: err})
+		} else {
+			s.subs.Publish(s.snapshot())
+		}
 	}
-	defer resp.Body.Close()
+	return err
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("request LHM data: status %s", resp.Status)
+func (s *Service) recv() error {
+	f, err := s.src.fetch()
+	if err != nil {
+		return err
 	}
 
 	var root node
-	if err := json.NewDecoder(resp.Body).Decode(&root); err != nil {
+	if err := json.Unmarshal(f.raw, &root); err != nil {
 		return fmt.Errorf("decode LHM response: %w", err)
 	}
+	if s.rec != nil {
+		if err := s.rec.Write(f.at, f.raw); err != nil {
+			log.Printf("lhm: %v", err)
+		}
+	}
 
 	sensors, order, readings := buildSnapshot(&root)
+	s.stats.observe(f.at, readings)
 
 	s.mu.Lock()
-	s.pollTime = uint64(time.Now().UnixNano())
+	s.pollTime = uint64(f.at.UnixNano())
 	s.sensors = sensors
 	s.sensorOrder = order
 	s.readings = readings
 	s.ready = true
+	s.snap = nil
 	s.mu.Unlock()
 
 	return nil
 }
 
+// dataSource yields the data.json documents a Service caches: an HTTP
+// endpoint, or a recording being replayed.
+type dataSource interface {
+	fetch() (fetched, error)
+}
+
+// fetched is one data.json document and the poll time to report for it.
+type fetched struct {
+	raw []byte
+	at  time.Time
+}
+
+// httpSource polls an LHM or lhm-companion endpoint.
+type httpSource struct {
+	url    string
+	creds  Credentials
+	client *http.Client
+}
+
+func (h *httpSource) fetch() (fetched, error) {
+	req, err := http.NewRequest(http.MethodGet, h.url, nil)
+	if err != nil {
+		return fetched{}, fmt.Errorf("request LHM data: %w", err)
+	}
+	h.creds.apply(req)
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return fetched{}, fmt.Errorf("request LHM data: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return fetched{}, hwsensorsservice.AuthError("request LHM data: authentication failed: status " + resp.Status)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fetched{}, fmt.Errorf("request LHM data: status %s", resp.Status)
+	}
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fetched{}, fmt.Errorf("read LHM response: %w", err)
+	}
+	return fetched{raw: raw, at: time.Now()}, nil
+}
+
 // refresh serializes snapshot fetches so concurrent RPC calls do not all hit LHM.
 func (s *Service) refresh() error {
 	s.fetchMu.Lock()
@@ -147,6 +241,80 @@ func (s *Service) ensureReady() error {
 	return s.refresh()
 }
 
+// Subscribe polls LHM every interval and delivers each new snapshot on the
+// returned channel until ctx is done.
+func (s *Service) Subscribe(ctx context.Context, interval time.Duration) (<-chan *hwsensorsservice.Snapshot, error) {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	ch := s.subs.Add()
+	go func() {
+		defer s.subs.Remove(ch)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			_ = s.refresh()
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// Snapshot returns every cached sensor with its readings. The cache is only
+// refreshed when it is older than pollTime, so all callers of one tick share
+// the same Snapshot.
+func (s *Service) Snapshot(pollTime uint64) (*hwsensorsservice.Snapshot, error) {
+	if err := s.ensureReady(); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	stale := s.pollTime < pollTime
+	s.mu.RUnlock()
+	if stale {
+		if err := s.refresh(); err != nil {
+			return nil, err
+		}
+	}
+	return s.snapshot(), nil
+}
+
+// snapshot returns the cache as an immutable Snapshot, copying it at most once
+// per poll.
+func (s *Service) snapshot() *hwsensorsservice.Snapshot {
+	s.mu.RLock()
+	snap := s.snap
+	s.mu.RUnlock()
+	if snap != nil {
+		return snap
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.snap != nil {
+		return s.snap
+	}
+	snap = &hwsensorsservice.Snapshot{
+		PollTime: s.pollTime,
+		Sensors:  make([]hwsensorsservice.Sensor, 0, len(s.sensorOrder)),
+		Readings: make(map[string][]hwsensorsservice.Reading, len(s.sensorOrder)),
+	}
+	for _, id := range s.sensorOrder {
+		snap.Sensors = append(snap.Sensors, &sensor{id: id, name: s.sensors[id].name})
+		rs := s.readings[id]
+		out := make([]hwsensorsservice.Reading, 0, len(rs))
+		for _, r := range rs {
+			out = append(out, r)
+		}
+		snap.Readings[id] = out
+	}
+	s.snap = snap
+	return snap
+}
+
 // PollTime returns the last time we updated the cache.
 func (s *Service) PollTime() (uint64, error) {
 	// PollTime is called by the plugin ticker and acts as the single poll trigger.
@@ -203,6 +371,15 @@ func (s *Service) ReadingsBySensorID(id string) ([]*reading, error) {
 	return out, nil
 }
 
+// Close stops the recording, if any. The service must not be polled
+// afterwards.
+func (s *Service) Close() error {
+	if s.rec == nil {
+		return nil
+	}
+	return s.rec.Close()
+}
+
 func buildSnapshot(root *node) (map[string]*sensor, []string, map[string][]*reading) {
 	sensors := make(map[string]*sensor)
 	sensorOrder := make([]string, 0)
@@ -332,12 +509,12 @@ func newReading(sensorID string, n *node) *reading {
 	max, _ := parseValue(n.Max)
 
 	rt := mapReadingType(n.Type)
-	normalizedVal := hwsensorsservice.NormalizeToBytes(val, unit)
+	normalizedVal := hwsensorsservice.NormalizeToBytes(val, unit.Symbol)
 
 	return &reading{
 		id:              makeReadingID(sensorID, n.SensorID),
 		label:           n.Text,
-		unit:            unit,
+		unit:            unit.Symbol,
 		typ:             n.Type,
 		typeI:           rt,
 		value:           val,
@@ -348,22 +525,24 @@ func newReading(sensorID string, n *node) *reading {
 	}
 }
 
-func parseValue(v string) (float64, string) {
+// parseValue splits an LHM value such as "45,5 °C" into its number and unit.
+// Units LHM spells loosely ("C", "kb/s") come back in their canonical form.
+func parseValue(v string) (float64, units.Unit) {
 	v = strings.TrimSpace(v)
 	if v == "" || v == "-" {
-		return 0, ""
+		return 0, units.Parse("")
 	}
 	fields := strings.Fields(v)
 	if len(fields) == 0 {
-		return 0, ""
+		return 0, units.Parse("")
 	}
 	num := strings.ReplaceAll(fields[0], ",", ".")
 	num = strings.TrimSpace(num)
+	unit := units.Parse(strings.TrimPrefix(v, fields[0]))
 	f, err := strconv.ParseFloat(num, 64)
 	if err != nil {
-		return 0, strings.TrimSpace(strings.TrimPrefix(v, fields[0]))
+		return 0, unit
 	}
-	unit := strings.TrimSpace(strings.TrimPrefix(v, fields[0]))
 	return f, unit
 }
 
@@ -410,8 +589,36 @@ func mapReadingType(t string) hwsensorsservice.ReadingType {
 		return hwsensorsservice.ReadingTypeClock
 	case "current":
 		return hwsensorsservice.ReadingTypeCurrent
-	case "load", "control", "level":
+	case "load":
 		return hwsensorsservice.ReadingTypeUsage
+	case "control":
+		return hwsensorsservice.ReadingTypeControl
+	case "level":
+		return hwsensorsservice.ReadingTypeLevel
+	case "throughput":
+		return hwsensorsservice.ReadingTypeThroughput
+	case "data":
+		return hwsensorsservice.ReadingTypeData
+	case "smalldata":
+		return hwsensorsservice.ReadingTypeSmallData
+	case "energy":
+		return hwsensorsservice.ReadingTypeEnergy
+	case "flow":
+		return hwsensorsservice.ReadingTypeFlow
+	case "noise":
+		return hwsensorsservice.ReadingTypeNoise
+	case "factor":
+		return hwsensorsservice.ReadingTypeFactor
+	case "frequency":
+		return hwsensorsservice.ReadingTypeFrequency
+	case "timespan":
+		return hwsensorsservice.ReadingTypeTimeSpan
+	case "timing":
+		return hwsensorsservice.ReadingTypeTiming
+	case "conductivity":
+		return hwsensorsservice.ReadingTypeConductivity
+	case "humidity":
+		return hwsensorsservice.ReadingTypeHumidity
 	default:
 		return hwsensorsservice.ReadingTypeOther
 	}
@@ -1,12 +1,29 @@
 package plugin
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	hwsensorsservice "github.com/moeilijk/lhm-streamdeck/pkg/service"
 )
 
+func newTestHTTPService(t *testing.T, ep Endpoint) *Plugin {
+	t.Helper()
+	p, err := NewHTTPService(ep)
+	if err != nil {
+		t.Fatalf("NewHTTPService: %v", err)
+	}
+	return p
+}
+
 func TestBuildSnapshotFromExample(t *testing.T) {
 	examplePath := filepath.Join("..", "..", "..", "example.json")
 	data, err := os.ReadFile(examplePath)
@@ -175,3 +192,179 @@ func TestBuildSnapshotDisambiguatesDuplicateSensorID(t *testing.T) {
 		}
 	}
 }
+
+func TestSubscribePushesSnapshotsAndErrors(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "..", "example.json"))
+	if err != nil {
+		t.Fatalf("read example.json: %v", err)
+	}
+	var failing atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			http.Error(w, "down", http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write(data)
+	}))
+	defer srv.Close()
+
+	p := newTestHTTPService(t, Endpoint{URL: srv.URL})
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := p.Subscribe(ctx, 20*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	first := <-ch
+	if first.Err != nil || first.PollTime == 0 {
+		t.Fatalf("unexpected first snapshot: %+v", first)
+	}
+	rs, err := first.ReadingsForSensorID("/amdcpu/0")
+	if err != nil || len(rs) == 0 || rs[0].Label() != "CPU Total" {
+		t.Fatalf("snapshot readings = %v, %v", rs, err)
+	}
+
+	failing.Store(true)
+	deadline := time.After(2 * time.Second)
+	for {
+		select {
+		case snap := <-ch:
+			if snap.Err == nil {
+				continue
+			}
+		case <-deadline:
+			t.Fatal("no error snapshot after LHM went down")
+		}
+		break
+	}
+
+	cancel()
+	for range ch {
+	}
+}
+
+func TestSnapshotSharedWithinOnePoll(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "..", "example.json"))
+	if err != nil {
+		t.Fatalf("read example.json: %v", err)
+	}
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write(data)
+	}))
+	defer srv.Close()
+
+	p := newTestHTTPService(t, Endpoint{URL: srv.URL})
+	pollTime, err := p.PollTime()
+	if err != nil {
+		t.Fatalf("PollTime: %v", err)
+	}
+	first, err := p.Snapshot(pollTime)
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	second, err := p.Snapshot(pollTime)
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	if first != second || first.PollTime != pollTime {
+		t.Fatal("expected both calls to share the snapshot of the current poll")
+	}
+	if got := requests.Load(); got != 1 {
+		t.Fatalf("LHM requested %d times, want 1", got)
+	}
+
+	newer, err := p.Snapshot(pollTime + 1)
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	if newer == first || requests.Load() != 2 {
+		t.Fatal("expected a newer poll time to refresh the cache")
+	}
+}
+
+func TestRecvSendsCredentialsAndReportsAuthFailure(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "..", "example.json"))
+	if err != nil {
+		t.Fatalf("read example.json: %v", err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "Bearer s3cret" {
+			_, _ = w.Write(data)
+			return
+		}
+		user, pass, ok := r.BasicAuth()
+		switch {
+		case !ok:
+			http.Error(w, "login required", http.StatusUnauthorized)
+		case user != "admin" || pass != "hunter2":
+			http.Error(w, "wrong credentials", http.StatusForbidden)
+		default:
+			_, _ = w.Write(data)
+		}
+	}))
+	defer srv.Close()
+
+	cases := []struct {
+		name    string
+		creds   Credentials
+		authErr bool
+	}{
+		{"basic", Credentials{Username: "admin", Password: "hunter2"}, false},
+		{"token", Credentials{Token: "s3cret", Username: "ignored"}, false},
+		{"missing", Credentials{}, true},
+		{"wrong", Credentials{Username: "admin", Password: "nope"}, true},
+	}
+	for _, tc := range cases {
+		_, err := newTestHTTPService(t, Endpoint{URL: srv.URL, Credentials: tc.creds}).PollTime()
+		if tc.authErr {
+			if !errors.Is(err, hwsensorsservice.ErrAuthFailed) {
+				t.Fatalf("%s: PollTime error = %v, want ErrAuthFailed", tc.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: PollTime: %v", tc.name, err)
+		}
+	}
+
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+	if _, err := newTestHTTPService(t, Endpoint{URL: down.URL}).PollTime(); err == nil || errors.Is(err, hwsensorsservice.ErrAuthFailed) {
+		t.Fatalf("503 error = %v, want a non-auth error", err)
+	}
+}
+
+func TestMapReadingTypeCoversLHMTypes(t *testing.T) {
+	for lhm, want := range map[string]hwsensorsservice.ReadingType{
+		"Voltage":      hwsensorsservice.ReadingTypeVolt,
+		"Current":      hwsensorsservice.ReadingTypeCurrent,
+		"Power":        hwsensorsservice.ReadingTypePower,
+		"Clock":        hwsensorsservice.ReadingTypeClock,
+		"Temperature":  hwsensorsservice.ReadingTypeTemp,
+		"Load":         hwsensorsservice.ReadingTypeUsage,
+		"Frequency":    hwsensorsservice.ReadingTypeFrequency,
+		"Fan":          hwsensorsservice.ReadingTypeFan,
+		"Flow":         hwsensorsservice.ReadingTypeFlow,
+		"Control":      hwsensorsservice.ReadingTypeControl,
+		"Level":        hwsensorsservice.ReadingTypeLevel,
+		"Factor":       hwsensorsservice.ReadingTypeFactor,
+		"Data":         hwsensorsservice.ReadingTypeData,
+		"SmallData":    hwsensorsservice.ReadingTypeSmallData,
+		"Throughput":   hwsensorsservice.ReadingTypeThroughput,
+		"TimeSpan":     hwsensorsservice.ReadingTypeTimeSpan,
+		"Timing":       hwsensorsservice.ReadingTypeTiming,
+		"Energy":       hwsensorsservice.ReadingTypeEnergy,
+		"Noise":        hwsensorsservice.ReadingTypeNoise,
+		"Conductivity": hwsensorsservice.ReadingTypeConductivity,
+		"Humidity":     hwsensorsservice.ReadingTypeHumidity,
+		"Unheard":      hwsensorsservice.ReadingTypeOther,
+	} {
+		if got := mapReadingType(lhm); got != want {
+			t.Errorf("mapReadingType(%q) = %v, want %v", lhm, got, want)
+		}
+	}
+}
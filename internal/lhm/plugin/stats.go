@@ -0,0 +1,171 @@
+package plugin
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	hwsensorsservice "github.com/moeilijk/lhm-streamdeck/pkg/service"
+)
+
+// DefaultStatWindows are the rolling windows tracked when an endpoint does not
+// configure its own.
+var DefaultStatWindows = []time.Duration{time.Minute, 5 * time.Minute, 15 * time.Minute}
+
+// ParseStatWindows parses a comma-separated list of durations such as
+// "1m,5m,15m". The result is sorted and free of duplicates.
+func ParseStatWindows(s string) ([]time.Duration, error) {
+	var out []time.Duration
+	for _, f := range strings.Split(s, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		d, err := time.ParseDuration(f)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid stat window %q", f)
+		}
+		out = append(out, d)
+	}
+	slices.Sort(out)
+	return slices.Compact(out), nil
+}
+
+type sample struct {
+	at    time.Time
+	value float64
+}
+
+// windowAgg keeps the running sum and monotonic min/max queues of one window
+// over the samples of a series. Indexes are absolute sample numbers, so they
+// stay valid while old samples are dropped from the front.
+type windowAgg struct {
+	d     time.Duration
+	first int
+	sum   float64
+	minq  []int
+	maxq  []int
+}
+
+// series is the sample history of one reading, trimmed to the longest window.
+// Adding a sample and reading all windows is amortized O(windows).
+type series struct {
+	samples []sample
+	base    int // absolute index of samples[0]
+	windows []windowAgg
+}
+
+func newSeries(windows []time.Duration) *series {
+	s := &series{windows: make([]windowAgg, len(windows))}
+	for i, d := range windows {
+		s.windows[i].d = d
+	}
+	return s
+}
+
+func (s *series) at(i int) sample { return s.samples[i-s.base] }
+
+func (s *series) add(at time.Time, v float64) {
+	s.samples = append(s.samples, sample{at: at, value: v})
+	idx := s.base + len(s.samples) - 1
+	oldest := idx
+	for i := range s.windows {
+		w := &s.windows[i]
+		w.sum += v
+		for len(w.minq) > 0 && s.at(w.minq[len(w.minq)-1]).value >= v {
+			w.minq = w.minq[:len(w.minq)-1]
+		}
+		w.minq = append(w.minq, idx)
+		for len(w.maxq) > 0 && s.at(w.maxq[len(w.maxq)-1]).value <= v {
+			w.maxq = w.maxq[:len(w.maxq)-1]
+		}
+		w.maxq = append(w.maxq, idx)
+
+		cutoff := at.Add(-w.d)
+		for w.first < idx && !s.at(w.first).at.After(cutoff) {
+			w.sum -= s.at(w.first).value
+			w.first++
+		}
+		for w.minq[0] < w.first {
+			w.minq = w.minq[1:]
+		}
+		for w.maxq[0] < w.first {
+			w.maxq = w.maxq[1:]
+		}
+		if w.first < oldest {
+			oldest = w.first
+		}
+	}
+	if n := oldest - s.base; n > 0 {
+		s.samples = s.samples[n:]
+		s.base = oldest
+	}
+}
+
+func (s *series) last() time.Time { return s.samples[len(s.samples)-1].at }
+
+func (s *series) stats() []hwsensorsservice.WindowStats {
+	last := s.base + len(s.samples) - 1
+	out := make([]hwsensorsservice.WindowStats, len(s.windows))
+	for i, w := range s.windows {
+		n := last - w.first + 1
+		out[i] = hwsensorsservice.WindowStats{
+			Window: w.d,
+			Min:    s.at(w.minq[0]).value,
+			Max:    s.at(w.maxq[0]).value,
+			Mean:   w.sum / float64(n),
+			Count:  n,
+		}
+	}
+	return out
+}
+
+type readingKey struct {
+	sensorID  string
+	readingID int32
+}
+
+// statsTracker keeps a series per reading across polls. A window holds the
+// samples polled within its duration before the newest one, which is always
+// included, so a freshly seen reading reports a count of one.
+type statsTracker struct {
+	mu      sync.Mutex
+	windows []time.Duration
+	series  map[readingKey]*series
+}
+
+func newStatsTracker(windows []time.Duration) *statsTracker {
+	if len(windows) == 0 {
+		windows = DefaultStatWindows
+	}
+	return &statsTracker{windows: windows, series: make(map[readingKey]*series)}
+}
+
+// observe adds one poll to the history and attaches the resulting statistics
+// to its readings. Readings that have not been seen for the longest window
+// are forgotten.
+func (t *statsTracker) observe(at time.Time, readings map[string][]*reading) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for sid, rs := range readings {
+		for _, r := range rs {
+			key := readingKey{sensorID: sid, readingID: r.id}
+			s := t.series[key]
+			if s == nil {
+				s = newSeries(t.windows)
+				t.series[key] = s
+			}
+			s.add(at, r.value)
+			r.stats = s.stats()
+			r.average = r.stats[0].Mean
+		}
+	}
+	cutoff := at.Add(-t.windows[len(t.windows)-1])
+	for key, s := range t.series {
+		if s.last().Before(cutoff) {
+			delete(t.series, key)
+		}
+	}
+}
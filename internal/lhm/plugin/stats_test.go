@@ -0,0 +1,80 @@
+package plugin
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	hwsensorsservice "github.com/moeilijk/lhm-streamdeck/pkg/service"
+)
+
+func TestSeriesRollingWindows(t *testing.T) {
+	s := newSeries([]time.Duration{10 * time.Second, time.Minute})
+	t0 := time.Date(2024, 7, 1, 14, 0, 0, 0, time.UTC)
+	// One sample every 5s for two minutes: 0, 5, 10, ... 120.
+	for i := 0; i <= 24; i++ {
+		s.add(t0.Add(time.Duration(i)*5*time.Second), float64(i*5))
+	}
+	got := s.stats()
+	want := []hwsensorsservice.WindowStats{
+		{Window: 10 * time.Second, Min: 115, Max: 120, Mean: 117.5, Count: 2},
+		{Window: time.Minute, Min: 65, Max: 120, Mean: 92.5, Count: 12},
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("stats = %+v\nwant %+v", got, want)
+	}
+	if len(s.samples) != 12 {
+		t.Fatalf("kept %d samples, want only the longest window's 12", len(s.samples))
+	}
+
+	// A falling value moves the min and evicts the old max.
+	for i := 0; i < 12; i++ {
+		s.add(t0.Add(125*time.Second+time.Duration(i)*5*time.Second), 1)
+	}
+	if st := s.stats()[1]; st.Min != 1 || st.Max != 1 || st.Count != 12 || math.Abs(st.Mean-1) > 1e-9 {
+		t.Fatalf("1m stats after drop = %+v", st)
+	}
+}
+
+func TestServiceReadingsCarryRollingStats(t *testing.T) {
+	loads := []float64{10, 30, 20}
+	var n int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(cpuLoadDoc(loads[n%len(loads)])))
+		n++
+	}))
+	defer srv.Close()
+
+	p := newTestHTTPService(t, Endpoint{URL: srv.URL, StatWindows: []time.Duration{time.Hour}})
+	for range loads {
+		if _, err := p.PollTime(); err != nil {
+			t.Fatalf("PollTime: %v", err)
+		}
+	}
+	rs, err := p.ReadingsForSensorID("/amdcpu/0")
+	if err != nil {
+		t.Fatalf("ReadingsForSensorID: %v", err)
+	}
+	st, ok := hwsensorsservice.ReadingStats(rs[0], time.Hour)
+	if !ok || st.Min != 10 || st.Max != 30 || st.Mean != 20 || st.Count != 3 {
+		t.Fatalf("1h stats = %+v, %v", st, ok)
+	}
+	if rs[0].Value() != 20 || rs[0].ValueAvg() != 20 {
+		t.Fatalf("Value = %v, ValueAvg = %v", rs[0].Value(), rs[0].ValueAvg())
+	}
+}
+
+func TestParseStatWindows(t *testing.T) {
+	got, err := ParseStatWindows("15m, 1m,5m,1m")
+	if want := []time.Duration{time.Minute, 5 * time.Minute, 15 * time.Minute}; err != nil || !slices.Equal(got, want) {
+		t.Fatalf("ParseStatWindows = %v, %v, want %v", got, err, want)
+	}
+	for _, bad := range []string{"5", "-1m", "0s"} {
+		if _, err := ParseStatWindows(bad); err == nil {
+			t.Fatalf("ParseStatWindows(%q) succeeded", bad)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package graph
+
+import (
+	"image/color"
+	"slices"
+)
+
+// GradientStop is a fill color at a value: in color-map mode a column whose
+// value lies between two stops is filled with a mix of their colors.
+type GradientStop struct {
+	Value float64
+	Color color.RGBA
+}
+
+// SetGradient switches the graph to color-map mode, where each column is
+// filled with the gradient color of its value instead of the foreground
+// color; nil or empty stops switch it back. Columns below the first stop
+// take its color, columns above the last stop the last one's.
+func (g *Graph) SetGradient(stops []GradientStop) {
+	g.gradient = append(g.gradient[:0], stops...)
+	slices.SortStableFunc(g.gradient, func(a, b GradientStop) int {
+		switch {
+		case a.Value < b.Value:
+			return -1
+		case a.Value > b.Value:
+			return 1
+		}
+		return 0
+	})
+	g.redraw = true
+}
+
+// fillColor returns the fill of a column whose value is plotted at vay.
+func (g *Graph) fillColor(vay int) *color.RGBA {
+	if len(g.gradient) == 0 {
+		return g.fgColor
+	}
+	c := g.gradientColor(float64(vay))
+	return &c
+}
+
+// gradientColor interpolates the gradient at plot height y. The stops are
+// placed at the height their value is plotted at, so the colors follow the
+// scale.
+func (g *Graph) gradientColor(y float64) color.RGBA {
+	stops := g.gradient
+	top := float64(g.effectiveHeight() - 1)
+	lo, hi := g.axis(g.lo), g.axis(g.hi)
+	at := func(v float64) float64 {
+		if hi == lo {
+			return 0
+		}
+		return (g.axis(v) - lo) / (hi - lo) * top
+	}
+	if y <= at(stops[0].Value) {
+		return stops[0].Color
+	}
+	for i := 1; i < len(stops); i++ {
+		y1 := at(stops[i].Value)
+		if y > y1 {
+			continue
+		}
+		y0 := at(stops[i-1].Value)
+		if y1 == y0 {
+			return stops[i].Color
+		}
+		return lerpRGBA(stops[i-1].Color, stops[i].Color, (y-y0)/(y1-y0))
+	}
+	return stops[len(stops)-1].Color
+}
+
+func lerpRGBA(a, b color.RGBA, t float64) color.RGBA {
+	mix := func(x, y uint8) uint8 {
+		return uint8(float64(x) + (float64(y)-float64(x))*t + 0.5)
+	}
+	return color.RGBA{mix(a.R, b.R), mix(a.G, b.G), mix(a.B, b.B), mix(a.A, b.A)}
+}
@@ -16,8 +16,11 @@ import (
 
 	"image"
 	"image/color"
+	"image/draw"
 	"image/png"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Label struct contains text, position and color information
@@ -38,19 +41,36 @@ type Graph struct {
 	min    int
 	max    int
 
-	yvals []uint8
+	samples   sampleRing // raw history the pixel cache is plotted from
+	autoScale AutoScale
+	scale     Scale
+	logFloor  float64 // lowest value a ScaleLog10 graph shows; <= 0 means 1
+	seenLo    float64 // range of every sample since start, for AutoScaleSinceStart
+	seenHi    float64
+	lo, hi    float64  // range yvals is plotted at
+	peers     []*Graph // graphs whose data auto scale also fits, see ShareScale
 
-	fgColor *color.RGBA
-	bgColor *color.RGBA
-	hlColor *color.RGBA
+	window  time.Duration // time the graph spans; 0 plots one column per sample
+	buckets []bucket      // time-window mode: per-column aggregates, oldest first
+
+	yvals []int // pixel cache: y-position of each visible sample, oldest first
+
+	markers []Marker // reference lines drawn over the plot when encoding
+
+	fgColor  *color.RGBA
+	bgColor  *color.RGBA
+	hlColor  *color.RGBA
+	gradient []GradientStop // color-map mode fill, sorted by value; empty fills with fgColor
 
 	labels          map[int]*Label
 	drawn           bool
 	redraw          bool
+	style           Style
 	heightPct       int         // 10–100; 0 means 100
 	lineThickness   int         // 1–4; 0 means 1
 	textStroke      bool        // draw outline around labels
 	textStrokeColor *color.RGBA // nil = use bgColor
+	pixelRatio      int         // device pixels per canvas pixel when smooth; 0 means 1
 }
 
 // FontFaceManager builds and caches fonts based on size
@@ -73,7 +93,59 @@ func (f *FontFaceManager) newFace(size float64) (font.Face, error) {
 	if err != nil {
 		return nil, fmt.Errorf("parse font: %w", err)
 	}
-	return truetype.NewFace(tt, &truetype.Options{Size: size, DPI: 72}), nil
+	return &lockedFace{face: truetype.NewFace(tt, &truetype.Options{Size: size, DPI: 72})}, nil
+}
+
+// lockedFace lets tiles rendering on different goroutines share a cached
+// face; truetype faces keep glyph caches and scratch buffers that are not
+// safe for concurrent use.
+type lockedFace struct {
+	mu   sync.Mutex
+	face font.Face
+}
+
+func (f *lockedFace) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.face.Close()
+}
+
+// Glyph returns a copy of the glyph mask: the face reuses its mask buffer on
+// the next call, which may come from another goroutine before this one draws.
+func (f *lockedFace) Glyph(dot fixed.Point26_6, r rune) (image.Rectangle, image.Image, image.Point, fixed.Int26_6, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	dr, mask, maskp, advance, ok := f.face.Glyph(dot, r)
+	if !ok || mask == nil {
+		return dr, mask, maskp, advance, ok
+	}
+	m := image.NewAlpha(image.Rect(0, 0, dr.Dx(), dr.Dy()))
+	draw.Draw(m, m.Bounds(), mask, maskp, draw.Src)
+	return dr, m, image.Point{}, advance, ok
+}
+
+func (f *lockedFace) GlyphBounds(r rune) (fixed.Rectangle26_6, fixed.Int26_6, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.face.GlyphBounds(r)
+}
+
+func (f *lockedFace) GlyphAdvance(r rune) (fixed.Int26_6, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.face.GlyphAdvance(r)
+}
+
+func (f *lockedFace) Kern(r0, r1 rune) fixed.Int26_6 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.face.Kern(r0, r1)
+}
+
+func (f *lockedFace) Metrics() font.Metrics {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.face.Metrics()
 }
 
 // GetFaceOfSize returns font face for given size
@@ -92,23 +164,30 @@ func (f *FontFaceManager) GetFaceOfSize(size float64) (font.Face, error) {
 }
 
 type singleshared struct {
-	mu              sync.Mutex // serializes EncodePNG calls that share pngBuf/pngEnc
 	fontFaceManager *FontFaceManager
-	pngEnc          *png.Encoder
-	pngBuf          *bytes.Buffer
+	pngPool         *pngBufferPool
+	pngLevel        atomic.Int32 // png.CompressionLevel used by EncodeImage
+	rendering       atomic.Int32 // Rendering of every graph
+}
+
+// pngBufferPool lets concurrent encodes reuse the encoder's scratch
+// buffers.
+type pngBufferPool struct{ pool sync.Pool }
+
+func (p *pngBufferPool) Get() *png.EncoderBuffer {
+	b, _ := p.pool.Get().(*png.EncoderBuffer)
+	return b
 }
 
+func (p *pngBufferPool) Put(b *png.EncoderBuffer) { p.pool.Put(b) }
+
 var sharedinstance *singleshared
 var once sync.Once
 
 func shared() *singleshared {
 	once.Do(func() {
-		sharedinstance = &singleshared{
-			pngEnc: &png.Encoder{
-				CompressionLevel: png.NoCompression,
-			},
-			pngBuf: bytes.NewBuffer(make([]byte, 0, 15697)),
-		}
+		sharedinstance = &singleshared{pngPool: &pngBufferPool{}}
+		sharedinstance.pngLevel.Store(int32(png.NoCompression))
 		sharedinstance.fontFaceManager = NewFontFaceManager()
 	})
 	return sharedinstance
@@ -133,7 +212,10 @@ func NewGraph(width, height, min, max int, fgColor, bgColor, hlColor *color.RGBA
 		max:    max,
 		labels: labels,
 
-		yvals: make([]uint8, 0, width),
+		samples: newSampleRing(historySize(width)),
+		lo:      float64(min),
+		hi:      float64(max),
+		yvals:   make([]int, 0, width),
 
 		fgColor: fgColor,
 		bgColor: bgColor,
@@ -162,7 +244,19 @@ func (g *Graph) SetHighlightColor(clr *color.RGBA) {
 // SetHeightPct sets the fraction of tile height used by the graph (10–100).
 func (g *Graph) SetHeightPct(pct int) {
 	g.heightPct = pct
-	g.redraw = true
+	g.rescale()
+}
+
+// SetHeight resizes the canvas to height pixels, keeping the width, and
+// replots the history at the new size.
+func (g *Graph) SetHeight(height int) {
+	if height < 1 || height == g.height {
+		return
+	}
+	g.height = height
+	g.img = image.NewRGBA(image.Rect(0, 0, g.width, height))
+	g.drawn = g.samples.len() > 0
+	g.rescale()
 }
 
 // SetLineThickness sets the highlight-line thickness in pixels (1–4).
@@ -181,14 +275,25 @@ func (g *Graph) SetTextStrokeColor(clr *color.RGBA) {
 	g.textStrokeColor = clr
 }
 
-// SetMin sets the min value for the graph scale
+// SetMin sets the min value for the graph scale and replots the history
+// against it.
 func (g *Graph) SetMin(min int) {
 	g.min = min
+	g.rescale()
 }
 
-// SetMax sets the max value for the graph scale
+// SetMax sets the max value for the graph scale and replots the history
+// against it.
 func (g *Graph) SetMax(max int) {
 	g.max = max
+	g.rescale()
+}
+
+// SetAutoScale sets how the graph picks its scale; AutoScaleOff uses the
+// min and max. The history is replotted at the new scale.
+func (g *Graph) SetAutoScale(a AutoScale) {
+	g.autoScale = a
+	g.rescale()
 }
 
 // SetLabel given a key, set the initial text, position and color
@@ -217,6 +322,16 @@ func (g *Graph) SetLabelFontSize(key int, size float64) error {
 	return nil
 }
 
+// SetLabelY given a key, moves a pre-set label to row y
+func (g *Graph) SetLabelY(key int, y uint) error {
+	l, ok := g.labels[key]
+	if !ok {
+		return fmt.Errorf("Label with key (%d) does not exist", key)
+	}
+	l.y = y
+	return nil
+}
+
 // SetLabelColor given a key and color, sets the color of the text
 func (g *Graph) SetLabelColor(key int, clr *color.RGBA) error {
 	l, ok := g.labels[key]
@@ -244,59 +359,90 @@ func (g *Graph) drawGraph(x, vay, maxx int) {
 		lt = 1
 	}
 	effectiveH := g.effectiveHeight()
+	fill := g.fillColor(vay)
+	fills := g.style.Fills()
 	var clr *color.RGBA
 	for ; x <= maxx; x++ {
+		lo, hi, prevLo, prevHi := 0, -1, 0, -1
+		if g.lvay != -1 {
+			lo, hi, prevLo, prevHi = g.style.Connector(g.lvay, vay)
+		}
 		for y := 0; y < g.height; y++ {
 			if y >= effectiveH {
 				clr = g.bgColor
-			} else if y == vay {
+			} else if y >= vay && y < vay+lt {
+				// the sample, extended upwards for thickness > 1
 				clr = g.hlColor
-			} else if y > vay && y < vay+lt {
-				// extended highlight line for thickness > 1
+			} else if y >= lo && y <= hi {
 				clr = g.hlColor
-			} else if g.lvay != -1 && vay > g.lvay && vay >= y && y >= g.lvay {
-				clr = g.hlColor
-			} else if g.lvay != -1 && vay < g.lvay && vay <= y && y <= g.lvay {
-				clr = g.hlColor
-			} else if vay > y {
-				clr = g.fgColor
+			} else if fills && vay > y {
+				clr = fill
 			} else {
 				clr = g.bgColor
 			}
-			i := g.img.PixOffset(x, g.height-1-y)
-			g.img.Pix[i+0] = clr.R
-			g.img.Pix[i+1] = clr.G
-			g.img.Pix[i+2] = clr.B
-			g.img.Pix[i+3] = clr.A
+			g.setPixel(x, y, clr)
+		}
+		if x > 0 {
+			for y := max(prevLo, 0); y <= min(prevHi, effectiveH-1); y++ {
+				g.setPixel(x-1, y, g.hlColor)
+			}
 		}
 		g.lvay = vay
 	}
 }
 
+// setPixel sets the pixel at column x, plot height y.
+func (g *Graph) setPixel(x, y int, clr *color.RGBA) {
+	i := g.img.PixOffset(x, g.height-1-y)
+	g.img.Pix[i+0] = clr.R
+	g.img.Pix[i+1] = clr.G
+	g.img.Pix[i+2] = clr.B
+	g.img.Pix[i+3] = clr.A
+}
+
 // Update given a value draws the graph, shifting contents left. Call EncodePNG to get a rendered PNG
 func (g *Graph) Update(value float64) {
-	vay := vAsY(g.effectiveHeight()-1, value, g.min, g.max)
+	g.UpdateAt(value, time.Now())
+}
 
-	if len(g.yvals) >= g.width {
-		_, a := g.yvals[0], g.yvals[1:]
-		g.yvals = a
+// UpdateAt is Update for a sample taken at t.
+func (g *Graph) UpdateAt(value float64, t time.Time) {
+	s := Sample{Value: value, Time: t}
+	g.growHistory(t)
+	g.samples.push(s)
+	if g.samples.len() == 1 {
+		g.seenLo, g.seenHi = value, value
+	} else {
+		g.seenLo, g.seenHi = math.Min(g.seenLo, value), math.Max(g.seenHi, value)
 	}
-	g.yvals = append(g.yvals, uint8(vay))
 
-	if g.redraw {
-		g.lvay = -1
-		lyvals := len(g.yvals)
-		for idx := lyvals - 1; idx >= 0; idx-- {
-			x := g.width - lyvals + idx
-			maxx := x
-			if idx == 0 {
-				x = 0
-			}
-			v := int(g.yvals[idx])
-			g.drawGraph(x, v, maxx)
+	if g.window > 0 {
+		// The newest column changes with every sample; redrawing the
+		// whole canvas is as cheap as patching it.
+		g.addToBuckets(s)
+		if g.updateRange() {
+			g.rescalePeers()
+		}
+		g.rebuildYvals()
+		g.replot()
+		return
+	}
+
+	if g.updateRange() {
+		// The scale moved: every visible sample lands somewhere else.
+		g.rescalePeers()
+		g.rebuildYvals()
+		g.redraw = true
+	} else {
+		if len(g.yvals) >= g.width {
+			g.yvals = append(g.yvals[:0], g.yvals[1:]...)
 		}
-		g.lvay = int(g.yvals[lyvals-1])
-		g.redraw = false
+		g.yvals = append(g.yvals, g.valueY(value))
+	}
+	vay := g.yvals[len(g.yvals)-1]
+
+	if g.redraw {
+		g.replot()
 	} else if g.drawn {
 		// shift the graph left 1px (in-place, avoid allocations)
 		stride := g.img.Stride
@@ -316,26 +462,167 @@ func (g *Graph) Update(value float64) {
 	}
 }
 
+// replot redraws the canvas from the pixel cache the way successive Updates
+// would have drawn it: the oldest sample also fills the columns to its left.
+func (g *Graph) replot() {
+	if g.window > 0 {
+		g.replotBuckets()
+		return
+	}
+	g.lvay = -1
+	n := len(g.yvals)
+	for idx, v := range g.yvals {
+		x := g.width - n + idx
+		from := x
+		if idx == 0 {
+			from = 0
+		}
+		g.drawGraph(from, v, x)
+	}
+	g.redraw = false
+	g.drawn = n > 0
+}
+
+// rescale replots the history after the scale or plot height changed.
+func (g *Graph) rescale() {
+	g.updateRange()
+	g.rebuildYvals()
+	if g.drawn && len(g.yvals) > 0 {
+		g.replot()
+	} else {
+		g.redraw = true
+	}
+}
+
+// updateRange sets the range the history is plotted at, reporting whether it
+// changed.
+func (g *Graph) updateRange() bool {
+	lo, hi := g.scaleRange()
+	if lo == g.lo && hi == g.hi {
+		return false
+	}
+	g.lo, g.hi = lo, hi
+	return true
+}
+
+// scaleRange returns the value range the graph is plotted at.
+func (g *Graph) scaleRange() (lo, hi float64) {
+	return g.fitScale(g.dataRange())
+}
+
+// dataRange returns min/max or, with auto scale, the range of the data.
+func (g *Graph) dataRange() (lo, hi float64) {
+	if g.autoScale == AutoScaleOff || g.samples.len() == 0 {
+		return float64(g.min), float64(g.max)
+	}
+	lo, hi = g.extremes(g.autoScale)
+	for _, p := range g.peers {
+		if p.samples.len() > 0 {
+			plo, phi := p.extremes(g.autoScale)
+			lo, hi = math.Min(lo, plo), math.Max(hi, phi)
+		}
+	}
+	if lo == hi {
+		// A flat line sits mid-height rather than on an edge.
+		lo, hi = lo-1, hi+1
+	}
+	return lo, hi
+}
+
+// extremes returns the lowest and highest value auto scale mode a fits to.
+func (g *Graph) extremes(a AutoScale) (lo, hi float64) {
+	switch {
+	case a == AutoScaleSinceStart:
+		return g.seenLo, g.seenHi
+	case g.window > 0:
+		return g.bucketRange()
+	}
+	lo, hi = math.Inf(1), math.Inf(-1)
+	g.samples.eachLast(g.width, func(s Sample) {
+		lo, hi = math.Min(lo, s.Value), math.Max(hi, s.Value)
+	})
+	return lo, hi
+}
+
+// rebuildYvals replots the visible samples into the pixel cache.
+func (g *Graph) rebuildYvals() {
+	if g.window > 0 {
+		g.rebuildBucketYvals()
+		return
+	}
+	g.yvals = g.yvals[:0]
+	g.samples.eachLast(g.width, func(s Sample) {
+		g.yvals = append(g.yvals, g.valueY(s.Value))
+	})
+}
+
+func (g *Graph) valueY(v float64) int {
+	return vAsY(g.effectiveHeight()-1, g.axis(v), g.axis(g.lo), g.axis(g.hi))
+}
+
+// valueYf is valueY before rounding, for smooth rendering.
+func (g *Graph) valueYf(v float64) float64 {
+	lo, hi := g.axis(g.lo), g.axis(g.hi)
+	if hi == lo {
+		return 0
+	}
+	return (g.axis(v) - lo) / (hi - lo) * float64(g.effectiveHeight()-1)
+}
+
+// pngSizeHint is the size of an uncompressed 72x72 tile.
+const pngSizeHint = 15697
+
 // EncodePNG renders the current state of the graph
 func (g *Graph) EncodePNG() ([]byte, error) {
+	return EncodeImage(g.render(false))
+}
+
+// Image returns the rendered graph, the image EncodePNG encodes. It is
+// RenderRatio times the size of the canvas.
+func (g *Graph) Image() *image.RGBA {
+	return g.render(false)
+}
+
+// render returns the canvas with the markers and labels drawn over it as a
+// new image, leaving the canvas itself untouched. flip turns the plot upside
+// down before the labels are drawn, so they stay upright.
+func (g *Graph) render(flip bool) *image.RGBA {
+	if CurrentRendering() == RenderingSmooth {
+		return g.renderSmooth(flip)
+	}
 	bak := append(g.img.Pix[:0:0], g.img.Pix...)
+	g.drawMarkers()
+	if flip {
+		flipRows(g.img)
+	}
 	for _, l := range g.labels {
-		g.drawLabel(l)
+		g.drawLabel(g.img, l, 1)
 	}
+	out := &image.RGBA{Pix: g.img.Pix, Stride: g.img.Stride, Rect: g.img.Rect}
+	g.img.Pix = bak
+	return out
+}
+
+// SetCompressionLevel sets the zlib level of every PNG encoded from now on.
+// No compression is cheapest to encode; higher levels trade CPU for smaller
+// images. The default is png.NoCompression.
+func SetCompressionLevel(level png.CompressionLevel) {
+	shared().pngLevel.Store(int32(level))
+}
+
+// EncodeImage encodes img as PNG at the level set by SetCompressionLevel. It
+// is safe for concurrent use.
+func EncodeImage(img image.Image) ([]byte, error) {
 	s := shared()
-	s.mu.Lock()
-	err := s.pngEnc.Encode(s.pngBuf, g.img)
-	if err != nil {
-		s.pngBuf.Reset()
-		s.mu.Unlock()
-		g.img.Pix = bak
+	enc := png.Encoder{
+		CompressionLevel: png.CompressionLevel(s.pngLevel.Load()),
+		BufferPool:       s.pngPool,
+	}
+	buf := bytes.NewBuffer(make([]byte, 0, pngSizeHint))
+	if err := enc.Encode(buf, img); err != nil {
 		return nil, err
 	}
-	bts := append([]byte(nil), s.pngBuf.Bytes()...)
-	s.pngBuf.Reset()
-	s.mu.Unlock()
-	g.img.Pix = bak
-	return bts, nil
+	return buf.Bytes(), nil
 }
 
 // Series returns a copy of the plotted history as y-positions in the range
@@ -343,11 +630,31 @@ func (g *Graph) EncodePNG() ([]byte, error) {
 // re-plot the same data natively at a different size (e.g. the stacked dial
 // strips) without rescaling a pre-rendered tile.
 func (g *Graph) Series() []uint8 {
+	maxY := g.effectiveHeight() - 1
 	out := make([]uint8, len(g.yvals))
-	copy(out, g.yvals)
+	for i, y := range g.yvals {
+		out[i] = uint8(min(max(y, 0), maxY, math.MaxUint8))
+	}
+	return out
+}
+
+// Samples returns a copy of the raw history, oldest first. It holds more
+// samples than fit on the graph, so callers can re-plot it at any scale or
+// width.
+func (g *Graph) Samples() []Sample {
+	out := make([]Sample, 0, g.samples.len())
+	g.samples.eachLast(g.samples.len(), func(s Sample) {
+		out = append(out, s)
+	})
 	return out
 }
 
+// Range returns the value range the graph is currently plotted at, which
+// follows the data under auto-scaling.
+func (g *Graph) Range() (lo, hi float64) {
+	return g.lo, g.hi
+}
+
 // EffectiveHeight is the exported height (in pixels) the series y-positions are
 // scaled against, honouring SetHeightPct.
 func (g *Graph) EffectiveHeight() int {
@@ -402,14 +709,20 @@ func (g *Graph) Clear() {
 	}
 	g.drawn = false
 	g.yvals = g.yvals[:0]
+	g.samples.reset()
+	g.buckets = g.buckets[:0]
+	g.lo, g.hi = g.scaleRange()
 }
 
-func vAsY(maxY int, v float64, minV, maxV int) int {
+func vAsY(maxY int, v float64, minV, maxV float64) int {
 	r := maxV - minV
-	v1 := v - float64(minV)
-	yf := v1 / float64(r) * float64(maxY)
-	yi := int(math.Round(yf))
-	return yi
+	if r == 0 {
+		return 0
+	}
+	yf := (v - minV) / r * float64(maxY)
+	// Keep far out-of-range values from overflowing; drawGraph clips.
+	const limit = 1 << 20
+	return int(math.Round(math.Max(-limit, math.Min(yf, limit))))
 }
 
 func unfix(x fixed.Int26_6) float64 {
@@ -438,15 +751,25 @@ func printableLabelText(text string) string {
 	}, text)
 }
 
-func (g *Graph) drawLabel(l *Label) {
+// defaultFontSize is the size of a label without one, as truetype picks for
+// a zero size.
+const defaultFontSize = 12
+
+// drawLabel draws l centred on dst, which is s times the size of the canvas
+// the label's row and font size are given in.
+func (g *Graph) drawLabel(dst *image.RGBA, l *Label, s float64) {
 	sh := shared()
 	lines := newlineRegex.Split(printableLabelText(l.text), -1)
-	face, err := sh.fontFaceManager.GetFaceOfSize(l.fontSize)
+	size := l.fontSize
+	if size <= 0 {
+		size = defaultFontSize
+	}
+	face, err := sh.fontFaceManager.GetFaceOfSize(size * s)
 	if err != nil {
 		log.Printf("drawLabel font: %v", err)
 		return
 	}
-	curY := l.y - uint(10.5-float64(face.Metrics().Height.Round()))
+	curY := float64(l.y)*s - math.Trunc(10.5*s-float64(face.Metrics().Height.Round()))
 
 	for _, line := range lines {
 		var lwidth float64
@@ -459,11 +782,11 @@ func (g *Graph) drawLabel(l *Label) {
 			lwidth += unfix(awidth)
 		}
 
-		lx := (float64(g.width) / 2.) - (lwidth / 2.)
+		lx := (float64(g.width) * s / 2.) - (lwidth / 2.)
 		point := fixed.Point26_6{X: fixed.Int26_6(lx * 64), Y: fixed.Int26_6(curY * 64)}
 
 		d := &font.Drawer{
-			Dst:  g.img,
+			Dst:  dst,
 			Src:  image.NewUniform(l.clr),
 			Face: face,
 			Dot:  point,
@@ -481,8 +804,8 @@ func (g *Graph) drawLabel(l *Label) {
 					}
 					d.Src = strokeSrc
 					d.Dot = fixed.Point26_6{
-						X: point.X + fixed.Int26_6(dx*64),
-						Y: point.Y + fixed.Int26_6(dy*64),
+						X: point.X + fixed.Int26_6(float64(dx)*s*64),
+						Y: point.Y + fixed.Int26_6(float64(dy)*s*64),
 					}
 					safeDrawString(d, line)
 				}
@@ -491,7 +814,7 @@ func (g *Graph) drawLabel(l *Label) {
 			d.Dot = point
 		}
 		safeDrawString(d, line)
-		curY += 12
+		curY += 12 * s
 	}
 }
 
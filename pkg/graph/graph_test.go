@@ -1,8 +1,14 @@
 package graph
 
 import (
+	"bytes"
+	"image"
 	"image/color"
+	"image/png"
+	"slices"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestGraphSupportsNonSquareCanvas(t *testing.T) {
@@ -32,3 +38,637 @@ func TestGraphLabelRenderingHandlesUnsafeRunes(t *testing.T) {
 		t.Fatalf("EncodePNG failed: %v", err)
 	}
 }
+
+func TestGraphsRenderConcurrently(t *testing.T) {
+	fg := &color.RGBA{0, 81, 40, 255}
+	bg := &color.RGBA{0, 0, 0, 255}
+	hl := &color.RGBA{0, 158, 0, 255}
+	render := func() []byte {
+		g := NewGraph(72, 72, 0, 100, fg, bg, hl)
+		g.SetLabel(0, "CPU 42°C", 19, &color.RGBA{255, 255, 255, 255})
+		g.Update(42)
+		bts, err := g.EncodePNG()
+		if err != nil {
+			t.Errorf("EncodePNG failed: %v", err)
+		}
+		return bts
+	}
+	want := render()
+
+	// Graphs share font faces and the PNG encoder; rendering on several
+	// goroutines at once must give the same image as rendering alone.
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				if got := render(); !bytes.Equal(got, want) {
+					t.Error("concurrent render differs from a serial one")
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestSetCompressionLevelShrinksImages(t *testing.T) {
+	fg := &color.RGBA{0, 81, 40, 255}
+	bg := &color.RGBA{0, 0, 0, 255}
+	hl := &color.RGBA{0, 158, 0, 255}
+	g := NewGraph(72, 72, 0, 100, fg, bg, hl)
+	g.Update(50)
+
+	defer SetCompressionLevel(png.NoCompression)
+	raw, err := g.EncodePNG()
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetCompressionLevel(png.BestCompression)
+	packed, err := g.EncodePNG()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(packed) >= len(raw) {
+		t.Fatalf("compressed PNG is %d bytes, uncompressed %d", len(packed), len(raw))
+	}
+
+	a, _ := png.Decode(bytes.NewReader(raw))
+	b, _ := png.Decode(bytes.NewReader(packed))
+	if !bytes.Equal(a.(*image.RGBA).Pix, b.(*image.RGBA).Pix) {
+		t.Fatal("compression changed the pixels")
+	}
+}
+
+func newTestGraph(min, max int) *Graph {
+	return NewGraph(72, 72, min, max, &color.RGBA{0, 81, 40, 255}, &color.RGBA{0, 0, 0, 255}, &color.RGBA{0, 158, 0, 255})
+}
+
+func TestSetMaxReplotsHistory(t *testing.T) {
+	values := []float64{10, 40, 80, 35, 60, 5, 90, 45}
+
+	rescaled := newTestGraph(0, 100)
+	for _, v := range values {
+		rescaled.Update(v)
+	}
+	rescaled.SetMax(200)
+
+	fresh := newTestGraph(0, 200)
+	for _, v := range values {
+		fresh.Update(v)
+	}
+
+	if !bytes.Equal(rescaled.img.Pix, fresh.img.Pix) {
+		t.Fatal("history after SetMax differs from a graph drawn at that scale")
+	}
+}
+
+func TestSetHeightPctReplotsHistory(t *testing.T) {
+	values := []float64{10, 40, 80, 35}
+
+	rescaled := newTestGraph(0, 100)
+	for _, v := range values {
+		rescaled.Update(v)
+	}
+	rescaled.SetHeightPct(50)
+
+	fresh := newTestGraph(0, 100)
+	fresh.SetHeightPct(50)
+	for _, v := range values {
+		fresh.Update(v)
+	}
+
+	if !bytes.Equal(rescaled.img.Pix, fresh.img.Pix) {
+		t.Fatal("history after SetHeightPct differs from a graph drawn at that height")
+	}
+}
+
+func TestSamplesKeepRawHistory(t *testing.T) {
+	g := newTestGraph(0, 100)
+	start := time.Unix(1000, 0)
+	for i := 0; i < minHistory+10; i++ {
+		g.UpdateAt(float64(i)+0.25, start.Add(time.Duration(i)*time.Second))
+	}
+
+	samples := g.Samples()
+	if len(samples) != minHistory {
+		t.Fatalf("len(Samples) = %d, want %d", len(samples), minHistory)
+	}
+	if first := samples[0]; first.Value != 10.25 || !first.Time.Equal(start.Add(10*time.Second)) {
+		t.Fatalf("oldest sample = %+v", first)
+	}
+	if last := samples[len(samples)-1]; last.Value != float64(minHistory+9)+0.25 {
+		t.Fatalf("newest sample = %+v", last)
+	}
+	if len(g.Series()) != 72 {
+		t.Fatalf("len(Series) = %d, want the graph width", len(g.Series()))
+	}
+
+	g.Clear()
+	if len(g.Samples()) != 0 {
+		t.Fatal("Clear kept the history")
+	}
+}
+
+func TestAutoScale(t *testing.T) {
+	tests := []struct {
+		name   string
+		mode   AutoScale
+		lo, hi float64
+	}{
+		{"off", AutoScaleOff, 0, 100},
+		{"window", AutoScaleWindow, 20, 30},
+		{"since start", AutoScaleSinceStart, -50, 30},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newTestGraph(0, 100)
+			g.SetAutoScale(tt.mode)
+			g.Update(-50) // scrolls off the 72px window below
+			for i := 0; i < 72; i++ {
+				g.Update(20 + float64(i%11))
+			}
+			if lo, hi := g.Range(); lo != tt.lo || hi != tt.hi {
+				t.Fatalf("Range() = %v..%v, want %v..%v", lo, hi, tt.lo, tt.hi)
+			}
+		})
+	}
+}
+
+func TestAutoScaleFlatLineSitsMidHeight(t *testing.T) {
+	g := newTestGraph(0, 100)
+	g.SetAutoScale(AutoScaleWindow)
+	g.Update(42)
+	g.Update(42)
+
+	series := g.Series()
+	if mid := uint8((g.EffectiveHeight() - 1) / 2); series[1] < mid || series[1] > mid+1 {
+		t.Fatalf("flat line at y=%d, want about %d", series[1], mid)
+	}
+}
+
+func TestSeriesClampsOutOfRangeValues(t *testing.T) {
+	g := newTestGraph(0, 100)
+	g.Update(-20)
+	g.Update(500)
+
+	series := g.Series()
+	if series[0] != 0 || int(series[1]) != g.EffectiveHeight()-1 {
+		t.Fatalf("Series() = %v, want values clamped to the plot", series)
+	}
+}
+
+func TestTimeWindowAggregatesBuckets(t *testing.T) {
+	g := newTestGraph(0, 100)
+	g.SetTimeWindow(72 * time.Second) // one second per column
+	t0 := time.Unix(1700000000, 0)
+
+	// Five polls in one second land in one column whatever the poll rate.
+	for i, v := range []float64{10, 90, 50, 50, 50} {
+		g.UpdateAt(v, t0.Add(time.Duration(i)*100*time.Millisecond))
+	}
+	g.UpdateAt(30, t0.Add(3*time.Second))
+
+	series := g.Series()
+	if len(series) != 4 {
+		t.Fatalf("len(Series()) = %d, want 4 columns (bucket, 2 carried over, bucket)", len(series))
+	}
+	if want := uint8(vAsY(71, 50, 0, 100)); series[0] != want {
+		t.Fatalf("first column at y=%d, want the mean's y=%d", series[0], want)
+	}
+
+	bg := color.RGBA{0, 0, 0, 255}
+	px := func(x int, v float64) color.RGBA {
+		return g.img.RGBAAt(x, g.height-1-vAsY(71, v, 0, 100))
+	}
+	// The spike to 90 shows as the min/max band above the mean...
+	if c := px(68, 80); c == bg || c == *g.fgColor {
+		t.Fatalf("no band between mean and max: %v", c)
+	}
+	// ...not above the max. The columns without samples continue the mean
+	// without the band, and nothing is drawn left of the first bucket.
+	if c := px(68, 95); c != bg {
+		t.Fatalf("band drawn above the max: %v", c)
+	}
+	if c := px(69, 5); c != *g.fgColor {
+		t.Fatalf("column without samples not filled: %v", c)
+	}
+	if c := px(69, 80); c != bg {
+		t.Fatalf("band carried into a column without samples: %v", c)
+	}
+	if c := px(67, 5); c != bg {
+		t.Fatalf("column before the first bucket drawn: %v", c)
+	}
+
+	// A sample a full window later scrolls the old buckets off.
+	g.UpdateAt(40, t0.Add(80*time.Second))
+	if n := len(g.Series()); n != 1 {
+		t.Fatalf("len(Series()) = %d after a window passed, want 1", n)
+	}
+}
+
+func TestSetTimeWindowRebuildsFromHistory(t *testing.T) {
+	g := newTestGraph(0, 100)
+	t0 := time.Unix(1700000000, 0)
+	for i := 0; i < 60; i++ {
+		g.UpdateAt(float64(i), t0.Add(time.Duration(i)*time.Second))
+	}
+	if n := len(g.Series()); n != 60 {
+		t.Fatalf("per-sample graph has %d columns, want 60", n)
+	}
+
+	g.SetTimeWindow(720 * time.Second) // ten seconds per column
+	if n := len(g.Series()); n != 6 {
+		t.Fatalf("time-window graph has %d columns, want 6", n)
+	}
+
+	g.SetAutoScale(AutoScaleWindow)
+	if lo, hi := g.Range(); lo != 0 || hi != 59 {
+		t.Fatalf("Range() = %v..%v, want the bucket extremes 0..59", lo, hi)
+	}
+
+	g.SetTimeWindow(0)
+	if n := len(g.Series()); n != 60 {
+		t.Fatalf("back to per-sample: %d columns, want 60", n)
+	}
+}
+
+func TestTimeWindowBucketsSurviveWindowChanges(t *testing.T) {
+	g := newTestGraph(0, 100)
+	g.SetTimeWindow(2 * time.Hour)
+	t0 := time.Unix(1700000000, 0)
+	for i := 0; i < 7200; i++ { // two hours at one sample a second
+		g.UpdateAt(float64(i%100), t0.Add(time.Duration(i)*time.Second))
+	}
+	want := append([]bucket(nil), g.buckets...)
+	if len(want) != g.width {
+		t.Fatalf("two-hour graph has %d buckets, want %d", len(want), g.width)
+	}
+
+	g.SetTimeWindow(30 * time.Minute)
+	if n := len(g.buckets); n != g.width {
+		t.Fatalf("30m graph has %d buckets, want %d", n, g.width)
+	}
+	g.SetTimeWindow(2 * time.Hour)
+	if !slices.Equal(g.buckets, want) {
+		t.Fatalf("buckets changed over a window round trip: %d buckets, want %d", len(g.buckets), len(want))
+	}
+}
+
+func TestTimeWindowHistoryStaysBounded(t *testing.T) {
+	g := newTestGraph(0, 100)
+	g.SetTimeWindow(24 * time.Hour)
+	t0 := time.Unix(1700000000, 0)
+	for i := 0; i < 2*MaxHistory; i++ {
+		g.UpdateAt(1, t0.Add(time.Duration(i)*time.Second))
+	}
+	if n := len(g.Samples()); n != MaxHistory {
+		t.Fatalf("history holds %d samples, want the MaxHistory cap %d", n, MaxHistory)
+	}
+}
+
+func TestMarkersFollowScaleAndHeight(t *testing.T) {
+	g := newTestGraph(0, 100)
+	g.Update(10)
+	red := color.RGBA{255, 0, 0, 255}
+	g.SetMarkers([]Marker{{Value: 80, Lo: 70, Hi: 80, Color: red}})
+
+	at := func(v float64) color.RGBA {
+		return g.img.RGBAAt(0, g.height-1-vAsY(g.effectiveHeight()-1, v, g.lo, g.hi))
+	}
+	bak := append([]uint8(nil), g.img.Pix...)
+	g.drawMarkers()
+	if c := at(80); c != red {
+		t.Fatalf("marker line = %v, want %v", c, red)
+	}
+	if c := g.img.RGBAAt(2, g.height-1-g.valueY(80)); c == red {
+		t.Fatal("marker line is not dashed")
+	}
+	if c := at(75); c.R == 0 || c == red {
+		t.Fatalf("hysteresis band = %v, want a translucent red", c)
+	}
+	if c := at(60); c.R != 0 {
+		t.Fatalf("band drawn outside its range: %v", c)
+	}
+
+	// At half height and double the max the line lands elsewhere, and one
+	// above the plot is not drawn.
+	copy(g.img.Pix, bak)
+	g.SetHeightPct(50)
+	g.SetMax(200)
+	g.drawMarkers()
+	if c := at(80); c != red {
+		t.Fatalf("marker line after rescale = %v, want %v", c, red)
+	}
+	copy(g.img.Pix, bak)
+	g.SetMarkers([]Marker{{Value: 300, Color: red}})
+	g.drawMarkers()
+	for y := 0; y < g.height; y++ {
+		if g.img.RGBAAt(0, y) == red {
+			t.Fatalf("marker above the plot drawn at row %d", y)
+		}
+	}
+}
+
+func TestGradientFillsColumnsByValue(t *testing.T) {
+	g := newTestGraph(0, 100)
+	green, red := color.RGBA{0, 255, 0, 255}, color.RGBA{255, 0, 0, 255}
+	g.SetGradient([]GradientStop{{Value: 90, Color: red}, {Value: 40, Color: green}})
+	for _, v := range []float64{20, 95, 65} {
+		g.Update(v)
+	}
+	fill := func(x int) color.RGBA { return g.img.RGBAAt(x, g.height-1) }
+	if c := fill(69); c != green {
+		t.Fatalf("column below the first stop = %v, want %v", c, green)
+	}
+	if c := fill(70); c != red {
+		t.Fatalf("column above the last stop = %v, want %v", c, red)
+	}
+	if c := fill(71); c.R == 0 || c.G == 0 || c.B != 0 {
+		t.Fatalf("column between the stops = %v, want a mix of green and red", c)
+	}
+
+	// Back to the foreground color on the next replot.
+	g.SetGradient(nil)
+	g.Update(65)
+	if c := fill(71); c != *g.fgColor {
+		t.Fatalf("column after clearing the gradient = %v, want %v", c, *g.fgColor)
+	}
+}
+
+func TestStylesDrawSamples(t *testing.T) {
+	const lo, mid, hi = 14, 45, 57 // rows of 20, ~63 and 80 on a 0..100 graph
+	bg := color.RGBA{0, 0, 0, 255}
+	for _, tc := range []struct {
+		style Style
+		// what column 71 shows between the samples and below the newest one,
+		// and column 70 just above the older sample
+		between, below, prevAbove string
+	}{
+		{StyleArea, "hl", "fg", "bg"},
+		{StyleLine, "hl", "bg", "hl"},
+		{StyleStep, "hl", "bg", "bg"},
+		{StyleBars, "fg", "fg", "bg"},
+		{StyleDots, "bg", "bg", "bg"},
+	} {
+		g := newTestGraph(0, 100)
+		g.SetStyle(tc.style)
+		g.Update(20)
+		g.Update(80)
+		name := func(y, x int) string {
+			switch g.img.RGBAAt(x, g.height-1-y) {
+			case *g.hlColor:
+				return "hl"
+			case *g.fgColor:
+				return "fg"
+			case bg:
+				return "bg"
+			}
+			return "?"
+		}
+		if c := name(hi, 71); c != "hl" {
+			t.Errorf("style %d: newest sample is %s, want hl", tc.style, c)
+		}
+		if c := name(mid, 71); c != tc.between {
+			t.Errorf("style %d: between the samples is %s, want %s", tc.style, c, tc.between)
+		}
+		if c := name(lo-5, 71); c != tc.below {
+			t.Errorf("style %d: below the newest sample is %s, want %s", tc.style, c, tc.below)
+		}
+		if c := name(lo+5, 70); c != tc.prevAbove {
+			t.Errorf("style %d: above the older sample is %s, want %s", tc.style, c, tc.prevAbove)
+		}
+	}
+}
+
+func TestLogScaleGivesDecadesEqualHeight(t *testing.T) {
+	g := newTestGraph(0, 1000000)
+	g.SetScale(ScaleLog10)
+	g.SetLogFloor(10)
+	for _, v := range []float64{0, 10, 1000, 1000000} {
+		g.Update(v)
+	}
+	if lo, hi := g.Range(); lo != 10 || hi != 1000000 {
+		t.Fatalf("Range() = %v..%v, want the floor up to max", lo, hi)
+	}
+	series := g.Series()
+	want := []uint8{0, 0, uint8(vAsY(71, 3, 1, 6)), 71}
+	for i := range want {
+		if series[i] != want[i] {
+			t.Fatalf("Series() = %v, want %v", series, want)
+		}
+	}
+}
+
+func TestSymmetricScaleCentersZero(t *testing.T) {
+	g := newTestGraph(-20, 100)
+	g.SetScale(ScaleSymmetric)
+	g.Update(0)
+	if lo, hi := g.Range(); lo != -100 || hi != 100 {
+		t.Fatalf("Range() = %v..%v, want -100..100", lo, hi)
+	}
+	if y := g.Series()[0]; y != uint8(vAsY(71, 0, -1, 1)) {
+		t.Fatalf("zero plotted at y=%d, want mid-height", y)
+	}
+
+	g.SetScale(ScaleLinear)
+	if lo, hi := g.Range(); lo != -20 || hi != 100 {
+		t.Fatalf("Range() back on linear = %v..%v, want -20..100", lo, hi)
+	}
+
+	// Auto scale fits the samples, the scale then centers that range.
+	g.SetScale(ScaleSymmetric)
+	g.SetAutoScale(AutoScaleWindow)
+	for _, v := range []float64{-5, 12, 3} {
+		g.Update(v)
+	}
+	if lo, hi := g.Range(); lo != -12 || hi != 12 {
+		t.Fatalf("auto scaled Range() = %v..%v, want -12..12", lo, hi)
+	}
+}
+
+func TestSetHeightReplotsHistory(t *testing.T) {
+	values := []float64{10, 40, 80, 35}
+
+	resized := newTestGraph(0, 100)
+	for _, v := range values {
+		resized.Update(v)
+	}
+	resized.SetHeight(36)
+
+	fresh := NewGraph(72, 36, 0, 100, &color.RGBA{0, 81, 40, 255}, &color.RGBA{0, 0, 0, 255}, &color.RGBA{0, 158, 0, 255})
+	for _, v := range values {
+		fresh.Update(v)
+	}
+
+	if !bytes.Equal(resized.img.Pix, fresh.img.Pix) {
+		t.Fatal("history after SetHeight differs from a graph drawn at that height")
+	}
+}
+
+func TestShareScaleFitsBothSeries(t *testing.T) {
+	up, down := newTestGraph(0, 100), newTestGraph(0, 100)
+	up.SetAutoScale(AutoScaleWindow)
+	down.SetAutoScale(AutoScaleWindow)
+	ShareScale(up, down)
+
+	up.Update(10)
+	down.Update(50)
+	up.Update(20)
+	for _, g := range []*Graph{up, down} {
+		if lo, hi := g.Range(); lo != 10 || hi != 50 {
+			t.Fatalf("Range() = %v..%v, want 10..50 on both graphs", lo, hi)
+		}
+	}
+	if y := down.Series()[0]; y != 71 {
+		t.Fatalf("down's sample at y=%d, want the top of the shared range", y)
+	}
+
+	ShareScale(up)
+	ShareScale(down)
+	up.Update(20)
+	if lo, hi := up.Range(); lo != 10 || hi != 20 {
+		t.Fatalf("Range() after unsharing = %v..%v, want 10..20", lo, hi)
+	}
+}
+
+func TestDrawMirroredFlipsLowerHalf(t *testing.T) {
+	up := NewGraph(72, 36, 0, 100, &color.RGBA{0, 81, 40, 255}, &color.RGBA{0, 0, 0, 255}, &color.RGBA{0, 158, 0, 255})
+	down := NewGraph(72, 36, 0, 100, &color.RGBA{80, 0, 0, 255}, &color.RGBA{0, 0, 0, 255}, &color.RGBA{200, 0, 0, 255})
+	up.Update(50)
+	down.Update(50)
+
+	before := append([]uint8(nil), down.img.Pix...)
+
+	dst := image.NewRGBA(image.Rect(0, 0, 72, 72))
+	DrawMirrored(dst, up, down)
+	// Both fills start at the centre line and reach halfway out.
+	for _, tt := range []struct {
+		y    int
+		want color.RGBA
+	}{
+		{35, color.RGBA{0, 81, 40, 255}},
+		{36, color.RGBA{80, 0, 0, 255}},
+		{5, color.RGBA{0, 0, 0, 255}},
+		{66, color.RGBA{0, 0, 0, 255}},
+	} {
+		if c := dst.RGBAAt(10, tt.y); c != tt.want {
+			t.Errorf("row %d = %v, want %v", tt.y, c, tt.want)
+		}
+	}
+	if !bytes.Equal(down.img.Pix, before) {
+		t.Fatal("DrawMirrored changed the canvas of the lower graph")
+	}
+}
+
+func TestSmoothRenderingAntiAliasesAtPixelRatio(t *testing.T) {
+	defer SetRendering(RenderingPixel)
+	g := newTestGraph(0, 100)
+	g.SetPixelRatio(2)
+	g.SetStyle(StyleLine)
+	for i := 0; i < 72; i++ {
+		g.Update(float64(i) * 100 / 71)
+	}
+
+	if b := g.Image().Bounds(); b != image.Rect(0, 0, 72, 72) {
+		t.Fatalf("pixel image bounds = %v, want the canvas size", b)
+	}
+	SetRendering(RenderingSmooth)
+	img := g.Image()
+	if b := img.Bounds(); b != image.Rect(0, 0, 144, 144) {
+		t.Fatalf("smooth image bounds = %v, want twice the canvas size", b)
+	}
+	// A diagonal line leaves partly covered pixels along its edges.
+	var edge, line int
+	for i := 0; i < len(img.Pix); i += 4 {
+		switch green := img.Pix[i+1]; {
+		case green == 158:
+			line++
+		case green > 0:
+			edge++
+		}
+	}
+	if line == 0 || edge == 0 {
+		t.Errorf("line pixels = %d, edge pixels = %d; want both", line, edge)
+	}
+	// The newest sample is drawn at the top right, the oldest bottom left.
+	if c := img.RGBAAt(143, 1); c.G == 0 {
+		t.Errorf("top right = %v, want the line", c)
+	}
+	if c := img.RGBAAt(0, 142); c.G == 0 {
+		t.Errorf("bottom left = %v, want the line", c)
+	}
+	if c := img.RGBAAt(20, 20); c != (color.RGBA{0, 0, 0, 255}) {
+		t.Errorf("above the line = %v, want background", c)
+	}
+}
+
+func TestSmoothRenderingDefaultsToCanvasSize(t *testing.T) {
+	defer SetRendering(RenderingPixel)
+	SetRendering(RenderingSmooth)
+	g := newTestGraph(0, 100)
+	g.Update(50)
+	if r := g.RenderRatio(); r != 1 {
+		t.Fatalf("RenderRatio() = %d, want 1 without a pixel ratio", r)
+	}
+	img := g.Image()
+	if b := img.Bounds(); b != image.Rect(0, 0, 72, 72) {
+		t.Fatalf("bounds = %v, want the canvas size", b)
+	}
+	// A flat sample fills exactly up to its row, like the canvas.
+	y := 71 - g.valueY(50)
+	if c := img.RGBAAt(10, y+3); c != (color.RGBA{0, 81, 40, 255}) {
+		t.Errorf("below the sample = %v, want the fill", c)
+	}
+	if c := img.RGBAAt(10, y-3); c != (color.RGBA{0, 0, 0, 255}) {
+		t.Errorf("above the sample = %v, want background", c)
+	}
+}
+
+func TestPaintSparklineStaysInRect(t *testing.T) {
+	g := newTestGraph(0, 100)
+	for i := 0; i < 30; i++ {
+		g.Update(float64(i % 7 * 10))
+	}
+	bg := color.RGBA{9, 9, 9, 255}
+	rect := image.Rect(10, 20, 50, 40)
+	paint := func(down bool) *image.RGBA {
+		img := image.NewRGBA(image.Rect(0, 0, 60, 60))
+		for i := 0; i < len(img.Pix); i += 4 {
+			copy(img.Pix[i:i+4], []uint8{bg.R, bg.G, bg.B, bg.A})
+		}
+		g.PaintSparkline(img, rect, down)
+		return img
+	}
+
+	for _, down := range []bool{false, true} {
+		img := paint(down)
+		soft := false
+		for y := 0; y < 60; y++ {
+			for x := 0; x < 60; x++ {
+				c := img.RGBAAt(x, y)
+				if !(image.Point{x, y}).In(rect) {
+					if c != bg {
+						t.Fatalf("down=%v: pixel %d,%d outside rect painted %v", down, x, y, c)
+					}
+					continue
+				}
+				if c != bg && c != g.ForegroundColor() && c != g.HighlightColor() {
+					soft = true
+				}
+			}
+		}
+		if !soft {
+			t.Errorf("down=%v: no anti-aliased pixels", down)
+		}
+		// The fill starts at the edge the plot grows from.
+		edge, far := rect.Max.Y-1, rect.Min.Y
+		if down {
+			edge, far = far, edge
+		}
+		if img.RGBAAt(rect.Max.X-1, edge) == bg || img.RGBAAt(rect.Min.X, far) != bg {
+			t.Errorf("down=%v: plot does not grow from its edge", down)
+		}
+	}
+}
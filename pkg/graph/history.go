@@ -0,0 +1,84 @@
+package graph
+
+import "time"
+
+// Sample is one raw value passed to Update.
+type Sample struct {
+	Value float64
+	Time  time.Time
+}
+
+// AutoScale selects how a graph derives its scale from the data.
+type AutoScale int
+
+const (
+	// AutoScaleOff plots between the min and max set on the graph.
+	AutoScaleOff AutoScale = iota
+	// AutoScaleWindow fits the samples currently on the graph.
+	AutoScaleWindow
+	// AutoScaleSinceStart fits every sample since the graph started or was
+	// cleared, so the scale only ever widens. With ScaleSymmetric either
+	// mode fits a range centered on zero.
+	AutoScaleSinceStart
+)
+
+// minHistory is the least number of raw samples a graph keeps, so a narrow
+// tile's history still fills a wider plot such as a dial strip.
+const minHistory = 256
+
+// MaxHistory caps how far a time-window graph grows its raw history, so a
+// long window at a fast poll stays bounded: two hours at one sample a second
+// fit.
+const MaxHistory = 8192
+
+func historySize(width int) int {
+	return max(width, minHistory)
+}
+
+// sampleRing is a ring buffer of samples; it only changes size through grow.
+type sampleRing struct {
+	buf   []Sample
+	start int // index of the oldest sample
+	n     int
+}
+
+func newSampleRing(size int) sampleRing {
+	return sampleRing{buf: make([]Sample, size)}
+}
+
+func (r *sampleRing) len() int { return r.n }
+
+func (r *sampleRing) push(s Sample) {
+	if r.n < len(r.buf) {
+		r.buf[(r.start+r.n)%len(r.buf)] = s
+		r.n++
+		return
+	}
+	r.buf[r.start] = s
+	r.start = (r.start + 1) % len(r.buf)
+}
+
+// eachLast calls fn for the newest n samples, oldest first.
+func (r *sampleRing) eachLast(n int, fn func(Sample)) {
+	n = min(n, r.n)
+	for i := r.n - n; i < r.n; i++ {
+		fn(r.buf[(r.start+i)%len(r.buf)])
+	}
+}
+
+func (r *sampleRing) oldest() Sample {
+	return r.buf[r.start]
+}
+
+// grow enlarges the ring to size samples, keeping its contents.
+func (r *sampleRing) grow(size int) {
+	buf := make([]Sample, 0, size)
+	r.eachLast(r.n, func(s Sample) {
+		buf = append(buf, s)
+	})
+	r.buf, r.start = buf[:size], 0
+}
+
+func (r *sampleRing) reset() {
+	r.start, r.n = 0, 0
+}
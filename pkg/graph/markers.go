@@ -0,0 +1,61 @@
+package graph
+
+import "image/color"
+
+// markerBandAlpha is how much of a marker's color is mixed into its band,
+// out of 256.
+const markerBandAlpha = 64
+
+// Marker is a horizontal reference line drawn over the graph, such as a
+// threshold. When Lo < Hi the range between them is shaded as a band.
+type Marker struct {
+	Value  float64
+	Lo, Hi float64
+	Color  color.RGBA
+}
+
+// SetMarkers replaces the reference lines drawn over the graph. They are
+// drawn when the graph is encoded, at the scale and height it is plotted at,
+// and do not scroll with the history.
+func (g *Graph) SetMarkers(markers []Marker) {
+	g.markers = append(g.markers[:0], markers...)
+}
+
+// drawMarkers draws the bands, then the dashed lines over them, clipped to
+// the plot area.
+func (g *Graph) drawMarkers() {
+	top := g.effectiveHeight() - 1
+	for _, m := range g.markers {
+		if m.Lo >= m.Hi {
+			continue
+		}
+		y0, y1 := max(g.valueY(m.Lo), 0), min(g.valueY(m.Hi), top)
+		for y := y0; y <= y1; y++ {
+			for x := 0; x < g.width; x++ {
+				g.mix(x, y, m.Color, markerBandAlpha)
+			}
+		}
+	}
+	for _, m := range g.markers {
+		y := g.valueY(m.Value)
+		if y < 0 || y > top {
+			continue
+		}
+		for x := 0; x < g.width; x += 4 {
+			g.mix(x, y, m.Color, 256)
+			if x+1 < g.width {
+				g.mix(x+1, y, m.Color, 256)
+			}
+		}
+	}
+}
+
+// mix blends a/256 of c into the pixel at column x, plot height y.
+func (g *Graph) mix(x, y int, c color.RGBA, a uint32) {
+	i := g.img.PixOffset(x, g.height-1-y)
+	px := g.img.Pix[i : i+4 : i+4]
+	px[0] = uint8((uint32(px[0])*(256-a) + uint32(c.R)*a) >> 8)
+	px[1] = uint8((uint32(px[1])*(256-a) + uint32(c.G)*a) >> 8)
+	px[2] = uint8((uint32(px[2])*(256-a) + uint32(c.B)*a) >> 8)
+	px[3] = uint8((uint32(px[3])*(256-a) + uint32(c.A)*a) >> 8)
+}
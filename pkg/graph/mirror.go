@@ -0,0 +1,61 @@
+package graph
+
+import (
+	"image"
+	"image/draw"
+)
+
+// ShareScale makes the graphs auto scale together: each fits the samples of
+// all of them, so two series drawn side by side compare at a glance. A graph
+// on Min/Max keeps its own range. Calling it again replaces the group of the
+// graphs passed; call it with each graph alone to stop sharing.
+func ShareScale(graphs ...*Graph) {
+	for _, g := range graphs {
+		g.peers = g.peers[:0]
+		for _, p := range graphs {
+			if p != g {
+				g.peers = append(g.peers, p)
+			}
+		}
+	}
+	for _, g := range graphs {
+		g.rescale()
+	}
+}
+
+// rescalePeers replots the graphs sharing g's scale after its range moved.
+func (g *Graph) rescalePeers() {
+	for _, p := range g.peers {
+		p.rescale()
+	}
+}
+
+// DrawMirrored draws two graphs back to back into dst: up in the top half
+// growing upward from the centre line, down below it flipped so it grows
+// downward. Each half keeps its own colors and markers; labels are drawn
+// upright. A nil graph leaves its half of dst as it was.
+func DrawMirrored(dst *image.RGBA, up, down *Graph) {
+	b := dst.Bounds()
+	mid := b.Min.Y + b.Dy()/2
+	if up != nil {
+		img := up.render(false)
+		draw.Draw(dst, image.Rect(b.Min.X, mid-img.Rect.Dy(), b.Max.X, mid), img, image.Point{}, draw.Src)
+	}
+	if down != nil {
+		img := down.render(true)
+		draw.Draw(dst, image.Rect(b.Min.X, mid, b.Max.X, mid+img.Rect.Dy()), img, image.Point{}, draw.Src)
+	}
+}
+
+// flipRows turns img upside down.
+func flipRows(img *image.RGBA) {
+	stride := img.Stride
+	row := make([]uint8, stride)
+	for top, bot := 0, img.Rect.Dy()-1; top < bot; top, bot = top+1, bot-1 {
+		t := img.Pix[top*stride : top*stride+stride]
+		b := img.Pix[bot*stride : bot*stride+stride]
+		copy(row, t)
+		copy(t, b)
+		copy(b, row)
+	}
+}
@@ -0,0 +1,69 @@
+package graph
+
+import "math"
+
+// Scale selects how values map to heights on the graph. It applies to the
+// range the graph is plotted at, whether that comes from min/max or from
+// auto scale.
+type Scale int
+
+const (
+	// ScaleLinear spaces values evenly.
+	ScaleLinear Scale = iota
+	// ScaleLog10 gives every decade the same height, so values spanning
+	// several orders of magnitude stay readable. Values at or below the
+	// floor sit on the bottom edge.
+	ScaleLog10
+	// ScaleSymmetric centers zero, stretching the range to the larger of
+	// its two ends so positive and negative values get the same height.
+	ScaleSymmetric
+)
+
+// defaultLogFloor is the floor of a log scale when none is set.
+const defaultLogFloor = 1
+
+// SetScale sets how values map to heights and replots the history.
+func (g *Graph) SetScale(s Scale) {
+	g.scale = s
+	g.rescale()
+}
+
+// SetLogFloor sets the lowest value a log scale shows; zero or less uses the
+// default of 1.
+func (g *Graph) SetLogFloor(floor float64) {
+	g.logFloor = floor
+	g.rescale()
+}
+
+func (g *Graph) floor() float64 {
+	if g.logFloor > 0 {
+		return g.logFloor
+	}
+	return defaultLogFloor
+}
+
+// axis maps v onto the axis of the graph's scale.
+func (g *Graph) axis(v float64) float64 {
+	if g.scale == ScaleLog10 {
+		return math.Log10(math.Max(v, g.floor()))
+	}
+	return v
+}
+
+// fitScale adjusts a value range to the graph's scale.
+func (g *Graph) fitScale(lo, hi float64) (float64, float64) {
+	switch g.scale {
+	case ScaleLog10:
+		lo = math.Max(lo, g.floor())
+		if hi <= lo {
+			hi = lo * 10
+		}
+	case ScaleSymmetric:
+		m := math.Max(math.Abs(lo), math.Abs(hi))
+		if m == 0 {
+			m = 1
+		}
+		lo, hi = -m, m
+	}
+	return lo, hi
+}
@@ -0,0 +1,375 @@
+package graph
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"golang.org/x/image/vector"
+)
+
+// Rendering selects how graphs are drawn when they are encoded.
+type Rendering int32
+
+const (
+	// RenderingPixel encodes the canvas as it is plotted: a column per
+	// sample with hard edges.
+	RenderingPixel Rendering = iota
+	// RenderingSmooth redraws the plot from the samples with anti-aliased
+	// edges and lines, at the graph's pixel ratio.
+	RenderingSmooth
+)
+
+// SetRendering sets how every graph is drawn from now on. The default is
+// RenderingPixel.
+func SetRendering(r Rendering) {
+	shared().rendering.Store(int32(r))
+}
+
+// CurrentRendering returns the rendering set by SetRendering.
+func CurrentRendering() Rendering {
+	return Rendering(shared().rendering.Load())
+}
+
+// SetPixelRatio sets how many device pixels a canvas pixel spans when the
+// graph is drawn smooth, e.g. 2 to draw a 72x72 graph for a 144x144 key.
+// Columns, label rows and font sizes stay in canvas pixels.
+func (g *Graph) SetPixelRatio(n int) {
+	g.pixelRatio = max(n, 1)
+}
+
+// RenderRatio returns how many device pixels a canvas pixel spans in the
+// images the graph encodes: its pixel ratio when drawn smooth, otherwise 1.
+func (g *Graph) RenderRatio() int {
+	if CurrentRendering() == RenderingSmooth {
+		return max(g.pixelRatio, 1)
+	}
+	return 1
+}
+
+// renderSmooth is render for RenderingSmooth. The plot is drawn from the
+// samples rather than the canvas, as shapes the rasterizer anti-aliases, so
+// slopes, line thickness and dots stay smooth at any pixel ratio.
+func (g *Graph) renderSmooth(flip bool) *image.RGBA {
+	s := g.RenderRatio()
+	out := image.NewRGBA(image.Rect(0, 0, g.width*s, g.height*s))
+	bg := g.BackgroundColor()
+	for i := 0; i < len(out.Pix); i += 4 {
+		copy(out.Pix[i:i+4], []uint8{bg.R, bg.G, bg.B, bg.A})
+	}
+	p := newPainter(out, g.effectiveHeight()*s, float64(s))
+	g.paintPlot(p)
+	g.paintMarkers(p)
+	if flip {
+		flipRows(out)
+	}
+	for _, l := range g.labels {
+		g.drawLabel(out, l, float64(s))
+	}
+	return out
+}
+
+// plotHeights returns the height in canvas pixels of the value of each
+// column from first to the right edge, unrounded. As on the canvas, a
+// time-window column without samples repeats the one before it.
+func (g *Graph) plotHeights() (first int, hs []float64) {
+	// Far out-of-range values sit just past the plot edge.
+	top := float64(g.effectiveHeight())
+	y := func(v float64) float64 {
+		h := g.valueYf(v)
+		if math.IsNaN(h) {
+			return 0
+		}
+		return math.Max(-8, math.Min(h, top+8))
+	}
+	if g.window <= 0 {
+		n := min(g.samples.len(), g.width)
+		g.samples.eachLast(n, func(s Sample) {
+			hs = append(hs, y(s.Value))
+		})
+		return g.width - n, hs
+	}
+	if len(g.buckets) == 0 {
+		return g.width, nil
+	}
+	first = g.bucketColumn(&g.buckets[0])
+	next := first
+	for i := range g.buckets {
+		b := &g.buckets[i]
+		for col := g.bucketColumn(b); next < col; next++ {
+			hs = append(hs, hs[len(hs)-1])
+		}
+		hs = append(hs, y(b.mean()))
+		next++
+	}
+	return first, hs
+}
+
+// paintPlot draws the samples in the graph's style: the fill under them,
+// then the line, bar caps or dots in the highlight color, then the min/max
+// bands of a time-window graph. The oldest sample also fills the columns to
+// its left, as on the canvas.
+func (g *Graph) paintPlot(p *painter) {
+	first, hs := g.plotHeights()
+	if len(hs) == 0 {
+		return
+	}
+	fills := make([]color.RGBA, g.width)
+	for c := range fills {
+		if len(g.gradient) > 0 {
+			fills[c] = g.gradientColor(hs[max(c-first, 0)])
+		} else {
+			fills[c] = g.ForegroundColor()
+		}
+	}
+	g.paintSeries(p, 0, first, hs, func(x int) color.RGBA {
+		return fills[min(int(float64(x)/p.s), g.width-1)]
+	})
+
+	if g.window > 0 {
+		hl := g.HighlightColor()
+		for i := range g.buckets {
+			b := &g.buckets[i]
+			c := float64(g.bucketColumn(b))
+			p.rect(c, g.valueYf(b.lo), c+1, g.valueYf(b.hi)+1)
+		}
+		p.paint(func(int) color.RGBA { return hl }, 128)
+	}
+}
+
+// paintSeries draws heights hs of the columns from first on in the graph's
+// style, the fill in fill and the rest in the highlight color. The plot
+// starts at column x0 and ends at the right edge of the painter; columns
+// between x0 and first repeat the oldest height. It adjusts hs in place.
+func (g *Graph) paintSeries(p *painter, x0, first int, hs []float64, fill func(x int) color.RGBA) {
+	width := p.width()
+	lt := float64(max(g.lineThickness, 1))
+	// The canvas draws the line upward from the sample's row.
+	for i := range hs {
+		hs[i] += lt / 2
+	}
+	at := func(c int) float64 {
+		return hs[max(c-first, 0)]
+	}
+	hl := g.HighlightColor()
+	highlight := func(int) color.RGBA { return hl }
+
+	switch g.style {
+	case StyleBars:
+		for c := x0; c < width; c++ {
+			p.rect(float64(c), 0, float64(c+1), at(c))
+		}
+		p.paint(fill, 256)
+		for c := x0; c < width; c++ {
+			p.rect(float64(c), at(c)-lt/2, float64(c+1), at(c)+lt/2)
+		}
+		p.paint(highlight, 256)
+	case StyleDots:
+		r := math.Max(lt, 1.5) / 2
+		for c := x0; c < width; c++ {
+			p.circle(float64(c)+0.5, at(c), r)
+		}
+		p.paint(highlight, 256)
+	default:
+		path := g.plotPath(x0, first, width, hs)
+		if g.style.Fills() {
+			area := append([]point{{float64(x0), 0}}, path...)
+			p.polygon(append(area, point{float64(width), 0})...)
+			p.paint(fill, 256)
+		}
+		p.line(path, lt)
+		p.paint(highlight, 256)
+	}
+}
+
+// plotPath returns the line through the columns from first on, reaching
+// from column x0 to the right edge at width: through the column centres, or
+// along each column's top for StyleStep.
+func (g *Graph) plotPath(x0, first, width int, hs []float64) []point {
+	path := make([]point, 0, 2*len(hs)+2)
+	path = append(path, point{float64(x0), hs[0]})
+	for i, h := range hs {
+		c := float64(first + i)
+		if g.style == StyleStep {
+			path = append(path, point{c, h}, point{c + 1, h})
+		} else {
+			path = append(path, point{c + 0.5, h})
+		}
+	}
+	return append(path, point{float64(width), hs[len(hs)-1]})
+}
+
+// PaintSparkline draws the history of g across rect of dst the way smooth
+// rendering draws the plot: in the graph's style and colors, anti-aliased,
+// one column per sample with the newest at the right edge and the range
+// scaled to the height of rect. Columns without history stay empty. down
+// grows the plot from the top of rect instead of its bottom.
+func (g *Graph) PaintSparkline(dst *image.RGBA, rect image.Rectangle, down bool) {
+	rect = rect.Intersect(dst.Rect)
+	w, h := rect.Dx(), rect.Dy()
+	if w <= 0 || h < 2 {
+		return
+	}
+	_, hs := g.plotHeights()
+	hs = hs[max(len(hs)-w, 0):]
+	if len(hs) == 0 {
+		return
+	}
+	scale := float64(h-1) / float64(max(g.effectiveHeight()-1, 1))
+	for i := range hs {
+		hs[i] = math.Min(math.Max(hs[i]*scale, 0), float64(h-1))
+	}
+	p := newPainter(dst.SubImage(rect).(*image.RGBA), h, 1)
+	p.down = down
+	first := w - len(hs)
+	fg := g.ForegroundColor()
+	g.paintSeries(p, first, first, hs, func(int) color.RGBA { return fg })
+}
+
+// paintMarkers is drawMarkers for smooth rendering: the lines sit on the
+// same rows as on the canvas, so they stay crisp.
+func (g *Graph) paintMarkers(p *painter) {
+	top := g.effectiveHeight() - 1
+	w := float64(g.width)
+	for _, m := range g.markers {
+		if m.Lo >= m.Hi {
+			continue
+		}
+		y0, y1 := math.Max(g.valueYf(m.Lo), 0), math.Min(g.valueYf(m.Hi)+1, float64(top+1))
+		if y0 < y1 {
+			p.rect(0, y0, w, y1)
+			p.paint(func(int) color.RGBA { return m.Color }, markerBandAlpha)
+		}
+	}
+	for _, m := range g.markers {
+		y := g.valueY(m.Value)
+		if y < 0 || y > top {
+			continue
+		}
+		for x := 0; x < g.width; x += 4 {
+			p.rect(float64(x), float64(y), float64(min(x+2, g.width)), float64(y+1))
+		}
+		p.paint(func(int) color.RGBA { return m.Color }, 256)
+	}
+}
+
+// point is a position on the plot in canvas pixels: x from the left edge,
+// y up from the bottom.
+type point struct{ x, y float64 }
+
+// painter rasterizes shapes into the plot area of a device image, its
+// bottom h rows. Shapes are given in canvas pixels and collected until
+// paint blends them in.
+type painter struct {
+	dst  *image.RGBA
+	h    int
+	s    float64 // device pixels per canvas pixel
+	down bool    // y grows down from the top of dst instead
+	z    vector.Rasterizer
+	mask *image.Alpha
+}
+
+func newPainter(dst *image.RGBA, h int, s float64) *painter {
+	w := dst.Rect.Dx()
+	p := &painter{dst: dst, h: h, s: s, mask: image.NewAlpha(image.Rect(0, 0, w, h))}
+	p.z.Reset(w, h)
+	return p
+}
+
+// polygon adds a closed shape. Shapes are wound the same way, so where they
+// overlap the coverage adds up rather than cancelling out.
+func (p *painter) polygon(pts ...point) {
+	if len(pts) < 3 {
+		return
+	}
+	var area float64
+	for i, a := range pts {
+		b := pts[(i+1)%len(pts)]
+		area += a.x*b.y - b.x*a.y
+	}
+	at := func(i int) point { return pts[i] }
+	if area < 0 {
+		at = func(i int) point { return pts[len(pts)-1-i] }
+	}
+	x, y := p.device(at(0))
+	p.z.MoveTo(x, y)
+	for i := 1; i < len(pts); i++ {
+		x, y = p.device(at(i))
+		p.z.LineTo(x, y)
+	}
+	p.z.ClosePath()
+}
+
+func (p *painter) device(pt point) (x, y float32) {
+	if p.down {
+		return float32(pt.x * p.s), float32(pt.y * p.s)
+	}
+	return float32(pt.x * p.s), float32(float64(p.h) - pt.y*p.s)
+}
+
+// width returns the width of the plot area in canvas pixels.
+func (p *painter) width() int {
+	return int(float64(p.dst.Rect.Dx()) / p.s)
+}
+
+func (p *painter) rect(x0, y0, x1, y1 float64) {
+	p.polygon(point{x0, y0}, point{x1, y0}, point{x1, y1}, point{x0, y1})
+}
+
+func (p *painter) circle(x, y, r float64) {
+	const n = 16
+	pts := make([]point, n)
+	for i := range pts {
+		a := 2 * math.Pi * float64(i) / n
+		pts[i] = point{x + r*math.Cos(a), y + r*math.Sin(a)}
+	}
+	p.polygon(pts...)
+}
+
+// line adds a line w canvas pixels wide through pts, with round joins.
+func (p *painter) line(pts []point, w float64) {
+	r := w / 2
+	for i := 1; i < len(pts); i++ {
+		a, b := pts[i-1], pts[i]
+		dx, dy := b.x-a.x, b.y-a.y
+		l := math.Hypot(dx, dy)
+		if l == 0 {
+			continue
+		}
+		nx, ny := -dy/l*r, dx/l*r
+		p.polygon(point{a.x + nx, a.y + ny}, point{b.x + nx, b.y + ny},
+			point{b.x - nx, b.y - ny}, point{a.x - nx, a.y - ny})
+	}
+	for _, pt := range pts {
+		p.circle(pt.x, pt.y, r)
+	}
+}
+
+// paint blends clr of each device column into the shapes added since the
+// last paint, a/256 of the way at full coverage.
+func (p *painter) paint(clr func(x int) color.RGBA, a uint32) {
+	clear(p.mask.Pix)
+	p.z.Draw(p.mask, p.mask.Rect, image.Opaque, image.Point{})
+	off := p.dst.Rect.Dy() - p.h
+	if p.down {
+		off = 0
+	}
+	o := p.dst.Rect.Min
+	for y := 0; y < p.h; y++ {
+		row := p.mask.Pix[y*p.mask.Stride : y*p.mask.Stride+p.mask.Rect.Dx()]
+		for x, m := range row {
+			if m == 0 {
+				continue
+			}
+			c := clr(x)
+			k := uint32(m) * a / 255
+			i := p.dst.PixOffset(o.X+x, o.Y+y+off)
+			px := p.dst.Pix[i : i+4 : i+4]
+			px[0] = uint8((uint32(px[0])*(256-k) + uint32(c.R)*k) >> 8)
+			px[1] = uint8((uint32(px[1])*(256-k) + uint32(c.G)*k) >> 8)
+			px[2] = uint8((uint32(px[2])*(256-k) + uint32(c.B)*k) >> 8)
+			px[3] = uint8((uint32(px[3])*(256-k) + uint32(c.A)*k) >> 8)
+		}
+	}
+	p.z.Reset(p.mask.Rect.Dx(), p.h)
+}
@@ -0,0 +1,52 @@
+package graph
+
+// Style is how the graph draws its samples.
+type Style int
+
+const (
+	StyleArea Style = iota // filled area under a highlight edge (default)
+	StyleLine              // highlight line, no fill
+	StyleStep              // highlight line that jumps at each sample, no fill
+	StyleBars              // a filled bar per column capped with the highlight
+	StyleDots              // a highlight dot per column
+)
+
+// Fills reports whether the style fills below the samples.
+func (s Style) Fills() bool {
+	return s == StyleArea || s == StyleBars
+}
+
+// Connector returns the rows the edge from a sample plotted at prev to the
+// next one at cur covers in the new sample's column (lo..hi) and in the
+// column before it (prevLo..prevHi); an empty range has lo > hi. Area and
+// step draw the whole jump in the new column, line splits it at the midpoint
+// so the edge runs diagonally, and bars and dots are not connected.
+func (s Style) Connector(prev, cur int) (lo, hi, prevLo, prevHi int) {
+	lo, hi, prevLo, prevHi = 0, -1, 0, -1
+	switch s {
+	case StyleArea, StyleStep:
+		lo, hi = min(prev, cur), max(prev, cur)
+	case StyleLine:
+		mid := (prev + cur) / 2
+		switch {
+		case cur > prev:
+			prevLo, prevHi = prev, mid
+			lo, hi = min(mid+1, cur), cur
+		case cur < prev:
+			prevLo, prevHi = mid, prev
+			lo, hi = cur, max(mid-1, cur)
+		}
+	}
+	return lo, hi, prevLo, prevHi
+}
+
+// SetStyle sets how samples are drawn and replots the history.
+func (g *Graph) SetStyle(s Style) {
+	g.style = s
+	g.redraw = true
+}
+
+// Style returns how samples are drawn.
+func (g *Graph) Style() Style {
+	return g.style
+}
@@ -0,0 +1,185 @@
+package graph
+
+import (
+	"math"
+	"time"
+)
+
+// bucket aggregates the samples that fall in one column of a time-window
+// graph.
+type bucket struct {
+	idx    int64 // sample time divided by the bucket duration
+	lo, hi float64
+	sum    float64
+	n      int
+}
+
+func (b *bucket) add(v float64) {
+	if b.n == 0 {
+		b.lo, b.hi = v, v
+	} else {
+		b.lo, b.hi = math.Min(b.lo, v), math.Max(b.hi, v)
+	}
+	b.sum += v
+	b.n++
+}
+
+func (b *bucket) mean() float64 {
+	return b.sum / float64(b.n)
+}
+
+// SetTimeWindow makes the graph span d of wall-clock time, each column
+// aggregating the samples of d/width: the column shows the bucket's mean
+// with its min/max range as a band around it, so spikes shorter than a
+// column stay visible. Zero plots one column per sample. The buckets are
+// rebuilt from the raw history, which grows to span the window.
+func (g *Graph) SetTimeWindow(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	if d == g.window {
+		return
+	}
+	g.window = d
+	g.rebuildBuckets()
+	g.rescale()
+}
+
+// TimeWindow returns the time the graph spans; 0 when it plots one column
+// per sample.
+func (g *Graph) TimeWindow() time.Duration {
+	return g.window
+}
+
+// growHistory doubles the raw history, up to MaxHistory, when it is full
+// before spanning the time window, so the buckets rebuilt after a window
+// change cover the whole window at any poll interval. It never shrinks:
+// going back to a longer window finds its samples still there.
+func (g *Graph) growHistory(t time.Time) {
+	r := &g.samples
+	if g.window <= 0 || r.n < len(r.buf) || len(r.buf) >= MaxHistory {
+		return
+	}
+	if t.Sub(r.oldest().Time) > g.window {
+		return
+	}
+	r.grow(min(2*len(r.buf), MaxHistory))
+}
+
+// bucketDuration is the time one column covers in time-window mode.
+func (g *Graph) bucketDuration() time.Duration {
+	return max(g.window/time.Duration(max(g.width, 1)), 1)
+}
+
+// addToBuckets files s into its column's bucket, dropping buckets that
+// scrolled off the left edge. A sample older than the newest bucket (the
+// clock went back) joins the newest bucket.
+func (g *Graph) addToBuckets(s Sample) {
+	idx := s.Time.UnixNano() / int64(g.bucketDuration())
+	if n := len(g.buckets); n > 0 && idx <= g.buckets[n-1].idx {
+		g.buckets[n-1].add(s.Value)
+		return
+	}
+	b := bucket{idx: idx}
+	b.add(s.Value)
+	g.buckets = append(g.buckets, b)
+	drop := 0
+	for drop < len(g.buckets) && g.buckets[drop].idx <= idx-int64(g.width) {
+		drop++
+	}
+	if drop > 0 {
+		g.buckets = append(g.buckets[:0], g.buckets[drop:]...)
+	}
+}
+
+func (g *Graph) rebuildBuckets() {
+	g.buckets = g.buckets[:0]
+	if g.window <= 0 {
+		return
+	}
+	g.samples.eachLast(g.samples.len(), g.addToBuckets)
+}
+
+// bucketColumn returns the column of b: the newest bucket is on the right
+// edge.
+func (g *Graph) bucketColumn(b *bucket) int {
+	newest := g.buckets[len(g.buckets)-1].idx
+	return g.width - 1 - int(newest-b.idx)
+}
+
+// rebuildBucketYvals fills the pixel cache with the mean of each column from
+// the oldest bucket on; a column without samples repeats the one before it,
+// as replotBuckets draws it.
+func (g *Graph) rebuildBucketYvals() {
+	g.yvals = g.yvals[:0]
+	if len(g.buckets) == 0 {
+		return
+	}
+	next := g.bucketColumn(&g.buckets[0])
+	for i := range g.buckets {
+		b := &g.buckets[i]
+		col := g.bucketColumn(b)
+		for ; next < col; next++ {
+			g.yvals = append(g.yvals, g.yvals[len(g.yvals)-1])
+		}
+		g.yvals = append(g.yvals, g.valueY(b.mean()))
+		next++
+	}
+}
+
+// replotBuckets redraws the canvas of a time-window graph: each column is
+// filled up to its bucket's mean and the min/max band is blended over it in
+// the highlight color. A column without samples (the bucket is shorter than
+// the poll interval) continues the column before it, without a band.
+func (g *Graph) replotBuckets() {
+	g.lvay = -1
+	if len(g.buckets) == 0 {
+		g.clearColumns(0, g.width-1)
+		g.redraw = false
+		g.drawn = false
+		return
+	}
+	first := g.bucketColumn(&g.buckets[0])
+	g.clearColumns(0, first-1)
+	bi := 0
+	for x := first; x < g.width; x++ {
+		if bi < len(g.buckets) && g.bucketColumn(&g.buckets[bi]) == x {
+			b := &g.buckets[bi]
+			bi++
+			g.drawGraph(x, g.valueY(b.mean()), x)
+			g.blendBand(x, g.valueY(b.lo), g.valueY(b.hi))
+			continue
+		}
+		g.drawGraph(x, g.lvay, x)
+	}
+	g.redraw = false
+	g.drawn = true
+}
+
+// blendBand mixes the highlight color half into column x between y0 and y1.
+func (g *Graph) blendBand(x, y0, y1 int) {
+	hl := g.HighlightColor()
+	for y := max(y0, 0); y <= min(y1, g.effectiveHeight()-1); y++ {
+		g.mix(x, y, hl, 128)
+	}
+}
+
+// clearColumns fills columns x0 to x1 with the background color.
+func (g *Graph) clearColumns(x0, x1 int) {
+	bg := g.BackgroundColor()
+	for x := max(x0, 0); x <= x1; x++ {
+		for y := 0; y < g.height; y++ {
+			i := g.img.PixOffset(x, y)
+			copy(g.img.Pix[i:i+4], []uint8{bg.R, bg.G, bg.B, bg.A})
+		}
+	}
+}
+
+// bucketRange returns the extremes of the buckets on the graph.
+func (g *Graph) bucketRange() (lo, hi float64) {
+	lo, hi = math.Inf(1), math.Inf(-1)
+	for i := range g.buckets {
+		lo, hi = math.Min(lo, g.buckets[i].lo), math.Max(hi, g.buckets[i].hi)
+	}
+	return lo, hi
+}
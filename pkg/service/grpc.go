@@ -4,21 +4,28 @@ import (
 	"context"
 	"errors"
 	"io"
+	"sync/atomic"
+	"time"
 
 	"github.com/golang/protobuf/ptypes/empty"
 	"github.com/moeilijk/lhm-streamdeck/pkg/service/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // GRPCClient is an implementation of KV that talks over RPC.
 type GRPCClient struct {
 	Client proto.HWServiceClient
+
+	// legacy is set once the bridge turned out not to know the Snapshot RPC.
+	legacy atomic.Bool
 }
 
 // PollTime rpc call
 func (c *GRPCClient) PollTime() (uint64, error) {
 	resp, err := c.Client.PollTime(context.Background(), &empty.Empty{})
 	if err != nil {
-		return 0, err
+		return 0, fromStatus(err)
 	}
 	return resp.GetPollTime(), nil
 }
@@ -27,7 +34,7 @@ func (c *GRPCClient) PollTime() (uint64, error) {
 func (c *GRPCClient) Sensors() ([]Sensor, error) {
 	stream, err := c.Client.Sensors(context.Background(), &empty.Empty{})
 	if err != nil {
-		return nil, err
+		return nil, fromStatus(err)
 	}
 
 	var sensors []Sensor
@@ -37,7 +44,7 @@ func (c *GRPCClient) Sensors() ([]Sensor, error) {
 			break
 		}
 		if err != nil {
-			return nil, err
+			return nil, fromStatus(err)
 		}
 		sensors = append(sensors, &sensor{s})
 	}
@@ -49,7 +56,7 @@ func (c *GRPCClient) Sensors() ([]Sensor, error) {
 func (c *GRPCClient) ReadingsForSensorID(id string) ([]Reading, error) {
 	stream, err := c.Client.ReadingsForSensorID(context.Background(), &proto.SensorIDRequest{Id: id})
 	if err != nil {
-		return nil, err
+		return nil, fromStatus(err)
 	}
 
 	var readings []Reading
@@ -59,7 +66,7 @@ func (c *GRPCClient) ReadingsForSensorID(id string) ([]Reading, error) {
 			break
 		}
 		if err != nil {
-			return nil, err
+			return nil, fromStatus(err)
 		}
 		readings = append(readings, &reading{r})
 	}
@@ -67,6 +74,83 @@ func (c *GRPCClient) ReadingsForSensorID(id string) ([]Reading, error) {
 	return readings, nil
 }
 
+// Subscribe opens the snapshot stream. It waits for the first snapshot so an
+// older bridge without the RPC is reported as ErrSubscribeUnsupported instead
+// of a silently closed channel.
+func (c *GRPCClient) Subscribe(ctx context.Context, interval time.Duration) (<-chan *Snapshot, error) {
+	stream, err := c.Client.Subscribe(ctx, &proto.SubscribeRequest{IntervalMs: uint32(interval / time.Millisecond)})
+	if err != nil {
+		return nil, err
+	}
+	first, err := stream.Recv()
+	if err != nil {
+		if status.Code(err) == codes.Unimplemented {
+			return nil, ErrSubscribeUnsupported
+		}
+		return nil, err
+	}
+
+	ch := make(chan *Snapshot, 1)
+	go func() {
+		defer close(ch)
+		msg := first
+		for {
+			select {
+			case ch <- snapshotFromProto(msg):
+			case <-ctx.Done():
+				return
+			}
+			msg, err = stream.Recv()
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// Snapshot fetches all sensors and readings in a single call. Bridges without
+// the Snapshot RPC get a LazySnapshot instead, which only asks for the sensors
+// that are read from it.
+func (c *GRPCClient) Snapshot(pollTime uint64) (*Snapshot, error) {
+	if !c.legacy.Load() {
+		msg, err := c.Client.Snapshot(context.Background(), &proto.SnapshotRequest{PollTime: pollTime})
+		if err == nil {
+			return snapshotFromProto(msg), nil
+		}
+		if status.Code(err) != codes.Unimplemented {
+			return nil, fromStatus(err)
+		}
+		c.legacy.Store(true)
+	}
+	return LazySnapshot(c, pollTime), nil
+}
+
+func snapshotFromProto(msg *proto.Snapshot) *Snapshot {
+	if msg.GetError() != "" {
+		err := errors.New(msg.GetError())
+		if msg.GetAuthFailed() {
+			err = AuthError(msg.GetError())
+		}
+		return &Snapshot{PollTime: msg.GetPollTime(), Err: err}
+	}
+	snap := &Snapshot{
+		PollTime: msg.GetPollTime(),
+		Sensors:  make([]Sensor, 0, len(msg.GetSensors())),
+		Readings: make(map[string][]Reading, len(msg.GetSensors())),
+	}
+	for _, sr := range msg.GetSensors() {
+		s := &sensor{sr.GetSensor()}
+		snap.Sensors = append(snap.Sensors, s)
+		rs := make([]Reading, 0, len(sr.GetReadings()))
+		for _, r := range sr.GetReadings() {
+			rs = append(rs, &reading{r})
+		}
+		snap.Readings[s.ID()] = rs
+	}
+	return snap
+}
+
 // GRPCServer is the gRPC server that GRPCClient talks to.
 type GRPCServer struct {
 	// This is the real implementation
@@ -77,14 +161,14 @@ type GRPCServer struct {
 // PollTime gRPC wrapper
 func (s *GRPCServer) PollTime(ctx context.Context, _ *empty.Empty) (*proto.PollTimeReply, error) {
 	v, err := s.Impl.PollTime()
-	return &proto.PollTimeReply{PollTime: v}, err
+	return &proto.PollTimeReply{PollTime: v}, toStatus(err)
 }
 
 // Sensors gRPC wrapper
 func (s *GRPCServer) Sensors(_ *empty.Empty, stream proto.HWService_SensorsServer) error {
 	sensors, err := s.Impl.Sensors()
 	if err != nil {
-		return err
+		return toStatus(err)
 	}
 
 	for _, sensor := range sensors {
@@ -103,24 +187,106 @@ func (s *GRPCServer) Sensors(_ *empty.Empty, stream proto.HWService_SensorsServe
 func (s *GRPCServer) ReadingsForSensorID(req *proto.SensorIDRequest, stream proto.HWService_ReadingsForSensorIDServer) error {
 	readings, err := s.Impl.ReadingsForSensorID(req.GetId())
 	if err != nil {
-		return err
+		return toStatus(err)
 	}
 
 	for _, reading := range readings {
-		if err := stream.Send(&proto.Reading{
-			ID:       reading.ID(),
-			TypeI:    reading.TypeI(),
-			Type:     reading.Type(),
-			Label:    reading.Label(),
-			Unit:     reading.Unit(),
-			Value:    reading.Value(),
-			ValueMin: reading.ValueMin(),
-			ValueMax: reading.ValueMax(),
-			ValueAvg: reading.ValueAvg(),
-		}); err != nil {
+		if err := stream.Send(readingToProto(reading)); err != nil {
 			return err
 		}
 	}
 
 	return nil
 }
+
+// Snapshot gRPC wrapper
+func (s *GRPCServer) Snapshot(ctx context.Context, req *proto.SnapshotRequest) (*proto.Snapshot, error) {
+	snap, err := s.Impl.Snapshot(req.GetPollTime())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return snapshotToProto(snap), nil
+}
+
+// Subscribe gRPC wrapper
+func (s *GRPCServer) Subscribe(req *proto.SubscribeRequest, stream proto.HWService_SubscribeServer) error {
+	sub, ok := s.Impl.(Subscriber)
+	if !ok {
+		return status.Error(codes.Unimplemented, "service does not push snapshots")
+	}
+	ch, err := sub.Subscribe(stream.Context(), time.Duration(req.GetIntervalMs())*time.Millisecond)
+	if err != nil {
+		return err
+	}
+	for snap := range ch {
+		if err := stream.Send(snapshotToProto(snap)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readingToProto(r Reading) *proto.Reading {
+	msg := &proto.Reading{
+		ID:       r.ID(),
+		TypeI:    r.TypeI(),
+		Type:     r.Type(),
+		Label:    r.Label(),
+		Unit:     r.Unit(),
+		Value:    r.Value(),
+		ValueMin: r.ValueMin(),
+		ValueMax: r.ValueMax(),
+		ValueAvg: r.ValueAvg(),
+	}
+	if sr, ok := r.(StatsReading); ok {
+		for _, st := range sr.Stats() {
+			msg.Stats = append(msg.Stats, &proto.WindowStats{
+				WindowMs: st.Window.Milliseconds(),
+				Min:      st.Min,
+				Max:      st.Max,
+				Mean:     st.Mean,
+				Count:    uint32(st.Count),
+			})
+		}
+	}
+	return msg
+}
+
+func snapshotToProto(snap *Snapshot) *proto.Snapshot {
+	msg := &proto.Snapshot{PollTime: snap.PollTime}
+	if snap.Err != nil {
+		msg.Error = snap.Err.Error()
+		msg.AuthFailed = errors.Is(snap.Err, ErrAuthFailed)
+		return msg
+	}
+	msg.Sensors = make([]*proto.SensorReadings, 0, len(snap.Sensors))
+	for _, s := range snap.Sensors {
+		rs := snap.Readings[s.ID()]
+		sr := &proto.SensorReadings{
+			Sensor:   &proto.Sensor{ID: s.ID(), Name: s.Name()},
+			Readings: make([]*proto.Reading, 0, len(rs)),
+		}
+		for _, r := range rs {
+			sr.Readings = append(sr.Readings, readingToProto(r))
+		}
+		msg.Sensors = append(msg.Sensors, sr)
+	}
+	return msg
+}
+
+// toStatus marks credential errors as Unauthenticated so the client can tell
+// them apart from an unreachable source.
+func toStatus(err error) error {
+	if err != nil && errors.Is(err, ErrAuthFailed) {
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+	return err
+}
+
+// fromStatus is the client side of toStatus.
+func fromStatus(err error) error {
+	if st, ok := status.FromError(err); ok && st.Code() == codes.Unauthenticated {
+		return AuthError(st.Message())
+	}
+	return err
+}
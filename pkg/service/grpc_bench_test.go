@@ -0,0 +1,88 @@
+package hwsensorsservice
+
+import (
+	"fmt"
+	"testing"
+)
+
+const (
+	benchTiles    = 50
+	benchSensors  = 10
+	benchReadings = 25
+)
+
+// benchTile is the sensor/reading pair one tile displays.
+type benchTile struct {
+	sensorID  string
+	readingID int32
+}
+
+func benchTileSet() []benchTile {
+	tiles := make([]benchTile, benchTiles)
+	for i := range tiles {
+		tiles[i] = benchTile{
+			sensorID:  fmt.Sprintf("/sensor/%d", i%benchSensors),
+			readingID: int32(i % benchReadings),
+		}
+	}
+	return tiles
+}
+
+func findReading(rs []Reading, id int32) Reading {
+	for _, r := range rs {
+		if r.ID() == id {
+			return r
+		}
+	}
+	return nil
+}
+
+// BenchmarkTick50Tiles measures one tick of 50 tiles over gRPC: the old path
+// makes a ReadingsForSensorID call per tile, the new one a single Snapshot
+// call that every tile reads from.
+func BenchmarkTick50Tiles(b *testing.B) {
+	impl := newTableService(benchSensors, benchReadings)
+	client := dialBufconn(b, impl)
+	tiles := benchTileSet()
+
+	b.Run("ReadingsForSensorID", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := client.PollTime(); err != nil {
+				b.Fatal(err)
+			}
+			for _, tile := range tiles {
+				rs, err := client.ReadingsForSensorID(tile.sensorID)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if findReading(rs, tile.readingID) == nil {
+					b.Fatalf("reading %d missing", tile.readingID)
+				}
+			}
+		}
+	})
+
+	b.Run("Snapshot", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			pollTime, err := client.PollTime()
+			if err != nil {
+				b.Fatal(err)
+			}
+			snap, err := client.Snapshot(pollTime)
+			if err != nil {
+				b.Fatal(err)
+			}
+			for _, tile := range tiles {
+				rs, err := snap.ReadingsForSensorID(tile.sensorID)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if findReading(rs, tile.readingID) == nil {
+					b.Fatalf("reading %d missing", tile.readingID)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,359 @@
+package hwsensorsservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/moeilijk/lhm-streamdeck/pkg/service/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type fakeSensor struct{ id, name string }
+
+func (s fakeSensor) ID() string   { return s.id }
+func (s fakeSensor) Name() string { return s.name }
+
+type fakeReading struct {
+	id    int32
+	label string
+	value float64
+}
+
+func (r fakeReading) ID() int32                { return r.id }
+func (r fakeReading) TypeI() int32             { return int32(ReadingTypeTemp) }
+func (r fakeReading) Type() string             { return "Temperature" }
+func (r fakeReading) Label() string            { return r.label }
+func (r fakeReading) Unit() string             { return "°C" }
+func (r fakeReading) Value() float64           { return r.value }
+func (r fakeReading) ValueNormalized() float64 { return r.value }
+func (r fakeReading) ValueMin() float64        { return r.value }
+func (r fakeReading) ValueMax() float64        { return r.value }
+func (r fakeReading) ValueAvg() float64        { return r.value }
+
+// pollingService only implements HardwareService, like a bridge built before
+// the Subscribe RPC existed.
+type pollingService struct{}
+
+func (pollingService) PollTime() (uint64, error)                        { return 1, nil }
+func (pollingService) Sensors() ([]Sensor, error)                       { return nil, nil }
+func (pollingService) ReadingsForSensorID(id string) ([]Reading, error) { return nil, nil }
+func (s pollingService) Snapshot(pollTime uint64) (*Snapshot, error) {
+	return &Snapshot{PollTime: pollTime}, nil
+}
+
+type pushingService struct {
+	pollingService
+	snaps []*Snapshot
+}
+
+func (s pushingService) Subscribe(ctx context.Context, interval time.Duration) (<-chan *Snapshot, error) {
+	ch := make(chan *Snapshot, len(s.snaps))
+	for _, snap := range s.snaps {
+		ch <- snap
+	}
+	close(ch)
+	return ch, nil
+}
+
+// tableService serves a fixed set of sensors and counts the calls it gets.
+type tableService struct {
+	pollTime uint64
+	sensors  []Sensor
+	readings map[string][]Reading
+
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func newTableService(sensors, readingsPerSensor int) *tableService {
+	s := &tableService{pollTime: 42, readings: make(map[string][]Reading), calls: make(map[string]int)}
+	for i := 0; i < sensors; i++ {
+		id := fmt.Sprintf("/sensor/%d", i)
+		s.sensors = append(s.sensors, fakeSensor{id: id, name: fmt.Sprintf("Sensor %d", i)})
+		for j := 0; j < readingsPerSensor; j++ {
+			s.readings[id] = append(s.readings[id], fakeReading{id: int32(j), label: fmt.Sprintf("Reading %d", j), value: float64(i*100 + j)})
+		}
+	}
+	return s
+}
+
+func (s *tableService) count(method string) {
+	s.mu.Lock()
+	s.calls[method]++
+	s.mu.Unlock()
+}
+
+func (s *tableService) PollTime() (uint64, error) {
+	s.count("PollTime")
+	return s.pollTime, nil
+}
+
+func (s *tableService) Sensors() ([]Sensor, error) {
+	s.count("Sensors")
+	return s.sensors, nil
+}
+
+func (s *tableService) ReadingsForSensorID(id string) ([]Reading, error) {
+	s.count("ReadingsForSensorID")
+	rs, ok := s.readings[id]
+	if !ok {
+		return nil, fmt.Errorf("sensor %s not found", id)
+	}
+	return rs, nil
+}
+
+func (s *tableService) Snapshot(pollTime uint64) (*Snapshot, error) {
+	s.count("Snapshot")
+	return &Snapshot{PollTime: s.pollTime, Sensors: s.sensors, Readings: s.readings}, nil
+}
+
+// oldBridgeServer only serves the RPCs a bridge had before Snapshot existed.
+type oldBridgeServer struct {
+	proto.UnimplementedHWServiceServer
+	s *GRPCServer
+}
+
+func (o oldBridgeServer) PollTime(ctx context.Context, e *empty.Empty) (*proto.PollTimeReply, error) {
+	return o.s.PollTime(ctx, e)
+}
+
+func (o oldBridgeServer) Sensors(e *empty.Empty, stream proto.HWService_SensorsServer) error {
+	return o.s.Sensors(e, stream)
+}
+
+func (o oldBridgeServer) ReadingsForSensorID(req *proto.SensorIDRequest, stream proto.HWService_ReadingsForSensorIDServer) error {
+	return o.s.ReadingsForSensorID(req, stream)
+}
+
+func dialBufconn(tb testing.TB, impl HardwareService) *GRPCClient {
+	tb.Helper()
+	return dialServer(tb, &GRPCServer{Impl: impl})
+}
+
+func dialServer(tb testing.TB, server proto.HWServiceServer) *GRPCClient {
+	tb.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	proto.RegisterHWServiceServer(srv, server)
+	go srv.Serve(lis)
+	tb.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		tb.Fatalf("dial: %v", err)
+	}
+	tb.Cleanup(func() { conn.Close() })
+	return &GRPCClient{Client: proto.NewHWServiceClient(conn)}
+}
+
+func TestGRPCSubscribeStreamsSnapshots(t *testing.T) {
+	impl := pushingService{snaps: []*Snapshot{
+		{
+			PollTime: 42,
+			Sensors:  []Sensor{fakeSensor{id: "/amdcpu/0", name: "CPU"}},
+			Readings: map[string][]Reading{
+				"/amdcpu/0": {fakeReading{id: 7, label: "CPU Total", value: 55.5}},
+			},
+		},
+		{PollTime: 43, Err: errors.New("LHM unreachable")},
+	}}
+	client := dialBufconn(t, impl)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, err := client.Subscribe(ctx, 500*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	first := <-ch
+	if first == nil || first.PollTime != 42 || first.Err != nil {
+		t.Fatalf("unexpected first snapshot: %+v", first)
+	}
+	if len(first.Sensors) != 1 || first.Sensors[0].ID() != "/amdcpu/0" || first.Sensors[0].Name() != "CPU" {
+		t.Fatalf("unexpected sensors: %+v", first.Sensors)
+	}
+	rs, err := first.ReadingsForSensorID("/amdcpu/0")
+	if err != nil || len(rs) != 1 {
+		t.Fatalf("ReadingsForSensorID = %v, %v", rs, err)
+	}
+	if rs[0].ID() != 7 || rs[0].Label() != "CPU Total" || rs[0].Value() != 55.5 || rs[0].Unit() != "°C" {
+		t.Fatalf("unexpected reading: id=%d label=%q value=%v unit=%q", rs[0].ID(), rs[0].Label(), rs[0].Value(), rs[0].Unit())
+	}
+
+	second := <-ch
+	if second == nil || second.Err == nil || second.Err.Error() != "LHM unreachable" {
+		t.Fatalf("expected error snapshot, got %+v", second)
+	}
+
+	if _, ok := <-ch; ok {
+		t.Fatal("expected channel to close when the stream ends")
+	}
+}
+
+func TestGRPCSubscribeUnsupportedByOldBridge(t *testing.T) {
+	client := dialBufconn(t, pollingService{})
+	if _, err := client.Subscribe(context.Background(), time.Second); !errors.Is(err, ErrSubscribeUnsupported) {
+		t.Fatalf("Subscribe error = %v, want ErrSubscribeUnsupported", err)
+	}
+}
+
+func TestGRPCSnapshotReturnsAllReadings(t *testing.T) {
+	impl := newTableService(3, 4)
+	client := dialBufconn(t, impl)
+
+	snap, err := client.Snapshot(impl.pollTime)
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	if snap.PollTime != 42 || len(snap.Sensors) != 3 {
+		t.Fatalf("got poll time %d with %d sensors, want 42 with 3", snap.PollTime, len(snap.Sensors))
+	}
+	rs, err := snap.ReadingsForSensorID("/sensor/2")
+	if err != nil || len(rs) != 4 || rs[3].Value() != 203 {
+		t.Fatalf("ReadingsForSensorID = %v, %v", rs, err)
+	}
+	if impl.calls["Snapshot"] != 1 || impl.calls["ReadingsForSensorID"] != 0 {
+		t.Fatalf("unexpected calls: %v", impl.calls)
+	}
+}
+
+type statsReading struct {
+	fakeReading
+	stats []WindowStats
+}
+
+func (r statsReading) Stats() []WindowStats { return r.stats }
+
+func TestGRPCReadingStatsSurviveTheBridge(t *testing.T) {
+	impl := newTableService(1, 2)
+	five := WindowStats{Window: 5 * time.Minute, Min: 40, Max: 72.5, Mean: 51.25, Count: 300}
+	impl.readings["/sensor/0"][1] = statsReading{
+		fakeReading: impl.readings["/sensor/0"][1].(fakeReading),
+		stats:       []WindowStats{{Window: time.Minute, Min: 50, Max: 60, Mean: 55, Count: 60}, five},
+	}
+	client := dialBufconn(t, impl)
+
+	snap, err := client.Snapshot(impl.pollTime)
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	rs, _ := snap.ReadingsForSensorID("/sensor/0")
+	if got, ok := ReadingStats(rs[1], 5*time.Minute); !ok || got != five {
+		t.Fatalf("5m stats = %+v, %v, want %+v", got, ok, five)
+	}
+	if _, ok := ReadingStats(rs[1], 15*time.Minute); ok {
+		t.Fatal("untracked window reported stats")
+	}
+	if got := rs[0].(StatsReading).Stats(); got != nil {
+		t.Fatalf("reading without stats got %+v", got)
+	}
+}
+
+func TestGRPCSnapshotFallsBackForOldBridge(t *testing.T) {
+	impl := newTableService(3, 4)
+	client := dialServer(t, oldBridgeServer{s: &GRPCServer{Impl: impl}})
+
+	for i := 0; i < 2; i++ {
+		snap, err := client.Snapshot(7)
+		if err != nil {
+			t.Fatalf("Snapshot: %v", err)
+		}
+		if snap.PollTime != 7 || !snap.Lazy() {
+			t.Fatalf("got poll time %d, lazy %v; want 7, lazy", snap.PollTime, snap.Lazy())
+		}
+		// Two tiles on the same sensor share one call.
+		for j := 0; j < 2; j++ {
+			rs, err := snap.ReadingsForSensorID("/sensor/1")
+			if err != nil || len(rs) != 4 {
+				t.Fatalf("ReadingsForSensorID = %d readings, %v; want 4", len(rs), err)
+			}
+		}
+		if _, err := snap.ReadingsForSensorID("/missing"); err == nil {
+			t.Fatal("expected an error for an unknown sensor")
+		}
+	}
+	if !client.legacy.Load() {
+		t.Fatal("expected the client to remember the bridge lacks Snapshot")
+	}
+	if impl.calls["Sensors"] != 0 {
+		t.Fatalf("Sensors called %d times, want 0", impl.calls["Sensors"])
+	}
+	// One call per poll for the sensor in use plus the failed lookups.
+	if impl.calls["ReadingsForSensorID"] != 4 {
+		t.Fatalf("ReadingsForSensorID called %d times, want 4", impl.calls["ReadingsForSensorID"])
+	}
+}
+
+// lockedOutService rejects every call the way a source with wrong
+// credentials does.
+type lockedOutService struct{ pollingService }
+
+func (lockedOutService) PollTime() (uint64, error) {
+	return 0, AuthError("request LHM data: authentication failed: status 401 Unauthorized")
+}
+
+func (s lockedOutService) Snapshot(pollTime uint64) (*Snapshot, error) {
+	_, err := s.PollTime()
+	return nil, err
+}
+
+func (s lockedOutService) Subscribe(ctx context.Context, interval time.Duration) (<-chan *Snapshot, error) {
+	_, err := s.PollTime()
+	ch := make(chan *Snapshot, 1)
+	ch <- &Snapshot{Err: err}
+	close(ch)
+	return ch, nil
+}
+
+func TestGRPCAuthErrorsSurviveTheBridge(t *testing.T) {
+	client := dialBufconn(t, lockedOutService{})
+
+	_, err := client.PollTime()
+	if !errors.Is(err, ErrAuthFailed) {
+		t.Fatalf("PollTime error = %v, want ErrAuthFailed", err)
+	}
+	if err.Error() != "request LHM data: authentication failed: status 401 Unauthorized" {
+		t.Fatalf("PollTime error message = %q", err.Error())
+	}
+	if _, err := client.Snapshot(1); !errors.Is(err, ErrAuthFailed) {
+		t.Fatalf("Snapshot error = %v, want ErrAuthFailed", err)
+	}
+
+	ch, err := client.Subscribe(context.Background(), time.Second)
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	if snap := <-ch; snap == nil || !errors.Is(snap.Err, ErrAuthFailed) {
+		t.Fatalf("pushed snapshot = %+v, want ErrAuthFailed", snap)
+	}
+
+	if _, err := dialBufconn(t, newTableService(1, 1)).ReadingsForSensorID("/missing"); err == nil || errors.Is(err, ErrAuthFailed) {
+		t.Fatal("ordinary errors must not be reported as auth failures")
+	}
+}
+
+func TestBroadcasterKeepsNewestSnapshot(t *testing.T) {
+	var b Broadcaster
+	ch := b.Add()
+	b.Publish(&Snapshot{PollTime: 1})
+	b.Publish(&Snapshot{PollTime: 2})
+	if got := <-ch; got.PollTime != 2 {
+		t.Fatalf("got poll time %d, want 2", got.PollTime)
+	}
+	b.Remove(ch)
+	if _, ok := <-ch; ok {
+		t.Fatal("expected channel to be closed after Remove")
+	}
+	b.Publish(&Snapshot{PollTime: 3}) // must not panic on the removed channel
+}
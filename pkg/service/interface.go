@@ -2,12 +2,14 @@ package hwsensorsservice
 
 import (
 	"context"
-	"strings"
+	"errors"
+	"time"
 
 	"google.golang.org/grpc"
 
 	"github.com/hashicorp/go-plugin"
 	"github.com/moeilijk/lhm-streamdeck/pkg/service/proto"
+	"github.com/moeilijk/lhm-streamdeck/pkg/units"
 )
 
 // Handshake is a common handshake that is shared by plugin and host.
@@ -28,8 +30,25 @@ type HardwareService interface {
 	PollTime() (uint64, error)
 	Sensors() ([]Sensor, error)
 	ReadingsForSensorID(id string) ([]Reading, error)
+	// Snapshot returns every sensor with its readings from a poll no older
+	// than pollTime.
+	Snapshot(pollTime uint64) (*Snapshot, error)
 }
 
+// ErrAuthFailed is matched (with errors.Is) by errors caused by the data
+// source rejecting our credentials, as opposed to it being unreachable.
+var ErrAuthFailed = errors.New("authentication failed")
+
+// AuthError reports a rejected request; msg carries the source's details.
+func AuthError(msg string) error {
+	return authError(msg)
+}
+
+type authError string
+
+func (e authError) Error() string        { return string(e) }
+func (e authError) Is(target error) bool { return target == ErrAuthFailed }
+
 // HardwareServicePlugin is the implementation of plugin.GRPCPlugin so we can serve/consume this.
 type HardwareServicePlugin struct {
 	// GRPCPlugin must still implement the Plugin interface
@@ -78,10 +97,68 @@ const (
 	ReadingTypeUsage
 	// ReadingTypeOther other
 	ReadingTypeOther
+	// ReadingTypeThroughput data rate, e.g. KB/s
+	ReadingTypeThroughput
+	// ReadingTypeData data size in GB
+	ReadingTypeData
+	// ReadingTypeSmallData data size in MB
+	ReadingTypeSmallData
+	// ReadingTypeEnergy energy, e.g. mWh
+	ReadingTypeEnergy
+	// ReadingTypeFlow liquid flow in L/h
+	ReadingTypeFlow
+	// ReadingTypeNoise noise in dBA
+	ReadingTypeNoise
+	// ReadingTypeFactor dimensionless factor, e.g. write amplification
+	ReadingTypeFactor
+	// ReadingTypeFrequency frequency in Hz
+	ReadingTypeFrequency
+	// ReadingTypeTimeSpan duration, e.g. battery runtime
+	ReadingTypeTimeSpan
+	// ReadingTypeTiming short timings in ns
+	ReadingTypeTiming
+	// ReadingTypeConductivity conductivity in µS/cm
+	ReadingTypeConductivity
+	// ReadingTypeHumidity relative humidity in %
+	ReadingTypeHumidity
+	// ReadingTypeControl fan/pump control duty in %
+	ReadingTypeControl
+	// ReadingTypeLevel fill or charge level in %
+	ReadingTypeLevel
 )
 
+var readingTypeNames = [...]string{
+	"None", "Temp", "Volt", "Fan", "Current", "Power", "Clock", "Usage", "Other",
+	"Throughput", "Data", "SmallData", "Energy", "Flow", "Noise", "Factor",
+	"Frequency", "TimeSpan", "Timing", "Conductivity", "Humidity", "Control", "Level",
+}
+
+// String returns the short name saved in threshold filters. Types this build
+// does not know, e.g. from a newer bridge, are "Other".
 func (t ReadingType) String() string {
-	return [...]string{"None", "Temp", "Volt", "Fan", "Current", "Power", "Clock", "Usage", "Other"}[t]
+	if t < 0 || int(t) >= len(readingTypeNames) {
+		return "Other"
+	}
+	return readingTypeNames[t]
+}
+
+// Legacy returns the type t was reported as before the taxonomy was extended
+// past ReadingTypeOther: Control and Level were Usage, the rest Other.
+func (t ReadingType) Legacy() ReadingType {
+	switch {
+	case t == ReadingTypeControl || t == ReadingTypeLevel:
+		return ReadingTypeUsage
+	case t > ReadingTypeOther || t < 0:
+		return ReadingTypeOther
+	}
+	return t
+}
+
+// Matches reports whether a saved type filter ("" = all types) applies to t.
+// Filters saved before the taxonomy was extended keep matching the readings
+// they matched then, so "Usage" still covers Control and Level.
+func (t ReadingType) Matches(filter string) bool {
+	return filter == "" || filter == t.String() || filter == t.Legacy().String()
 }
 
 // Reading is the common hardware interface for a sensor's reading
@@ -154,23 +231,38 @@ func (r reading) ValueAvg() float64 {
 	return r.Reading.GetValueAvg()
 }
 
-// NormalizeToBytes converts a value with a data size unit (KB, MB, GB, TB) to bytes.
-// This ensures consistent graph scaling when units change dynamically.
-func NormalizeToBytes(value float64, unit string) float64 {
-	unitLower := strings.ToLower(unit)
+func (r reading) Stats() []WindowStats {
+	msgs := r.Reading.GetStats()
+	if len(msgs) == 0 {
+		return nil
+	}
+	out := make([]WindowStats, 0, len(msgs))
+	for _, m := range msgs {
+		out = append(out, WindowStats{
+			Window: time.Duration(m.GetWindowMs()) * time.Millisecond,
+			Min:    m.GetMin(),
+			Max:    m.GetMax(),
+			Mean:   m.GetMean(),
+			Count:  int(m.GetCount()),
+		})
+	}
+	return out
+}
 
-	switch {
-	case strings.HasPrefix(unitLower, "tb") || strings.HasPrefix(unitLower, "tib"):
-		return value * 1024 * 1024 * 1024 * 1024
-	case strings.HasPrefix(unitLower, "gb") || strings.HasPrefix(unitLower, "gib"):
-		return value * 1024 * 1024 * 1024
-	case strings.HasPrefix(unitLower, "mb") || strings.HasPrefix(unitLower, "mib"):
-		return value * 1024 * 1024
-	case strings.HasPrefix(unitLower, "kb") || strings.HasPrefix(unitLower, "kib"):
-		return value * 1024
-	case strings.HasPrefix(unitLower, "b/") || unitLower == "b":
-		return value
+// NormalizeToBytes converts a data size or rate (KB, MiB/s, Mbit/s, ...) to
+// bytes, or bytes per second. Other values are returned unchanged. This
+// ensures consistent graph scaling when units change dynamically.
+func NormalizeToBytes(value float64, unit string) float64 {
+	u := units.Parse(unit)
+	base := "B"
+	switch u.Dim {
+	case units.DataSize:
+	case units.DataRate:
+		base = "B/s"
 	default:
 		return value
 	}
+	to, _ := units.Lookup(base)
+	v, _ := units.Convert(value, u, to)
+	return v
 }
@@ -0,0 +1,39 @@
+package hwsensorsservice
+
+import "testing"
+
+func TestReadingTypeSavedFiltersKeepMatching(t *testing.T) {
+	for _, tc := range []struct {
+		typ     ReadingType
+		filter  string
+		matches bool
+	}{
+		{ReadingTypeTemp, "Temp", true},
+		{ReadingTypeTemp, "", true},
+		{ReadingTypeTemp, "Usage", false},
+		{ReadingTypeControl, "Control", true},
+		{ReadingTypeControl, "Usage", true}, // saved before Control was split out
+		{ReadingTypeLevel, "Usage", true},
+		{ReadingTypeUsage, "Control", false},
+		{ReadingTypeThroughput, "Throughput", true},
+		{ReadingTypeThroughput, "Other", true},
+		{ReadingTypeThroughput, "Data", false},
+		{ReadingTypeOther, "Throughput", false},
+		{ReadingType(99), "Other", true}, // from a newer bridge
+	} {
+		if got := tc.typ.Matches(tc.filter); got != tc.matches {
+			t.Errorf("%v.Matches(%q) = %v, want %v", tc.typ, tc.filter, got, tc.matches)
+		}
+	}
+}
+
+func TestReadingTypeNamesAreUnique(t *testing.T) {
+	seen := make(map[string]ReadingType)
+	for typ := ReadingTypeNone; typ <= ReadingTypeLevel; typ++ {
+		name := typ.String()
+		if prev, ok := seen[name]; ok {
+			t.Fatalf("%d and %d are both named %q", prev, typ, name)
+		}
+		seen[name] = typ
+	}
+}
@@ -26,6 +26,8 @@ type HWServiceClient interface {
 	PollTime(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*PollTimeReply, error)
 	Sensors(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (HWService_SensorsClient, error)
 	ReadingsForSensorID(ctx context.Context, in *SensorIDRequest, opts ...grpc.CallOption) (HWService_ReadingsForSensorIDClient, error)
+	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (HWService_SubscribeClient, error)
+	Snapshot(ctx context.Context, in *SnapshotRequest, opts ...grpc.CallOption) (*Snapshot, error)
 }
 
 type hWServiceClient struct {
@@ -109,6 +111,47 @@ func (x *hWServiceReadingsForSensorIDClient) Recv() (*Reading, error) {
 	return m, nil
 }
 
+func (c *hWServiceClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (HWService_SubscribeClient, error) {
+	stream, err := c.cc.NewStream(ctx, &HWService_ServiceDesc.Streams[2], "/proto.HWService/Subscribe", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &hWServiceSubscribeClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type HWService_SubscribeClient interface {
+	Recv() (*Snapshot, error)
+	grpc.ClientStream
+}
+
+type hWServiceSubscribeClient struct {
+	grpc.ClientStream
+}
+
+func (x *hWServiceSubscribeClient) Recv() (*Snapshot, error) {
+	m := new(Snapshot)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *hWServiceClient) Snapshot(ctx context.Context, in *SnapshotRequest, opts ...grpc.CallOption) (*Snapshot, error) {
+	out := new(Snapshot)
+	err := c.cc.Invoke(ctx, "/proto.HWService/Snapshot", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // HWServiceServer is the server API for HWService service.
 // All implementations must embed UnimplementedHWServiceServer
 // for forward compatibility
@@ -116,6 +159,8 @@ type HWServiceServer interface {
 	PollTime(context.Context, *emptypb.Empty) (*PollTimeReply, error)
 	Sensors(*emptypb.Empty, HWService_SensorsServer) error
 	ReadingsForSensorID(*SensorIDRequest, HWService_ReadingsForSensorIDServer) error
+	Subscribe(*SubscribeRequest, HWService_SubscribeServer) error
+	Snapshot(context.Context, *SnapshotRequest) (*Snapshot, error)
 	mustEmbedUnimplementedHWServiceServer()
 }
 
@@ -132,6 +177,12 @@ func (UnimplementedHWServiceServer) Sensors(*emptypb.Empty, HWService_SensorsSer
 func (UnimplementedHWServiceServer) ReadingsForSensorID(*SensorIDRequest, HWService_ReadingsForSensorIDServer) error {
 	return status.Errorf(codes.Unimplemented, "method ReadingsForSensorID not implemented")
 }
+func (UnimplementedHWServiceServer) Subscribe(*SubscribeRequest, HWService_SubscribeServer) error {
+	return status.Errorf(codes.Unimplemented, "method Subscribe not implemented")
+}
+func (UnimplementedHWServiceServer) Snapshot(context.Context, *SnapshotRequest) (*Snapshot, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Snapshot not implemented")
+}
 func (UnimplementedHWServiceServer) mustEmbedUnimplementedHWServiceServer() {}
 
 // UnsafeHWServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -205,6 +256,45 @@ func (x *hWServiceReadingsForSensorIDServer) Send(m *Reading) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _HWService_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(HWServiceServer).Subscribe(m, &hWServiceSubscribeServer{stream})
+}
+
+type HWService_SubscribeServer interface {
+	Send(*Snapshot) error
+	grpc.ServerStream
+}
+
+type hWServiceSubscribeServer struct {
+	grpc.ServerStream
+}
+
+func (x *hWServiceSubscribeServer) Send(m *Snapshot) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _HWService_Snapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HWServiceServer).Snapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.HWService/Snapshot",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HWServiceServer).Snapshot(ctx, req.(*SnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // HWService_ServiceDesc is the grpc.ServiceDesc for HWService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -216,6 +306,10 @@ var HWService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "PollTime",
 			Handler:    _HWService_PollTime_Handler,
 		},
+		{
+			MethodName: "Snapshot",
+			Handler:    _HWService_Snapshot_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -228,6 +322,11 @@ var HWService_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _HWService_ReadingsForSensorID_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "Subscribe",
+			Handler:       _HWService_Subscribe_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "pkg/service/proto/hwservice.proto",
 }
@@ -0,0 +1,125 @@
+package hwsensorsservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Snapshot is one complete poll of a hardware service: every sensor with its
+// readings, tagged with the poll time that produced them. Snapshots are shared
+// between goroutines and must not be modified once published.
+type Snapshot struct {
+	PollTime uint64
+	Sensors  []Sensor
+	Readings map[string][]Reading // keyed by sensor ID
+	// Err is set when the poll failed; Sensors and Readings are empty then.
+	Err error
+
+	// fetch loads a sensor's readings on first use in a snapshot built by
+	// LazySnapshot; nil for complete snapshots.
+	fetch  func(id string) ([]Reading, error)
+	mu     sync.Mutex
+	loaded map[string][]Reading
+}
+
+// LazySnapshot returns a snapshot for bridges that predate the Snapshot RPC.
+// It carries no sensors; the readings of a sensor are fetched through hw the
+// first time ReadingsForSensorID asks for them and then shared for the rest of
+// the poll, so a tick costs one call per sensor actually displayed.
+func LazySnapshot(hw HardwareService, pollTime uint64) *Snapshot {
+	return &Snapshot{PollTime: pollTime, fetch: hw.ReadingsForSensorID}
+}
+
+// Lazy reports whether s was built by LazySnapshot, so Sensors and Readings
+// do not hold the whole poll.
+func (s *Snapshot) Lazy() bool {
+	return s.fetch != nil
+}
+
+// ReadingsForSensorID returns the readings of a single sensor.
+func (s *Snapshot) ReadingsForSensorID(id string) ([]Reading, error) {
+	if rs, ok := s.Readings[id]; ok {
+		return rs, nil
+	}
+	if s.fetch == nil {
+		return nil, fmt.Errorf("sensor %s not found", id)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rs, ok := s.loaded[id]; ok {
+		return rs, nil
+	}
+	rs, err := s.fetch(id)
+	if err != nil {
+		return nil, err
+	}
+	if s.loaded == nil {
+		s.loaded = make(map[string][]Reading)
+	}
+	s.loaded[id] = rs
+	return rs, nil
+}
+
+// Subscriber is implemented by services that push a Snapshot every time they
+// poll, instead of waiting for PollTime calls. The channel is closed when ctx
+// is done or the underlying connection fails.
+type Subscriber interface {
+	Subscribe(ctx context.Context, interval time.Duration) (<-chan *Snapshot, error)
+}
+
+// ErrSubscribeUnsupported is returned by GRPCClient.Subscribe when the bridge
+// on the other end predates the Subscribe RPC.
+var ErrSubscribeUnsupported = errors.New("bridge does not support Subscribe")
+
+// Broadcaster fans snapshots out to subscriber channels. A subscriber that
+// falls behind only ever sees the newest snapshot.
+type Broadcaster struct {
+	mu   sync.Mutex
+	subs map[chan *Snapshot]struct{}
+}
+
+// Add registers a new subscriber channel.
+func (b *Broadcaster) Add() chan *Snapshot {
+	ch := make(chan *Snapshot, 1)
+	b.mu.Lock()
+	if b.subs == nil {
+		b.subs = make(map[chan *Snapshot]struct{})
+	}
+	b.subs[ch] = struct{}{}
+	b.mu.Unlock()
+	return ch
+}
+
+// Remove unregisters and closes a channel returned by Add.
+func (b *Broadcaster) Remove(ch chan *Snapshot) {
+	b.mu.Lock()
+	if _, ok := b.subs[ch]; ok {
+		delete(b.subs, ch)
+		close(ch)
+	}
+	b.mu.Unlock()
+}
+
+// Publish hands snap to every subscriber without blocking, replacing any
+// snapshot a subscriber has not picked up yet.
+func (b *Broadcaster) Publish(snap *Snapshot) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subs {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- snap
+	}
+}
+
+// Len reports the number of registered subscribers.
+func (b *Broadcaster) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs)
+}
@@ -0,0 +1,34 @@
+package hwsensorsservice
+
+import "time"
+
+// WindowStats summarizes a reading over a trailing window of polls.
+type WindowStats struct {
+	Window time.Duration
+	Min    float64
+	Max    float64
+	Mean   float64
+	Count  int // samples in the window
+}
+
+// StatsReading is implemented by readings that carry rolling statistics, one
+// entry per window the service tracks, shortest first. ValueMin and ValueMax
+// stay whatever the source reports (LHM: since it started).
+type StatsReading interface {
+	Stats() []WindowStats
+}
+
+// ReadingStats returns the statistics of r over window, if its service
+// tracks that window.
+func ReadingStats(r Reading, window time.Duration) (WindowStats, bool) {
+	sr, ok := r.(StatsReading)
+	if !ok {
+		return WindowStats{}, false
+	}
+	for _, st := range sr.Stats() {
+		if st.Window == window {
+			return st, true
+		}
+	}
+	return WindowStats{}, false
+}
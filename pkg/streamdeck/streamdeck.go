@@ -40,6 +40,7 @@ type EventDelegate interface {
 	OnApplicationDidLaunch(*EvApplication)
 	OnApplicationDidTerminate(*EvApplication)
 	OnDidReceiveGlobalSettings(*EvDidReceiveGlobalSettings)
+	OnDeviceDidConnect(*EvDeviceDidConnect)
 }
 
 // StreamDeck SDK APIs
@@ -305,7 +306,14 @@ func (sd *StreamDeck) spawnMessageReader() {
 				sd.delegate.OnApplicationDidTerminate(&ev)
 			}
 		case "deviceDidConnect":
-			// No-op: Stream Deck device connect event (not needed by this plugin).
+			var ev EvDeviceDidConnect
+			if err := json.Unmarshal(message, &ev); err != nil {
+				log.Printf("deviceDidConnect unmarshal: %v", err)
+				continue
+			}
+			if sd.delegate != nil {
+				sd.delegate.OnDeviceDidConnect(&ev)
+			}
 		case "deviceDidDisconnect":
 			// No-op: Stream Deck device disconnect event (not needed by this plugin).
 		default:
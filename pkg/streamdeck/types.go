@@ -132,6 +132,28 @@ type EvApplication struct {
 	Payload EvApplicationPayload `json:"payload"`
 }
 
+// EvDeviceSize is the number of key columns and rows of a device
+type EvDeviceSize struct {
+	Columns int `json:"columns"`
+	Rows    int `json:"rows"`
+}
+
+// EvDeviceInfo describes a device in the deviceDidConnect event and the
+// registration info
+type EvDeviceInfo struct {
+	ID   string       `json:"id,omitempty"`
+	Name string       `json:"name"`
+	Type int          `json:"type"`
+	Size EvDeviceSize `json:"size"`
+}
+
+// EvDeviceDidConnect is the payload from the deviceDidConnect event
+type EvDeviceDidConnect struct {
+	Event      string       `json:"event"`
+	Device     string       `json:"device"`
+	DeviceInfo EvDeviceInfo `json:"deviceInfo"`
+}
+
 // EvTitleParameters is sub-structure from EvTitleParametersDidChangePayload
 type EvTitleParameters struct {
 	FontFamily     string `json:"fontFamily"`
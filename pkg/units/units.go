@@ -0,0 +1,204 @@
+// Package units parses the unit strings sensors report and converts values
+// between compatible units: temperatures, power, frequency, voltage, current,
+// energy, data sizes and data rates.
+package units
+
+import "strings"
+
+// Dimension groups units that convert into each other.
+type Dimension int
+
+const (
+	// None is a unit this package does not know; it only converts to itself.
+	None Dimension = iota
+	// Temperature in °C, °F or K
+	Temperature
+	// Power in mW, W or kW
+	Power
+	// Frequency in Hz, kHz, MHz or GHz
+	Frequency
+	// Voltage in mV or V
+	Voltage
+	// Current in mA or A
+	Current
+	// Energy in mWh, Wh or kWh
+	Energy
+	// DataSize in bytes or bits
+	DataSize
+	// DataRate in bytes or bits per second
+	DataRate
+)
+
+// Unit is a unit a value can be shown in. ID identifies it in settings;
+// Symbol is what a tile prints. They only differ where a symbol is ambiguous:
+// "MB" is 1024² bytes, the way LHM and Windows report it, while the SI
+// megabyte has the ID "MB_SI" and prints as "MB".
+type Unit struct {
+	ID     string
+	Symbol string
+	Dim    Dimension
+	// A value v in this unit is v*scale + offset in the dimension's base unit
+	// (°C, W, Hz, V, A, Wh, bytes, bytes/s).
+	scale  float64
+	offset float64
+}
+
+// Known reports whether u belongs to a dimension.
+func (u Unit) Known() bool { return u.Dim != None }
+
+const (
+	kib = 1024.0
+	mib = kib * 1024
+	gib = mib * 1024
+	tib = gib * 1024
+)
+
+var table = []Unit{
+	{ID: "°C", Symbol: "°C", Dim: Temperature, scale: 1},
+	{ID: "°F", Symbol: "°F", Dim: Temperature, scale: 5.0 / 9, offset: -32 * 5.0 / 9},
+	{ID: "K", Symbol: "K", Dim: Temperature, scale: 1, offset: -273.15},
+
+	{ID: "mW", Symbol: "mW", Dim: Power, scale: 1e-3},
+	{ID: "W", Symbol: "W", Dim: Power, scale: 1},
+	{ID: "kW", Symbol: "kW", Dim: Power, scale: 1e3},
+
+	{ID: "Hz", Symbol: "Hz", Dim: Frequency, scale: 1},
+	{ID: "kHz", Symbol: "kHz", Dim: Frequency, scale: 1e3},
+	{ID: "MHz", Symbol: "MHz", Dim: Frequency, scale: 1e6},
+	{ID: "GHz", Symbol: "GHz", Dim: Frequency, scale: 1e9},
+
+	{ID: "mV", Symbol: "mV", Dim: Voltage, scale: 1e-3},
+	{ID: "V", Symbol: "V", Dim: Voltage, scale: 1},
+
+	{ID: "mA", Symbol: "mA", Dim: Current, scale: 1e-3},
+	{ID: "A", Symbol: "A", Dim: Current, scale: 1},
+
+	{ID: "mWh", Symbol: "mWh", Dim: Energy, scale: 1e-3},
+	{ID: "Wh", Symbol: "Wh", Dim: Energy, scale: 1},
+	{ID: "kWh", Symbol: "kWh", Dim: Energy, scale: 1e3},
+}
+
+// Byte prefixes in both flavours, plus bits, which are always decimal.
+var dataUnits = []struct {
+	id, symbol string
+	bytes      float64
+}{
+	{"B", "B", 1},
+	{"KB", "KB", kib}, {"MB", "MB", mib}, {"GB", "GB", gib}, {"TB", "TB", tib},
+	{"KiB", "KiB", kib}, {"MiB", "MiB", mib}, {"GiB", "GiB", gib}, {"TiB", "TiB", tib},
+	{"kB_SI", "kB", 1e3}, {"MB_SI", "MB", 1e6}, {"GB_SI", "GB", 1e9}, {"TB_SI", "TB", 1e12},
+	{"bit", "bit", 1.0 / 8},
+	{"kbit", "kbit", 1e3 / 8}, {"Mbit", "Mbit", 1e6 / 8}, {"Gbit", "Gbit", 1e9 / 8},
+}
+
+var byID = func() map[string]Unit {
+	m := make(map[string]Unit, len(table)+2*len(dataUnits))
+	for _, u := range table {
+		m[u.ID] = u
+	}
+	for _, d := range dataUnits {
+		m[d.id] = Unit{ID: d.id, Symbol: d.symbol, Dim: DataSize, scale: d.bytes}
+		m[d.id+"/s"] = Unit{ID: d.id + "/s", Symbol: d.symbol + "/s", Dim: DataRate, scale: d.bytes}
+	}
+	return m
+}()
+
+// Spellings sensors use that are not an ID. Byte units are matched without
+// regard to case, so "kb/s" and "KB/s" are both the 1024-byte kilobyte.
+var aliases = map[string]string{
+	"C": "°C", "℃": "°C", "F": "°F", "℉": "°F",
+	"bps": "bit/s", "kbps": "kbit/s", "Kbps": "kbit/s", "Mbps": "Mbit/s", "Gbps": "Gbit/s",
+	"b/s": "B/s",
+}
+
+// Lookup returns the unit with the given ID.
+func Lookup(id string) (Unit, bool) {
+	u, ok := byID[id]
+	return u, ok
+}
+
+// Parse maps a reported unit string such as "°C", "MB/s" or "GHz" to a
+// Unit. Strings it does not know become a unit of their own with Dim None,
+// so callers can always keep the symbol.
+func Parse(s string) Unit {
+	s = strings.TrimSpace(s)
+	if u, ok := byID[s]; ok && !strings.HasSuffix(u.ID, "_SI") && !strings.HasSuffix(u.ID, "_SI/s") {
+		return u
+	}
+	if id, ok := aliases[s]; ok {
+		return byID[id]
+	}
+	if id, ok := byteUnitID(s); ok {
+		return byID[id]
+	}
+	return Unit{ID: s, Symbol: s}
+}
+
+// byteUnitID matches byte units with the prefix in any case ("kB", "MiB/s",
+// "GIB"). The B itself must be uppercase: a lowercase b is a bit, and bit
+// units are left to the exact spellings above, since "Mb" would be ambiguous.
+func byteUnitID(s string) (string, bool) {
+	base, rate := strings.CutSuffix(s, "/s")
+	if !strings.HasSuffix(base, "B") {
+		return "", false
+	}
+	var id string
+	switch base = strings.ToLower(base); base {
+	case "b":
+		id = "B"
+	case "kb", "mb", "gb", "tb":
+		id = strings.ToUpper(base)
+	case "kib", "mib", "gib", "tib":
+		id = strings.ToUpper(base[:1]) + "iB"
+	default:
+		return "", false
+	}
+	if rate {
+		id += "/s"
+	}
+	return id, true
+}
+
+// Convertible reports whether values in from can be shown in to.
+func Convertible(from, to Unit) bool {
+	if from.Dim == None || to.Dim == None {
+		return from.ID == to.ID
+	}
+	return from.Dim == to.Dim
+}
+
+// Convert converts v from one unit to another. It reports false, and returns
+// v unchanged, when the units are not convertible.
+func Convert(v float64, from, to Unit) (float64, bool) {
+	if !Convertible(from, to) {
+		return v, false
+	}
+	if from.ID == to.ID || from.Dim == None {
+		return v, true
+	}
+	return (v*from.scale + from.offset - to.offset) / to.scale, true
+}
+
+// Choices returns the units a value in u can be shown in, u's own included,
+// in table order.
+func Choices(u Unit) []Unit {
+	if u.Dim == None {
+		return []Unit{u}
+	}
+	var out []Unit
+	for _, c := range table {
+		if c.Dim == u.Dim {
+			out = append(out, c)
+		}
+	}
+	for _, d := range dataUnits {
+		id := d.id
+		if u.Dim == DataRate {
+			id += "/s"
+		}
+		if c := byID[id]; c.Dim == u.Dim {
+			out = append(out, c)
+		}
+	}
+	return out
+}
@@ -0,0 +1,101 @@
+package units
+
+import (
+	"math"
+	"testing"
+)
+
+func mustLookup(t *testing.T, id string) Unit {
+	t.Helper()
+	u, ok := Lookup(id)
+	if !ok {
+		t.Fatalf("Lookup(%q) failed", id)
+	}
+	return u
+}
+
+func TestConvert(t *testing.T) {
+	for _, tc := range []struct {
+		v        float64
+		from, to string
+		want     float64
+	}{
+		{100, "°C", "°F", 212},
+		{-40, "°F", "°C", -40},
+		{0, "°C", "K", 273.15},
+		{1500, "W", "kW", 1.5},
+		{4200, "MHz", "GHz", 4.2},
+		{1, "MB/s", "KB/s", 1024},
+		{1, "MiB/s", "MB/s", 1},
+		{1, "MB_SI/s", "KB/s", 1e6 / 1024},
+		{100, "Mbit/s", "MB_SI/s", 12.5},
+		{1, "GB", "MB", 1024},
+		{1250, "mV", "V", 1.25},
+	} {
+		got, ok := Convert(tc.v, mustLookup(t, tc.from), mustLookup(t, tc.to))
+		if !ok || math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("Convert(%v %s → %s) = %v, %v, want %v", tc.v, tc.from, tc.to, got, ok, tc.want)
+		}
+	}
+}
+
+func TestConvertRejectsIncompatibleUnits(t *testing.T) {
+	for _, pair := range [][2]Unit{
+		{mustLookup(t, "MB/s"), mustLookup(t, "MB")}, // rate vs size
+		{mustLookup(t, "°C"), mustLookup(t, "W")},
+		{Parse("RPM"), mustLookup(t, "W")},
+		{Parse("RPM"), Parse("%")},
+	} {
+		if v, ok := Convert(7, pair[0], pair[1]); ok || v != 7 {
+			t.Errorf("Convert(7, %s → %s) = %v, %v; want 7 unchanged", pair[0].ID, pair[1].ID, v, ok)
+		}
+	}
+	if v, ok := Convert(7, Parse("RPM"), Parse("RPM")); !ok || v != 7 {
+		t.Errorf("unknown unit does not convert to itself: %v, %v", v, ok)
+	}
+}
+
+func TestParse(t *testing.T) {
+	for in, want := range map[string]string{
+		"°C":    "°C",
+		" C ":   "°C",
+		"KB/s":  "KB/s",
+		"kB/s":  "KB/s",
+		"MiB":   "MiB",
+		"giB/s": "GiB/s",
+		"GIB":   "GiB",
+		"Mbps":  "Mbit/s",
+		"GHz":   "GHz",
+		"RPM":   "RPM",
+		"":      "",
+		"MB_SI": "MB_SI", // IDs only exist in settings; never reported
+	} {
+		if got := Parse(in).ID; got != want {
+			t.Errorf("Parse(%q).ID = %q, want %q", in, got, want)
+		}
+	}
+	// A lowercase b is a bit: megabits must not be scaled as megabytes.
+	for _, in := range []string{"Mb/s", "mb/s", "Mb", "kb", "gib/s"} {
+		if u := Parse(in); u.Known() {
+			t.Errorf("Parse(%q) = %s, want an unknown unit", in, u.ID)
+		}
+	}
+	if u := Parse("RPM"); u.Known() || u.Symbol != "RPM" {
+		t.Errorf("Parse(RPM) = %+v, want an unknown unit keeping its symbol", u)
+	}
+}
+
+func TestChoicesStayInDimension(t *testing.T) {
+	choices := Choices(Parse("KB/s"))
+	if len(choices) == 0 {
+		t.Fatal("no choices for KB/s")
+	}
+	for _, c := range choices {
+		if c.Dim != DataRate {
+			t.Fatalf("KB/s offers %s", c.ID)
+		}
+	}
+	if got := Choices(Parse("RPM")); len(got) != 1 || got[0].ID != "RPM" {
+		t.Fatalf("Choices(RPM) = %+v", got)
+	}
+}
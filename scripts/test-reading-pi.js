@@ -393,6 +393,31 @@ function testDerivedApplySettingsClearsStaleBoundsAndKeepsZero() {
   assert(String(derivedMax.value) === "0", `expected derived max to keep zero, got ${derivedMax.value}`);
 }
 
+function testValueSourceOptionsFollowProfileWindows() {
+  const select = new FakeSelect();
+  const item = new FakeElement({ style: {} });
+  const sandbox = loadScriptSandbox("com.moeilijk.lhm.sdPlugin/index_pi.js", {
+    elementsById: {
+      valueSource: select,
+      valueSourceItem: item,
+    },
+  });
+
+  sandbox.renderValueSourceOptions(["30s", "2m"], "max2m");
+  const values = select.options.map((option) => option.value);
+  assert(values.join(",") === ",avg30s,min30s,max30s,avg2m,min2m,max2m", `unexpected value options: ${values.join(",")}`);
+  assert(select.options[select.selectedIndex].value === "max2m", "expected the saved window to stay selected");
+  assert(item.style.display === "", "expected the selector to show for a source with windows");
+
+  sandbox.renderValueSourceOptions(["30s", "2m"], "avg5m");
+  const last = select.options[select.options.length - 1];
+  assert(last.value === "avg5m" && last.textContent === "5m average (not tracked)", `unexpected untracked option: ${last.textContent}`);
+
+  sandbox.renderValueSourceOptions(undefined, "");
+  assert(select.options.length === 1, "expected only Current for a source without windows");
+  assert(item.style.display === "none", "expected the selector to hide for a source without windows");
+}
+
 function main() {
   testNormalizeSnoozeDurations();
   testApplySnoozeDurationsToUI();
@@ -403,7 +428,8 @@ function main() {
   testDerivedReadingSortUsesNaturalLabelOrder();
   testCompositeApplySettingsClearsStaleBoundsAndKeepsZero();
   testDerivedApplySettingsClearsStaleBoundsAndKeepsZero();
-  process.stdout.write("reading-pi tests ok (9 cases)\n");
+  testValueSourceOptionsFollowProfileWindows();
+  process.stdout.write("reading-pi tests ok (10 cases)\n");
 }
 
 main();
@@ -59,10 +59,17 @@ function loadSandbox(opts = {}) {
   const elements = {
     pollInterval: new FakeElement({ value: "1000" }),
     currentRate: new FakeElement({ textContent: "" }),
+    renderTiming: new FakeElement({ textContent: "", style: {} }),
+    frameStats: new FakeElement({ textContent: "" }),
+    pngCompression: new FakeElement({ value: "none" }),
+    historyMaxAge: new FakeElement({ value: "15" }),
+    rendering: new FakeElement({ value: "" }),
     tileBackground: new FakeElement({ value: "#112233" }),
     tileTextColor: new FakeElement({ value: "#aabbcc" }),
     showLabel: new FakeElement({ checked: true }),
     connectionStatus: new FakeElement({ textContent: "", style: {} }),
+    sourceType: new FakeElement(),
+    groupMembers: new FakeElement(),
     sourceProfileSelect: new FakeElement(),
     defaultProfileSelect: new FakeElement(),
     deleteProfileBtn: new FakeElement(),
@@ -198,6 +205,134 @@ function testDidReceiveSettingsAppliesUi() {
   assert(elements.showLabel.checked === false, "showLabel not applied");
 }
 
+function testRenderTimingStatus() {
+  const ws = {
+    readyState: 1,
+    send() {},
+    onopen: null,
+    onmessage: null,
+  };
+  const { sandbox, elements } = loadSandbox({ mockSocket: ws });
+  sandbox.connectElgatoStreamDeckSocket("12345", "uuid-x", "registerPropertyInspector", "{}", JSON.stringify({
+    action: "com.moeilijk.lhm.settings",
+    context: "ctx-x",
+  }));
+  const status = (renderTiming) => ws.onmessage({
+    data: JSON.stringify({
+      event: "sendToPropertyInspector",
+      payload: { currentRate: 1000, renderTiming },
+    }),
+  });
+
+  status({ avgMs: 12, peakMs: 40, lateTicks: 0, skippedRenders: 0 });
+  assert(elements.renderTiming.textContent === "12 ms avg · peak 40 ms", "unexpected render timing: " + elements.renderTiming.textContent);
+  assert(elements.renderTiming.style.color === "#999", "on-time render timing should be grey");
+
+  status({ avgMs: 900, peakMs: 1400, lateTicks: 3, skippedRenders: 5 });
+  assert(elements.renderTiming.textContent === "900 ms avg · peak 1400 ms (3 late, 5 skipped)", "unexpected late render timing: " + elements.renderTiming.textContent);
+  assert(elements.renderTiming.style.color === "#ca4", "late render timing should be amber");
+}
+
+function testFrameStatsAndCompression() {
+  const { sandbox, elements, sent } = loadSandbox();
+  const ws = {
+    readyState: 1,
+    send(msg) {
+      sent.push(JSON.parse(msg));
+    },
+    onopen: null,
+    onmessage: null,
+  };
+  sandbox.WebSocket = function () {
+    return ws;
+  };
+  sandbox.connectElgatoStreamDeckSocket("12345", "uuid-x", "registerPropertyInspector", "{}", JSON.stringify({
+    action: "com.moeilijk.lhm.settings",
+    context: "ctx-x",
+  }));
+  ws.onmessage({
+    data: JSON.stringify({
+      event: "sendToPropertyInspector",
+      payload: {
+        frames: { rendered: 120, suppressed: 20, bytesSent: 2097152, transport: "opendeck", compression: "best" },
+      },
+    }),
+  });
+  assert(elements.frameStats.textContent === "100 sent · 20 unchanged · 2.0 MB", "unexpected frame stats: " + elements.frameStats.textContent);
+  assert(elements.pngCompression.value === "best", "compression not applied from status");
+
+  elements.pngCompression.value = "fast";
+  elements.pngCompression.trigger("change");
+  const updates = sent.filter((m) => m.event === "sendToPlugin" && m.payload && m.payload.setPngCompression);
+  assert(updates.length === 1 && updates[0].payload.setPngCompression === "fast", "compression change not sent");
+}
+
+function testHistoryMaxAge() {
+  const { sandbox, elements, sent } = loadSandbox();
+  const ws = {
+    readyState: 1,
+    send(msg) {
+      sent.push(JSON.parse(msg));
+    },
+    onopen: null,
+    onmessage: null,
+  };
+  sandbox.WebSocket = function () {
+    return ws;
+  };
+  sandbox.connectElgatoStreamDeckSocket("12345", "uuid-x", "registerPropertyInspector", "{}", JSON.stringify({
+    action: "com.moeilijk.lhm.settings",
+    context: "ctx-x",
+  }));
+  const globals = (settings) => ws.onmessage({
+    data: JSON.stringify({ event: "didReceiveGlobalSettings", payload: { settings } }),
+  });
+
+  globals({ pollInterval: 1000 });
+  assert(elements.historyMaxAge.value === "15", "unset history age should show the default");
+  globals({ pollInterval: 1000, historyMaxAgeMin: -1 });
+  assert(elements.historyMaxAge.value === "-1", "history off not applied");
+
+  elements.historyMaxAge.value = "60";
+  elements.historyMaxAge.trigger("change");
+  const updates = sent.filter((m) => m.event === "sendToPlugin" && m.payload && m.payload.setHistoryMaxAge !== undefined);
+  assert(updates.length === 1 && updates[0].payload.setHistoryMaxAge === 60, "history age change not sent as a number");
+}
+
+function testRendering() {
+  const { sandbox, elements, sent } = loadSandbox();
+  const ws = {
+    readyState: 1,
+    send(msg) {
+      sent.push(JSON.parse(msg));
+    },
+    onopen: null,
+    onmessage: null,
+  };
+  sandbox.WebSocket = function () {
+    return ws;
+  };
+  sandbox.connectElgatoStreamDeckSocket("12345", "uuid-x", "registerPropertyInspector", "{}", JSON.stringify({
+    action: "com.moeilijk.lhm.settings",
+    context: "ctx-x",
+  }));
+  const globals = (settings) => ws.onmessage({
+    data: JSON.stringify({ event: "didReceiveGlobalSettings", payload: { settings } }),
+  });
+
+  globals({ pollInterval: 1000, rendering: "smooth" });
+  assert(elements.rendering.value === "smooth", "smooth rendering not applied");
+  globals({ pollInterval: 1000, rendering: "pixel" });
+  assert(elements.rendering.value === "", "stored pixel rendering should show the pixel default");
+  globals({ pollInterval: 1000 });
+  assert(elements.rendering.value === "", "unset rendering should show pixel");
+
+  elements.rendering.value = "smooth";
+  elements.rendering.trigger("change");
+  const updates = sent.filter((m) => m.event === "sendToPlugin" && m.payload && m.payload.setRendering !== undefined);
+  assert(updates.length === 1 && updates[0].payload.setRendering === "smooth", "rendering change not sent");
+}
+
 function testMalformedInputsDoNotCrash() {
   const ws = {
     readyState: 1,
@@ -307,6 +442,32 @@ function testAddGlobalThresholdButtonSendsCommand() {
   assert(elements.newGlobalThresholdName.value === "", "name input should clear after add");
 }
 
+function testGroupMembersSavedInOrder() {
+  const { sandbox, elements, sent } = loadSandbox();
+  sandbox.context = "ctx-settings";
+  sandbox.uuid = "ctx-pi";
+  sandbox.sourceProfiles = [
+    { id: "lhm", name: "Workstation LHM" },
+    { id: "companion", name: "Workstation companion" },
+    { id: "box", name: "Workstation", type: "group", members: ["lhm"] },
+  ];
+  sandbox.selectedProfileId = "box";
+
+  sandbox.applySelectedProfileToUI();
+  const selects = elements.groupMembers.children;
+  assert(selects.length === 2, "expected the member plus one empty select");
+  assert(selects[0].value === "lhm", "primary member not selected");
+  const offered = selects[1].children.map((o) => o.value);
+  assert(offered.indexOf("box") < 0, "group offered as its own member");
+
+  selects[1].value = "companion";
+  sandbox.saveSourceProfile();
+  const saves = sent.filter((m) => m.payload && m.payload.setSourceProfile);
+  const saved = saves[saves.length - 1].payload.setSourceProfile;
+  assert(saved.type === "group", "group type not saved");
+  assert(JSON.stringify(saved.members) === JSON.stringify(["lhm", "companion"]), "members not saved in order: " + JSON.stringify(saved.members));
+}
+
 function testGlobalThresholdWithoutEnabledRendersOpen() {
   const { sandbox } = loadSandbox();
   const elements = {};
@@ -357,13 +518,18 @@ function main() {
   testContextFanout();
   testPollIntervalEvents();
   testDidReceiveSettingsAppliesUi();
+  testRenderTimingStatus();
+  testFrameStatsAndCompression();
+  testHistoryMaxAge();
+  testRendering();
   testMalformedInputsDoNotCrash();
   testPollingFallbackSave();
   testStatusHeartbeatIsLightweight();
   testFocusedProfileInputIsNotOverwritten();
   testAddGlobalThresholdButtonSendsCommand();
   testGlobalThresholdWithoutEnabledRendersOpen();
-  process.stdout.write("settings-pi tests ok (10 cases)\n");
+  testGroupMembersSavedInOrder();
+  process.stdout.write("settings-pi tests ok (15 cases)\n");
 }
 
 main();
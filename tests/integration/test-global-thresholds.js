@@ -7,6 +7,8 @@
 //   - DeckBridge running (ports from /tmp/deckbridge.log)
 //   - lhm plugin deployed and running under DeckBridge
 
+const fs = require('fs');
+const path = require('path');
 const {
   pass, fail, summary, sleep,
   waitForDeckBridge, connectPI, waitForMessage, sendToPlugin, sdpi,
@@ -323,7 +325,10 @@ async function run() {
           case 'current':                  return 'Current';
           case 'power':                    return 'Power';
           case 'clock':                    return 'Clock';
-          case 'usage': case 'load': case 'control': case 'level': return 'Usage';
+          case 'usage': case 'load':       return 'Usage';
+          case 'control':                  return 'Control';
+          case 'level':                    return 'Level';
+          case 'throughput':               return 'Throughput';
           case 'none':                     return 'None';
           default:                         return t ? 'Other' : '';
         }
@@ -358,8 +363,9 @@ async function run() {
         { raw: 'Voltage', expected: 'Volt' },
         { raw: 'Fan',     expected: 'Fan' },
         { raw: 'Load',    expected: 'Usage' },
-        { raw: 'Control', expected: 'Usage' },
+        { raw: 'Control', expected: 'Control' },
         { raw: 'Clock',   expected: 'Clock' },
+        { raw: 'Throughput', expected: 'Throughput' },
       ];
       let type5dOk = true;
       for (const { raw, expected } of voltageTypes) {
@@ -372,6 +378,17 @@ async function run() {
       if (type5dOk) {
         pass('test 5d — all LHM type strings map correctly (Voltage→Volt, Load→Usage, etc.)');
       }
+
+      // 5e — globals saved as "Usage" before Control was split out still apply
+      // (same rule as readingTypeMatches in pi_utils.js)
+      const piUtils = fs.readFileSync(path.join(__dirname, '../../com.moeilijk.lhm.sdPlugin/pi_utils.js'), 'utf8');
+      const readingTypeMatches = new Function(piUtils + '\nreturn readingTypeMatches;')();
+      if (readingTypeMatches('Usage', 'Control') && readingTypeMatches('Other', 'Throughput') &&
+          !readingTypeMatches('Usage', 'Throughput')) {
+        pass('test 5e — legacy Usage/Other globals still match split-out types');
+      } else {
+        fail('test 5e — legacy type filters no longer match');
+      }
     }
     piWs.close();
   }